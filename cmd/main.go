@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"time"
+	_ "time/tzdata" // The runtime image has no timezone database, which punch cards need
 
 	"github-service/config"
 	"github-service/internal/adapters"
@@ -35,7 +36,7 @@ func main() {
 	logger.InitLogger()
 
 	// Set up storage components (Postgres and BadgerDB)
-	commitRepo, repositoryRepo, badgerService, err := adapters.SetupStorage(cfg)
+	storage, err := adapters.SetupStorage(cfg)
 	if err != nil {
 		log.Fatalf("Failed to setup storage: %v", err)
 	}
@@ -47,15 +48,21 @@ func main() {
 	}
 
 	// Set up core services with the initialized repositories
-	commitService, repositoryService, monitorService := service.SetupService(ctx, cfg, defaultRepoData, commitRepo, repositoryRepo, badgerService)
-
-	// Create handlers for commit and repository operations
-	commitHandler := handlers.NewCommitHandler(commitService, repositoryService)
-	repositoryHandler := handlers.NewRepositoryHandler(repositoryService, monitorService)
+	services := service.SetupService(ctx, cfg, defaultRepoData, storage)
+
+	// Create handlers for commit, repository, job and identity operations
+	commitHandler := handlers.NewCommitHandler(services.Commits, services.Repositories)
+	repositoryHandler := handlers.NewRepositoryHandler(services.Repositories, services.Monitor)
+	jobHandler := handlers.NewJobHandler(services.Jobs)
+	identityHandler := handlers.NewIdentityHandler(services.Identities)
+	pullRequestHandler := handlers.NewPullRequestHandler(services.PullRequests)
+	issueHandler := handlers.NewIssueHandler(services.Issues)
+	releaseHandler := handlers.NewReleaseHandler(services.Releases)
+	changelogHandler := handlers.NewChangelogHandler(services.Changelogs)
 
 	// Initialize Gin router and configure API routes
 	router := gin.Default()
-	routes.SetupAPIRoutes(router, commitHandler, repositoryHandler)
+	routes.SetupAPIRoutes(router, commitHandler, repositoryHandler, jobHandler, identityHandler, pullRequestHandler, issueHandler, releaseHandler, changelogHandler)
 
 	// Define the server port
 	PORT := fmt.Sprintf(":%s", cfg.PORT)
@@ -66,7 +73,7 @@ func main() {
 
 func gracefulShutdown(router *gin.Engine, port string) {
 	// Create a channel to listen for OS signals
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 
 	// Create a server instance with a timeout
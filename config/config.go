@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"log"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -14,18 +15,27 @@ type DatabaseConfig struct {
 
 // Config holds the application configuration.
 type Config struct {
-	PER_PAGE          string `json:"PER_PAGE"`
-	BASE_URL          string `json:"BASE_URL"`
-	GITHUB_TOKEN      string `json:"GITHUB_TOKEN"`
-	DEFAULT_OWNER     string `json:"DEFAULT_OWNER"`
-	DEFAULT_REPO      string `json:"DEFAULT_REPO"`
-	BEGIN_FETCH_DATE  string `json:"BEGIN_FETCH_DATE"`
-	PORT              string `json:"PORT"`
-	POSTGRES_USER     string `json:"POSTGRES_USER"`
-	POSTGRES_PASSWORD string `json:"POSTGRES_PASSWORD"`
-	POSTGRES_HOST     string `json:"POSTGRES_HOST"`
-	POSTGRES_DB       string `json:"POSTGRES_DB"`
-	POLL_INTERVAL     int64  `json:"POLL_INTERVAL"`
+	PER_PAGE            string `json:"PER_PAGE"`
+	BASE_URL            string `json:"BASE_URL"`
+	GITHUB_TOKEN        string `json:"GITHUB_TOKEN"`
+	GITHUB_TOKENS       string `json:"GITHUB_TOKENS"`
+	DEFAULT_OWNER       string `json:"DEFAULT_OWNER"`
+	DEFAULT_REPO        string `json:"DEFAULT_REPO"`
+	BEGIN_FETCH_DATE    string `json:"BEGIN_FETCH_DATE"`
+	PORT                string `json:"PORT"`
+	POSTGRES_USER       string `json:"POSTGRES_USER"`
+	POSTGRES_PASSWORD   string `json:"POSTGRES_PASSWORD"`
+	POSTGRES_HOST       string `json:"POSTGRES_HOST"`
+	POSTGRES_DB         string `json:"POSTGRES_DB"`
+	POLL_INTERVAL       int64  `json:"POLL_INTERVAL"`
+	MAX_PAGES           int    `json:"MAX_PAGES"`
+	SYNC_WORKERS        int    `json:"SYNC_WORKERS"`
+	JOB_MAX_ATTEMPTS    int    `json:"JOB_MAX_ATTEMPTS"`
+	BACKFILL_SLICE_DAYS int    `json:"BACKFILL_SLICE_DAYS"`
+	ENRICH_COMMITS      bool   `json:"ENRICH_COMMITS"`
+	SYNC_PULL_REQUESTS  bool   `json:"SYNC_PULL_REQUESTS"`
+	SYNC_ISSUES         bool   `json:"SYNC_ISSUES"`
+	SYNC_RELEASES       bool   `json:"SYNC_RELEASES"`
 }
 
 // LoadConfig loads configuration from environment variables or a .env file.
@@ -58,3 +68,9 @@ func LoadConfig() (config Config, err error) {
 	log.Println("Configuration loaded successfully")
 	return
 }
+
+// GithubTokens returns every configured GitHub token: GITHUB_TOKEN followed by the comma-separated GITHUB_TOKENS pool.
+func (c Config) GithubTokens() []string {
+	tokens := []string{c.GITHUB_TOKEN}
+	return append(tokens, strings.Split(c.GITHUB_TOKENS, ",")...)
+}
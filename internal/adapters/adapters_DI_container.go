@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"errors"
 	"fmt"
 	"github-service/config"
 	badger "github-service/internal/adapters/badgerdb"
@@ -9,39 +10,89 @@ import (
 	"github-service/internal/ports"
 )
 
-func SetupStorage(cfg config.Config) (ports.PostgresCommit, ports.PostgresRepository, *badger.BadgerRepository, error) {
+func SetupStorage(cfg config.Config) (ports.Storage, error) {
 	// Initialize the Postgres database connection
 	db, err := postgresdb.Connect(cfg)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		return ports.Storage{}, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Create the Commit repository
 	commitRepo, err := postgresdb.NewCommitRepository(db)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create commit repository: %w", err)
+		return ports.Storage{}, fmt.Errorf("failed to create commit repository: %w", err)
 	}
 
 	// Create the Repository repository
 	repositoryRepo, err := postgresdb.NewRepository(db)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create repository repository: %w", err)
+		return ports.Storage{}, fmt.Errorf("failed to create repository repository: %w", err)
 	}
 
-	// Initialize Badger key-value store
-	badgerService, err := badger.NewBadgerRepository("./tmp")
+	// Create the Job repository
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	if err != nil {
+		return ports.Storage{}, fmt.Errorf("failed to create job repository: %w", err)
+	}
+
+	// Create the Backfill repository
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	if err != nil {
+		return ports.Storage{}, fmt.Errorf("failed to create backfill repository: %w", err)
+	}
+
+	// Create the Identity repository
+	identityRepo, err := postgresdb.NewIdentityRepository(db)
+	if err != nil {
+		return ports.Storage{}, fmt.Errorf("failed to create identity repository: %w", err)
+	}
+
+	// Create the PullRequest repository
+	pullRequestRepo, err := postgresdb.NewPullRequestRepository(db)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create badger repository: %w", err)
+		return ports.Storage{}, fmt.Errorf("failed to create pull request repository: %w", err)
 	}
 
-	// Example of seeding data into Badger (can be optional)
-	repoDataArray := []domain.RepoData{
-		{RepoName: "chromium", Owner: "chromium"},
+	// Create the Issue repository
+	issueRepo, err := postgresdb.NewIssueRepository(db)
+	if err != nil {
+		return ports.Storage{}, fmt.Errorf("failed to create issue repository: %w", err)
+	}
+
+	// Create the Release repository
+	releaseRepo, err := postgresdb.NewReleaseRepository(db)
+	if err != nil {
+		return ports.Storage{}, fmt.Errorf("failed to create release repository: %w", err)
 	}
-	err = badgerService.SaveRepoArray("repos", repoDataArray)
+
+	// Initialize Badger key-value store
+	badgerService, err := badger.NewBadgerRepository("./tmp")
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to save initial data to badger: %w", err)
+		return ports.Storage{}, fmt.Errorf("failed to create badger repository: %w", err)
+	}
+
+	// Seed the watchlist on first start only, so repositories added or removed at runtime survive a restart
+	if _, err := badgerService.GetRepoArray("repos"); errors.Is(err, badger.ErrRepoArrayNotFound) {
+		repoDataArray := []domain.RepoData{
+			{RepoName: "chromium", Owner: "chromium"},
+		}
+		err = badgerService.SaveRepoArray("repos", repoDataArray)
+		if err != nil {
+			return ports.Storage{}, fmt.Errorf("failed to save initial data to badger: %w", err)
+		}
+	} else if err != nil {
+		return ports.Storage{}, fmt.Errorf("failed to read watchlist from badger: %w", err)
 	}
 
-	return commitRepo, repositoryRepo, badgerService, nil
+	return ports.Storage{
+		Commits:      commitRepo,
+		Repositories: repositoryRepo,
+		Jobs:         jobRepo,
+		Backfills:    backfillRepo,
+		Identities:   identityRepo,
+		PullRequests: pullRequestRepo,
+		Issues:       issueRepo,
+		Releases:     releaseRepo,
+		Badger:       badgerService,
+	}, nil
 }
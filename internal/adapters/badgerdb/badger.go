@@ -1,14 +1,20 @@
 package badger
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"github-service/internal/core/domain"
 	"log"
 
 	"github.com/dgraph-io/badger/v3"
+	"github.com/dgraph-io/badger/v3/pb"
 )
 
+// ErrRepoArrayNotFound is returned when no RepoData array is stored under the requested key
+var ErrRepoArrayNotFound = errors.New("repository data not found")
+
 // BadgerRepository provides methods to interact with a Badger DB instance
 type BadgerRepository struct {
 	db *badger.DB
@@ -30,22 +36,7 @@ func NewBadgerRepository(dbPath string) (*BadgerRepository, error) {
 // SaveRepoArray saves an array of RepoData to Badger using the provided repoKey.
 func (b *BadgerRepository) SaveRepoArray(repoKey string, repoDataArray []domain.RepoData) error {
 	return b.db.Update(func(txn *badger.Txn) error {
-		// Marshal the array of RepoData into JSON
-		data, err := json.Marshal(repoDataArray)
-		if err != nil {
-			log.Printf("Error marshaling RepoDataArray: %v", err)
-			return err
-		}
-
-		// Save the array in Badger using the given key
-		err = txn.Set([]byte(repoKey), data)
-		if err != nil {
-			log.Printf("Error saving data to Badger for key %s: %v", repoKey, err)
-			return err
-		}
-
-		log.Printf("RepoDataArray saved to Badger with key %s", repoKey)
-		return nil
+		return writeRepoArray(txn, repoKey, repoDataArray)
 	})
 }
 
@@ -54,28 +45,12 @@ func (b *BadgerRepository) GetRepoArray(repoKey string) ([]domain.RepoData, erro
 	var repoDataArray []domain.RepoData
 
 	err := b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(repoKey))
-		if err != nil {
-			log.Printf("Error retrieving data from Badger for key %s: %v", repoKey, err)
-			return err
-		}
-
-		// Get the value and unmarshal it into the array of RepoData
-		err = item.Value(func(val []byte) error {
-			return json.Unmarshal(val, &repoDataArray)
-		})
-		if err != nil {
-			log.Printf("Error unmarshaling data for key %s: %v", repoKey, err)
-			return err
-		}
-		return nil
+		var err error
+		repoDataArray, err = readRepoArray(txn, repoKey)
+		return err
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			log.Printf("Repository data not found for key %s", repoKey)
-			return nil, fmt.Errorf("repository data not found")
-		}
 		log.Printf("Error getting repo array: %v", err)
 		return nil, err
 	}
@@ -85,41 +60,146 @@ func (b *BadgerRepository) GetRepoArray(repoKey string) ([]domain.RepoData, erro
 }
 
 // UpdateRepoArray updates or adds a RepoData entry in the array for the provided repoKey.
+// Entries are matched on both owner and repository name, so forks sharing a name are kept apart.
 func (b *BadgerRepository) UpdateRepoArray(repoKey string, updatedRepo domain.RepoData) error {
-	// Fetch the current array from Badger
-	repoDataArray, err := b.GetRepoArray(repoKey)
+	_, err := b.editRepoArray(repoKey, func(repoDataArray []domain.RepoData) ([]domain.RepoData, bool) {
+		// Add or update the RepoData in the array
+		for i, repo := range repoDataArray {
+			if repo.Owner == updatedRepo.Owner && repo.RepoName == updatedRepo.RepoName {
+				// Update the existing repo entry
+				repoDataArray[i] = updatedRepo
+				return repoDataArray, true
+			}
+		}
+		// If not found, append the new repo entry
+		return append(repoDataArray, updatedRepo), true
+	})
 	if err != nil {
-		log.Printf("Error fetching repo array for update: %v", err)
+		log.Printf("Error updating repo array: %v", err)
 		return err
 	}
 
-	// Add or update the RepoData in the array
-	updated := false
-	for i, repo := range repoDataArray {
-		if repo.RepoName == updatedRepo.RepoName {
-			// Update the existing repo entry
-			repoDataArray[i] = updatedRepo
-			updated = true
-			break
+	log.Printf("RepoDataArray updated successfully for key %s", repoKey)
+	return nil
+}
+
+// RemoveFromRepoArray removes the RepoData entry with the same owner and repository name from the array for the provided repoKey.
+// Removing an entry that is not in the array is not an error.
+func (b *BadgerRepository) RemoveFromRepoArray(repoKey string, removedRepo domain.RepoData) error {
+	removed, err := b.editRepoArray(repoKey, func(repoDataArray []domain.RepoData) ([]domain.RepoData, bool) {
+		// Keep every entry except the removed repository
+		remaining := make([]domain.RepoData, 0, len(repoDataArray))
+		for _, repo := range repoDataArray {
+			if repo.Owner == removedRepo.Owner && repo.RepoName == removedRepo.RepoName {
+				continue
+			}
+			remaining = append(remaining, repo)
 		}
+		return remaining, len(remaining) != len(repoDataArray)
+	})
+	if err != nil {
+		log.Printf("Error removing from repo array: %v", err)
+		return err
+	}
+	if !removed {
+		return nil
 	}
 
-	if !updated {
-		// If not found, append the new repo entry
-		repoDataArray = append(repoDataArray, updatedRepo)
+	log.Printf("Repository %s removed from key %s", removedRepo.FullName(), repoKey)
+	return nil
+}
+
+// editRepoArray reads the array for the provided repoKey, passes it to edit and saves what edit returns, unless edit
+// reports no change, all in one transaction, and returns whether it saved. A missing array is edited as an empty one.
+// When another edit commits first, the transaction conflicts and is retried on the new array, so concurrent edits
+// never overwrite each other.
+func (b *BadgerRepository) editRepoArray(repoKey string, edit func([]domain.RepoData) ([]domain.RepoData, bool)) (bool, error) {
+	for {
+		changed := false
+		err := b.db.Update(func(txn *badger.Txn) error {
+			repoDataArray, err := readRepoArray(txn, repoKey)
+			if err != nil && !errors.Is(err, ErrRepoArrayNotFound) {
+				return err
+			}
+
+			repoDataArray, changed = edit(repoDataArray)
+			if !changed {
+				return nil
+			}
+			return writeRepoArray(txn, repoKey, repoDataArray)
+		})
+		if !errors.Is(err, badger.ErrConflict) {
+			return changed && err == nil, err
+		}
+	}
+}
+
+// readRepoArray reads the array of RepoData stored under repoKey within txn.
+// It returns ErrRepoArrayNotFound if no array is stored.
+func readRepoArray(txn *badger.Txn, repoKey string) ([]domain.RepoData, error) {
+	item, err := txn.Get([]byte(repoKey))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		log.Printf("Repository data not found for key %s", repoKey)
+		return nil, ErrRepoArrayNotFound
+	}
+	if err != nil {
+		log.Printf("Error retrieving data from Badger for key %s: %v", repoKey, err)
+		return nil, err
+	}
+
+	// Get the value and unmarshal it into the array of RepoData
+	var repoDataArray []domain.RepoData
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &repoDataArray)
+	})
+	if err != nil {
+		log.Printf("Error unmarshaling data for key %s: %v", repoKey, err)
+		return nil, err
 	}
+	return repoDataArray, nil
+}
 
-	// Save the updated array back to Badger
-	err = b.SaveRepoArray(repoKey, repoDataArray)
+// writeRepoArray stores an array of RepoData under repoKey within txn
+func writeRepoArray(txn *badger.Txn, repoKey string, repoDataArray []domain.RepoData) error {
+	// Marshal the array of RepoData into JSON
+	data, err := json.Marshal(repoDataArray)
 	if err != nil {
-		log.Printf("Error saving updated repo array: %v", err)
+		log.Printf("Error marshaling RepoDataArray: %v", err)
 		return err
 	}
 
-	log.Printf("RepoDataArray updated successfully for key %s", repoKey)
+	// Save the array in Badger using the given key
+	if err := txn.Set([]byte(repoKey), data); err != nil {
+		log.Printf("Error saving data to Badger for key %s: %v", repoKey, err)
+		return err
+	}
+
+	log.Printf("RepoDataArray saved to Badger with key %s", repoKey)
 	return nil
 }
 
+// WatchRepoArray calls onChange with the new array every time the array for the provided repoKey is saved.
+// It blocks until ctx is cancelled or the subscription fails.
+func (b *BadgerRepository) WatchRepoArray(ctx context.Context, repoKey string, onChange func([]domain.RepoData)) error {
+	key := []byte(repoKey)
+	return b.db.Subscribe(ctx, func(kvs *badger.KVList) error {
+		for _, kv := range kvs.Kv {
+			// The subscription matches on prefix, so skip longer keys that share it
+			if !bytes.Equal(kv.Key, key) {
+				continue
+			}
+
+			var repoDataArray []domain.RepoData
+			if err := json.Unmarshal(kv.Value, &repoDataArray); err != nil {
+				log.Printf("Error unmarshaling watched data for key %s: %v", repoKey, err)
+				continue
+			}
+			onChange(repoDataArray)
+		}
+		return nil
+	}, []pb.Match{{Prefix: key}})
+}
+
 // Close closes the Badger database.
 func (b *BadgerRepository) Close() error {
 	err := b.db.Close()
@@ -3,10 +3,14 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	neturl "net/url"
+	"slices"
 	"time"
 
 	"github-service/config"
+	"github-service/internal/core/domain"
 	"github-service/pkg/httpClient"
 	"github-service/pkg/logger"
 )
@@ -18,41 +22,351 @@ type GithubClient struct {
 }
 
 // NewGithubClient creates a new instance of GithubClient with a custom HTTP client.
-// The client is rate-limited based on the configured PollInterval.
+// The client paces itself by the quota GitHub reports and authenticates with the
+// configured GitHub tokens, rotating between them when there is more than one.
 func NewGithubClient(cfg *config.Config, ctx context.Context) *GithubClient {
-	rateLimitInterval := time.Duration(cfg.POLL_INTERVAL) * time.Second
+	tokens := httpclient.NewTokenPool(cfg.GithubTokens())
+	if tokens.Size() == 0 {
+		logger.LogWarning("No GitHub token configured, requests are limited to the anonymous quota")
+	}
 	// Initialize the custom HTTP client
-	client := httpclient.NewClient(nil, rateLimitInterval) // Use default http.Client or pass a custom one
+	client := httpclient.NewClient(nil, tokens) // Use default http.Client or pass a custom one
 	return &GithubClient{
 		client: client,
 		cfg:    cfg,
 	}
 }
 
+// pageWalk is a paginated GitHub listing for walk to follow page by page along the Link rel="next" chain.
+// P is what a page decodes into.
+type pageWalk[P any] struct {
+	what  string // What the listing holds, for logs and errors, such as "pull requests"
+	owner string
+	repo  string
+	url   string // URL of the first page
+	page  int    // Number of the first page, 1 when unset
+
+	// revalidate keeps the validators of the pages walked, so walking an unchanged listing again is answered with 304:
+	// a 304 on the first page is returned as httpclient.ErrNotModified, and a 304 on a later one ends the walk as the
+	// rest was handled on an earlier walk. If the walk fails, the pages walked are forgotten too, so a retry fetches
+	// them in full rather than being answered with 304 before it reaches the failed page.
+	// Without it every page is fetched in full on every walk.
+	revalidate bool
+
+	onPage func(page int, current P) error // Called with every page as it arrives; an error ends the walk
+	stop   func(page int, current P) bool  // Reports whether to end the walk before the next page; nil walks every page
+	done   func() error                    // Called once the walk is over when set; an error fails the walk
+}
+
+// walk fetches the pages of the listing with client and hands them over
+func (w pageWalk[P]) walk(ctx context.Context, client *httpclient.Client) error {
+	first := w.page
+	if first < 1 {
+		first = 1
+	}
+
+	var walked []string
+	fail := func(err error) error {
+		for _, u := range walked {
+			client.Forget(u)
+		}
+		return err
+	}
+
+	url := w.url
+	for page := first; url != ""; page++ {
+		resp, err := client.ApiCallWithResponse(ctx, "GET", url, nil)
+		if errors.Is(err, httpclient.ErrNotModified) {
+			if page == first {
+				logger.LogInfo(fmt.Sprintf("Listing of %s for %s/%s not modified since last fetch", w.what, w.owner, w.repo))
+				return err
+			}
+			logger.LogInfo(fmt.Sprintf("Page %d of %s for %s/%s not modified, stopping", page, w.what, w.owner, w.repo))
+			break
+		}
+		if err != nil {
+			logger.LogWarning(fmt.Sprintf("Error fetching %s page %d for %s/%s: %v", w.what, page, w.owner, w.repo, err))
+			return fail(fmt.Errorf("failed to fetch %s page %d: %w", w.what, page, err))
+		}
+		if w.revalidate {
+			walked = append(walked, url)
+		} else {
+			client.Forget(url)
+		}
+
+		var current P
+		if err := json.Unmarshal(resp.Body, &current); err != nil {
+			logger.LogWarning(fmt.Sprintf("Error unmarshaling %s page %d for %s/%s: %v", w.what, page, w.owner, w.repo, err))
+			return fail(fmt.Errorf("failed to decode %s page %d: %w", w.what, page, err))
+		}
+		if err := w.onPage(page, current); err != nil {
+			return fail(fmt.Errorf("failed to handle %s page %d: %w", w.what, page, err))
+		}
+		logger.LogInfo(fmt.Sprintf("Fetched %s page %d from %s/%s successfully", w.what, page, w.owner, w.repo))
+
+		url = httpclient.NextPageURL(resp.Header)
+		if url != "" && w.stop != nil && w.stop(page, current) {
+			break
+		}
+	}
+
+	if w.done != nil {
+		if err := w.done(); err != nil {
+			return fail(fmt.Errorf("failed to handle %s: %w", w.what, err))
+		}
+	}
+	return nil
+}
+
+// CommitPageHandler is called with every page of commits as soon as it is fetched.
+// Returning an error stops the pagination.
+type CommitPageHandler func(page int, commits []Commit) error
+
 // FetchRepositoryCommits fetches commits for a given repository from GitHub.
-// It takes the repository owner, repository name, and the time from which to fetch commits.
-func (g *GithubClient) FetchRepositoryCommits(ctx context.Context, owner, repo string, since time.Time) ([]Commit, error) {
-	// Format the time as a string in RFC3339 format
-	sinceStr := since.Format(time.RFC3339)
+// It follows the Link rel="next" chain page by page, starting at opts.StartPage and stopping
+// after opts.MaxPages pages when set, and hands every page to the handler as it arrives.
+func (g *GithubClient) FetchRepositoryCommits(ctx context.Context, owner, repo string, opts domain.CommitFetchOptions, handle CommitPageHandler) error {
 	// Construct the URL for fetching commits
-	url := fmt.Sprintf("%s/%s/%s/commits?per_page=%s&until=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE, sinceStr)
+	url := fmt.Sprintf("%s/%s/%s/commits?per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
 
-	// Perform the GET request using the custom HTTP client
-	body, err := g.client.ApiCall(ctx, "GET", url, nil)
+	// Restrict the commits to the requested window, formatted in RFC3339 as UTC so no offset needs escaping
+	if !opts.Since.IsZero() {
+		url = fmt.Sprintf("%s&since=%s", url, opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		url = fmt.Sprintf("%s&until=%s", url, opts.Until.UTC().Format(time.RFC3339))
+	}
+	// Walk the history of the requested branch rather than the default one
+	if opts.Branch != "" {
+		url = fmt.Sprintf("%s&sha=%s", url, neturl.QueryEscape(opts.Branch))
+	}
+
+	start := max(opts.StartPage, 1)
+	if start > 1 {
+		url = fmt.Sprintf("%s&page=%d", url, start)
+	}
+
+	return pageWalk[[]Commit]{
+		what: "commits", owner: owner, repo: repo, url: url, page: start, revalidate: true,
+		onPage: handle,
+		// Stop once the page cap is reached, reporting where to resume from
+		stop: func(page int, _ []Commit) bool {
+			if opts.MaxPages > 0 && page-start+1 >= opts.MaxPages {
+				logger.LogWarning(fmt.Sprintf("Reached page limit of %d for %s/%s, resume from page %d", opts.MaxPages, owner, repo, page+1))
+				return true
+			}
+			return false
+		},
+	}.walk(ctx, g.client)
+}
+
+// FetchCommitDetail fetches a single commit with its change stats and the files it touched.
+// GitHub pages the file list of large commits, so every page is followed and the files are combined.
+func (g *GithubClient) FetchCommitDetail(ctx context.Context, owner, repo, sha string) (*CommitDetail, error) {
+	// Construct the URL for fetching the commit
+	url := fmt.Sprintf("%s/%s/%s/commits/%s", g.cfg.BASE_URL, owner, repo, sha)
+
+	// A commit never changes, so there is no point keeping validators to revalidate it
+	var detail *CommitDetail
+	err := pageWalk[CommitDetail]{what: "commit " + sha, owner: owner, repo: repo, url: url, onPage: func(page int, current CommitDetail) error {
+		if detail == nil {
+			detail = &current
+		} else {
+			detail.Files = append(detail.Files, current.Files...)
+		}
+		return nil
+	}}.walk(ctx, g.client)
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// PullsHandler is called with the pull requests fetched by FetchRepositoryPulls.
+// Returning an error makes the next fetch walk every page again.
+type PullsHandler func(pulls []PullRequest) error
+
+// FetchRepositoryPulls fetches the pull requests of a repository in every state updated since the given time.
+// The list is walked most recently updated first until it reaches pull requests last updated before since,
+// which are left out, so an incremental sync only fetches what changed. A zero since walks every page.
+// The pull requests are handed over at once, least recently updated first, so a consumer saving them in order
+// always has stored everything up to the last one saved.
+func (g *GithubClient) FetchRepositoryPulls(ctx context.Context, owner, repo string, since time.Time, handle PullsHandler) error {
+	url := fmt.Sprintf("%s/%s/%s/pulls?state=all&sort=updated&direction=desc&per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
+
+	var pulls []PullRequest
+	seen := make(map[int]bool)
+	return pageWalk[[]PullRequest]{
+		what: "pull requests", owner: owner, repo: repo, url: url, revalidate: true,
+		onPage: func(page int, current []PullRequest) error {
+			for _, pull := range current {
+				// Leave out the pull requests last updated before the last sync
+				if !since.IsZero() && pull.UpdatedAt.Before(since) {
+					continue
+				}
+				// A pull request updated during the walk pushes the others down, so the end of a page can show up again on the next
+				if seen[pull.Number] {
+					continue
+				}
+				seen[pull.Number] = true
+				pulls = append(pulls, pull)
+			}
+			return nil
+		},
+		// Once a page reaches the pull requests updated before the last sync, the rest of the list is older still
+		stop: func(page int, current []PullRequest) bool {
+			return !since.IsZero() && len(current) > 0 && current[len(current)-1].UpdatedAt.Before(since)
+		},
+		done: func() error {
+			slices.Reverse(pulls)
+			return handle(pulls)
+		},
+	}.walk(ctx, g.client)
+}
+
+// FetchPullReviews fetches every review of a pull request, following the pages of large review lists
+func (g *GithubClient) FetchPullReviews(ctx context.Context, owner, repo string, number int) ([]Review, error) {
+	url := fmt.Sprintf("%s/%s/%s/pulls/%d/reviews?per_page=%s", g.cfg.BASE_URL, owner, repo, number, g.cfg.PER_PAGE)
+
+	// The reviews are only fetched when the pull request changed, so validators would never be used
+	var reviews []Review
+	err := pageWalk[[]Review]{what: fmt.Sprintf("reviews of pull request %d", number), owner: owner, repo: repo, url: url, onPage: func(page int, current []Review) error {
+		reviews = append(reviews, current...)
+		return nil
+	}}.walk(ctx, g.client)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// IssuesHandler is called with the issues fetched by FetchRepositoryIssues.
+// Returning an error makes the next fetch walk every page again.
+type IssuesHandler func(issues []Issue) error
+
+// FetchRepositoryIssues fetches the issues of a repository in every state updated at or after the given time,
+// including pull requests, which GitHub lists as issues. A zero since walks every issue.
+// The list is walked most recently updated first, so an issue updated during the walk shows up twice rather than being
+// skipped, and the issues are handed over at once, least recently updated first, so a consumer saving them in order
+// always has stored everything up to the last one saved.
+func (g *GithubClient) FetchRepositoryIssues(ctx context.Context, owner, repo string, since time.Time, handle IssuesHandler) error {
+	url := fmt.Sprintf("%s/%s/%s/issues?state=all&sort=updated&direction=desc&per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
+	if !since.IsZero() {
+		url = fmt.Sprintf("%s&since=%s", url, since.UTC().Format(time.RFC3339))
+	}
+
+	var issues []Issue
+	seen := make(map[int]bool)
+	return pageWalk[[]Issue]{
+		what: "issues", owner: owner, repo: repo, url: url, revalidate: true,
+		onPage: func(page int, current []Issue) error {
+			for _, issue := range current {
+				if seen[issue.Number] {
+					continue
+				}
+				seen[issue.Number] = true
+				issues = append(issues, issue)
+			}
+			return nil
+		},
+		done: func() error {
+			slices.Reverse(issues)
+			return handle(issues)
+		},
+	}.walk(ctx, g.client)
+}
+
+// IssueEventsHandler is called with the issue events fetched by FetchRepositoryIssueEvents.
+// Returning an error makes the next fetch walk every page again.
+type IssueEventsHandler func(events []IssueEvent) error
+
+// FetchRepositoryIssueEvents fetches the events of every issue of a repository created at or after the given time.
+// GitHub lists them newest first and has no since filter, so pages are walked until one reaches older events,
+// which are left out. The events are handed over at once, oldest first.
+func (g *GithubClient) FetchRepositoryIssueEvents(ctx context.Context, owner, repo string, since time.Time, handle IssueEventsHandler) error {
+	url := fmt.Sprintf("%s/%s/%s/issues/events?per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
+
+	var events []IssueEvent
+	seen := make(map[int64]bool)
+	return pageWalk[[]IssueEvent]{
+		what: "issue events", owner: owner, repo: repo, url: url, revalidate: true,
+		onPage: func(page int, current []IssueEvent) error {
+			for _, event := range current {
+				if !since.IsZero() && event.CreatedAt.Before(since) {
+					continue
+				}
+				// New events push the others down, so the end of a page can show up again on the next
+				if seen[event.ID] {
+					continue
+				}
+				seen[event.ID] = true
+				events = append(events, event)
+			}
+			return nil
+		},
+		// Once a page reaches events created before since, the rest of the list is older still
+		stop: func(page int, current []IssueEvent) bool {
+			return !since.IsZero() && len(current) > 0 && current[len(current)-1].CreatedAt.Before(since)
+		},
+		done: func() error {
+			slices.Reverse(events)
+			return handle(events)
+		},
+	}.walk(ctx, g.client)
+}
+
+// FetchRepositoryReleases fetches every release of a repository, following every page.
+// Releases can be edited and added to any page, so the list is always fetched in full rather than revalidated.
+func (g *GithubClient) FetchRepositoryReleases(ctx context.Context, owner, repo string) ([]Release, error) {
+	url := fmt.Sprintf("%s/%s/%s/releases?per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
+
+	var releases []Release
+	err := pageWalk[[]Release]{what: "releases", owner: owner, repo: repo, url: url, onPage: func(page int, current []Release) error {
+		releases = append(releases, current...)
+		return nil
+	}}.walk(ctx, g.client)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LogInfo(fmt.Sprintf("Fetched %d releases from %s/%s successfully", len(releases), owner, repo))
+	return releases, nil
+}
+
+// FetchRepositoryTags fetches every tag of a repository with the commit it points to, following every page.
+// Like releases, the list is always fetched in full.
+func (g *GithubClient) FetchRepositoryTags(ctx context.Context, owner, repo string) ([]Tag, error) {
+	url := fmt.Sprintf("%s/%s/%s/tags?per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
+
+	var tags []Tag
+	err := pageWalk[[]Tag]{what: "tags", owner: owner, repo: repo, url: url, onPage: func(page int, current []Tag) error {
+		tags = append(tags, current...)
+		return nil
+	}}.walk(ctx, g.client)
 	if err != nil {
-		logger.LogWarning(fmt.Sprintf("Error fetching commits for %s/%s: %v", owner, repo, err))
 		return nil, err
 	}
 
-	// Unmarshal the response body into the slice of Commit structs
-	var commits []Commit
-	if err := json.Unmarshal(body, &commits); err != nil {
-		logger.LogWarning(fmt.Sprintf("Error unmarshaling commits for %s/%s: %v", owner, repo, err))
+	logger.LogInfo(fmt.Sprintf("Fetched %d tags from %s/%s successfully", len(tags), owner, repo))
+	return tags, nil
+}
+
+// FetchRepositoryBranches fetches every branch of a repository with the commit at its head, following every page.
+// Like tags, the list is always fetched in full.
+func (g *GithubClient) FetchRepositoryBranches(ctx context.Context, owner, repo string) ([]Branch, error) {
+	url := fmt.Sprintf("%s/%s/%s/branches?per_page=%s", g.cfg.BASE_URL, owner, repo, g.cfg.PER_PAGE)
+
+	var branches []Branch
+	err := pageWalk[[]Branch]{what: "branches", owner: owner, repo: repo, url: url, onPage: func(page int, current []Branch) error {
+		branches = append(branches, current...)
+		return nil
+	}}.walk(ctx, g.client)
+	if err != nil {
 		return nil, err
 	}
 
-	logger.LogInfo(fmt.Sprintf("Fetched commits from %s/%s successfully", owner, repo))
-	return commits, nil
+	logger.LogInfo(fmt.Sprintf("Fetched %d branches from %s/%s successfully", len(branches), owner, repo))
+	return branches, nil
 }
 
 // FetchRepositoryMetaData fetches metadata for a given repository from GitHub.
@@ -63,6 +377,10 @@ func (g *GithubClient) FetchRepositoryMetaData(ctx context.Context, owner, repo
 
 	// Perform the GET request using the custom HTTP client
 	body, err := g.client.ApiCall(ctx, "GET", url, nil)
+	if errors.Is(err, httpclient.ErrNotModified) {
+		logger.LogInfo(fmt.Sprintf("Repository metadata for %s/%s not modified since last fetch", owner, repo))
+		return nil, err
+	}
 	if err != nil {
 		logger.LogWarning(fmt.Sprintf("Error fetching metadata for repository %s/%s: %v", owner, repo, err))
 		return nil, err
@@ -78,3 +396,22 @@ func (g *GithubClient) FetchRepositoryMetaData(ctx context.Context, owner, repo
 	logger.LogInfo(fmt.Sprintf("Fetched repository metadata for %s/%s successfully", owner, repo))
 	return &repository, nil
 }
+
+// ForgetRepositoryMetaData drops the validators cached for the metadata of a repository,
+// so the next FetchRepositoryMetaData is sent unconditionally and returns the metadata in full.
+func (g *GithubClient) ForgetRepositoryMetaData(owner, repo string) {
+	g.client.Forget(fmt.Sprintf("%s/%s/%s", g.cfg.BASE_URL, owner, repo))
+}
+
+// ForgetRepositoryCommits drops the validators cached for every commit listing of a repository, whatever its window,
+// branch or page, so the next fetches of them are sent unconditionally and return every commit.
+func (g *GithubClient) ForgetRepositoryCommits(owner, repo string) {
+	g.client.ForgetPrefix(fmt.Sprintf("%s/%s/%s/commits?", g.cfg.BASE_URL, owner, repo))
+}
+
+// RateLimit returns the quota GitHub last reported, whether one has been reported yet,
+// and when a back-off requested by GitHub ends (the zero time if there is none).
+func (g *GithubClient) RateLimit() (httpclient.Quota, bool, time.Time) {
+	quota, known := g.client.Quota()
+	return quota, known, g.client.BlockedUntil()
+}
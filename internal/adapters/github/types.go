@@ -2,9 +2,16 @@ package github
 
 import "time"
 
+// User is the GitHub account a commit identity is linked to.
+// GitHub leaves it null when the email does not belong to any account.
+type User struct {
+	Login string `json:"login"`
+	ID    int64  `json:"id"`
+}
+
 type Commit struct {
-	Author      interface{} `json:"author"`
-	CommentsURL string      `json:"comments_url"`
+	Author      *User  `json:"author"`
+	CommentsURL string `json:"comments_url"`
 	Commit      struct {
 		Author struct {
 			Date  time.Time `json:"date"`
@@ -30,9 +37,9 @@ type Commit struct {
 			Verified  bool        `json:"verified"`
 		} `json:"verification"`
 	} `json:"commit"`
-	Committer interface{} `json:"committer"`
-	HTMLURL   string      `json:"html_url"`
-	NodeID    string      `json:"node_id"`
+	Committer *User  `json:"committer"`
+	HTMLURL   string `json:"html_url"`
+	NodeID    string `json:"node_id"`
 	Parents   []struct {
 		HTMLURL string `json:"html_url"`
 		SHA     string `json:"sha"`
@@ -42,6 +49,27 @@ type Commit struct {
 	URL string `json:"url"`
 }
 
+// CommitDetail is a single commit as returned by the commit endpoint, with its change stats and files
+type CommitDetail struct {
+	Commit
+	Stats struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+		Total     int `json:"total"`
+	} `json:"stats"`
+	Files []CommitFile `json:"files"`
+}
+
+// CommitFile is a file changed by a commit
+type CommitFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Changes          int    `json:"changes"`
+}
+
 type Repository struct {
 	Name             string    `json:"name"`
 	Description      string    `json:"description"`
@@ -52,6 +80,95 @@ type Repository struct {
 	OpenIssuesCount  int       `json:"open_issues_count"`
 	WatchersCount    int       `json:"watchers_count"`
 	SubscribersCount int       `json:"subscribers_count"`
+	DefaultBranch    string    `json:"default_branch"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
+
+// PullRequest is a pull request as listed by the pulls endpoint
+type PullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	State          string     `json:"state"` // open or closed; merged pull requests are closed with a merged_at time
+	Draft          bool       `json:"draft"`
+	User           *User      `json:"user"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	MergedAt       *time.Time `json:"merged_at"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	HTMLURL        string     `json:"html_url"`
+	Base           struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+// Review is a review of a pull request
+type Review struct {
+	State       string     `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED or PENDING
+	SubmittedAt *time.Time `json:"submitted_at"`
+}
+
+// Issue is an issue as listed by the issues endpoint, which lists pull requests as issues too
+type Issue struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	State       string     `json:"state"`
+	StateReason string     `json:"state_reason"`
+	User        *User      `json:"user"`
+	Labels      []Label    `json:"labels"`
+	Assignees   []User     `json:"assignees"`
+	Comments    int        `json:"comments"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	HTMLURL     string     `json:"html_url"`
+	PullRequest *struct{}  `json:"pull_request"` // Set when the issue is a pull request
+}
+
+// Label is a label attached to an issue
+type Label struct {
+	Name string `json:"name"`
+}
+
+// IssueEvent is an event in the timeline of an issue, as listed by the repository's issue events endpoint
+type IssueEvent struct {
+	ID        int64     `json:"id"`
+	Event     string    `json:"event"` // closed, reopened, labeled, assigned...
+	Actor     *User     `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+	Issue     Issue     `json:"issue"`
+}
+
+// Release is a release as listed by the releases endpoint
+type Release struct {
+	ID              int64      `json:"id"`
+	TagName         string     `json:"tag_name"`
+	TargetCommitish string     `json:"target_commitish"` // The branch or commit the tag was created from, if it did not exist
+	Name            string     `json:"name"`
+	Draft           bool       `json:"draft"`
+	Prerelease      bool       `json:"prerelease"`
+	Author          *User      `json:"author"`
+	CreatedAt       time.Time  `json:"created_at"`
+	PublishedAt     *time.Time `json:"published_at"`
+	HTMLURL         string     `json:"html_url"`
+}
+
+// Tag is a tag as listed by the tags endpoint, with the commit it points to
+type Tag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// Branch is a branch of a repository as listed by the branches endpoint
+type Branch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
@@ -0,0 +1,56 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+
+	"gorm.io/gorm"
+)
+
+// BackfillRepositoryImpl implements the PostgresBackfill interface using GORM
+type BackfillRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewBackfillRepository creates a new instance of BackfillRepositoryImpl.
+// It returns an error if the provided database connection is nil.
+func NewBackfillRepository(db *gorm.DB) (ports.PostgresBackfill, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	return &BackfillRepositoryImpl{DB: db}, nil
+}
+
+// SaveBackfill saves every field of a backfill, creating it if it has no ID yet
+func (b *BackfillRepositoryImpl) SaveBackfill(ctx context.Context, backfill *domain.Backfill) error {
+	if err := b.DB.WithContext(ctx).Save(backfill).Error; err != nil {
+		return fmt.Errorf("failed to save backfill for %s/%s: %w", backfill.Owner, backfill.Repository, err)
+	}
+	return nil
+}
+
+// GetBackfill retrieves the backfill of a repository.
+// It returns nil if the repository has none.
+func (b *BackfillRepositoryImpl) GetBackfill(ctx context.Context, owner, repositoryName string) (*domain.Backfill, error) {
+	var backfill domain.Backfill
+	err := b.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).First(&backfill).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve backfill for %s/%s: %w", owner, repositoryName, err)
+	}
+	return &backfill, nil
+}
+
+// DeleteBackfill deletes the backfill of a repository, if any
+func (b *BackfillRepositoryImpl) DeleteBackfill(ctx context.Context, owner, repositoryName string) error {
+	err := b.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).Delete(&domain.Backfill{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete backfill for %s/%s: %w", owner, repositoryName, err)
+	}
+	return nil
+}
@@ -7,8 +7,11 @@ import (
 	"github-service/internal/core/domain"
 	"github-service/internal/ports"
 	"github-service/pkg/logger"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CommitRepositoryImpl implements the CommitRepository interface using GORM for database operations.
@@ -25,10 +28,31 @@ func NewCommitRepository(db *gorm.DB) (ports.PostgresCommit, error) {
 	return &CommitRepositoryImpl{DB: db}, nil
 }
 
-// SaveCommit saves a commit to the database.
+// commitBatchSize is the number of commits inserted per statement by SaveCommits
+const commitBatchSize = 100
+
+// commitUpsert makes saving a commit that is already stored update it in place instead of inserting a duplicate.
+// Commits are matched on the unique (owner, repository, hash) index. The resolved identity is cleared with the
+// author fields so it is resolved again, and the parsed message parts are replaced with the message, while
+// enrichment stats, pull request links and releases are kept.
+var commitUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "owner"}, {Name: "repository"}, {Name: "hash"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"message", "author", "email", "author_login", "author_date", "identity",
+		"committer", "committer_email", "committer_login", "commit_date",
+		"parents", "tree_sha", "comment_count", "url", "html_url",
+		"commit_type", "scope", "breaking", "subject", "co_authors", "signed_off_by",
+	}),
+}
+
+// SaveCommit saves a commit to the database, updating it if it is already stored. A commit without parents is saved
+// with an empty list, as NULL parents mark commits stored before parents were.
 // It returns an error if the save operation fails.
 func (c *CommitRepositoryImpl) SaveCommit(ctx context.Context, commit *domain.Commit) error {
-	if err := c.DB.WithContext(ctx).Create(commit).Error; err != nil {
+	if commit.Parents == nil {
+		commit.Parents = []string{}
+	}
+	if err := c.DB.WithContext(ctx).Clauses(commitUpsert).Create(commit).Error; err != nil {
 		logger.LogWarning(fmt.Sprintf("Failed to save commit for repository %s: %v", commit.Repository, err))
 		return err
 	}
@@ -36,88 +60,534 @@ func (c *CommitRepositoryImpl) SaveCommit(ctx context.Context, commit *domain.Co
 	return nil
 }
 
-// GetCommits retrieves a list of commits based on the repository name, page, and limit.
-// The page and limit parameters control pagination.
+// SaveCommits saves a batch of commits to the database in chunks, updating any that are already stored. Commits without
+// parents are saved with an empty list, as NULL parents mark commits stored before parents were.
+// It returns an error if the save operation fails.
+func (c *CommitRepositoryImpl) SaveCommits(ctx context.Context, commits []domain.Commit) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	for i := range commits {
+		if commits[i].Parents == nil {
+			commits[i].Parents = []string{}
+		}
+	}
+	if err := c.DB.WithContext(ctx).Clauses(commitUpsert).CreateInBatches(commits, commitBatchSize).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to save %d commits for repository %s: %v", len(commits), commits[0].Repository, err))
+		return err
+	}
+	logger.LogInfo(fmt.Sprintf("Successfully saved %d commits for repository %s", len(commits), commits[0].Repository))
+	return nil
+}
+
+// GetCommits retrieves a list of commits based on the repository owner and name, filter, page, and limit.
+// The page and limit parameters control pagination, and commits are ordered by commit date as the filter asks.
 // It returns a slice of Commit and an error if the query fails.
-func (c *CommitRepositoryImpl) GetCommits(ctx context.Context, repositoryName string, page, limit int) ([]domain.Commit, error) {
+func (c *CommitRepositoryImpl) GetCommits(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, page, limit int) ([]domain.Commit, error) {
 	if page < 1 || limit < 1 {
 		return nil, errors.New("page and limit must be greater than 0")
 	}
 
+	// The hash breaks ties between commits made in the same second, so pages do not overlap
+	order := "commit_date DESC, hash DESC"
+	if filter.Sort == domain.CommitSortDate {
+		order = "commit_date ASC, hash ASC"
+	}
+
 	var commits []domain.Commit
-	err := c.DB.WithContext(ctx).
-		Where("repository = ?", repositoryName).
+	err := applyCommitFilter(c.DB.WithContext(ctx), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Order(order).
 		Limit(limit).
 		Offset((page - 1) * limit).
 		Find(&commits).Error
 
 	if err != nil {
-		logger.LogWarning(fmt.Sprintf("Failed to retrieve commits for repository %s: %v", repositoryName, err))
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve commits for repository %s/%s: %v", owner, repositoryName, err))
 		return nil, err
 	}
 
-	logger.LogInfo(fmt.Sprintf("Successfully retrieved commits for repository %s", repositoryName))
+	logger.LogInfo(fmt.Sprintf("Successfully retrieved commits for repository %s/%s", owner, repositoryName))
 	return commits, nil
 }
 
-// GetTotalCommits retrieves the total number of commits for the provided repository name.
+// GetCommitsByCursor retrieves up to limit commits matching the filter that come after the cursor in the filter's order,
+// or before it for a backward cursor; a nil cursor starts at the beginning. Commits are returned in listing order.
+// It also reports whether more commits lie beyond them in the direction of travel.
+func (c *CommitRepositoryImpl) GetCommitsByCursor(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, cursor *domain.CommitCursor, limit int) ([]domain.Commit, bool, error) {
+	if limit < 1 {
+		return nil, false, errors.New("limit must be greater than 0")
+	}
+
+	// Walk the (commit_date, hash) key towards the end of the listing, or towards its start for a backward cursor
+	ascending := filter.Sort == domain.CommitSortDate
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		ascending = !ascending
+	}
+	order, compare := "commit_date DESC, hash DESC", "<"
+	if ascending {
+		order, compare = "commit_date ASC, hash ASC", ">"
+	}
+
+	query := applyCommitFilter(c.DB.WithContext(ctx), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName)
+	if cursor != nil {
+		query = query.Where(fmt.Sprintf("(commit_date %[1]s ? OR (commit_date = ? AND hash %[1]s ?))", compare), cursor.Date, cursor.Date, cursor.Hash)
+	}
+
+	// Fetch one extra commit to tell whether there is another page
+	var commits []domain.Commit
+	if err := query.Order(order).Limit(limit + 1).Find(&commits).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve commits for repository %s/%s: %v", owner, repositoryName, err))
+		return nil, false, err
+	}
+
+	more := len(commits) > limit
+	if more {
+		commits = commits[:limit]
+	}
+	if backward {
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+	}
+	return commits, more, nil
+}
+
+// GetTotalCommits retrieves the total number of commits for the provided repository owner and name that match the filter.
 // It returns the total count and an error if the query fails.
-func (c *CommitRepositoryImpl) GetTotalCommits(ctx context.Context, repositoryName string) (int64, error) {
+func (c *CommitRepositoryImpl) GetTotalCommits(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter) (int64, error) {
 	var totalCommits int64
-	err := c.DB.WithContext(ctx).
-		Where("repository = ?", repositoryName).
+	err := applyCommitFilter(c.DB.WithContext(ctx), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
 		Model(&domain.Commit{}).
 		Count(&totalCommits).Error
 
 	if err != nil {
-		logger.LogWarning(fmt.Sprintf("Failed to count commits for repository %s: %v", repositoryName, err))
+		logger.LogWarning(fmt.Sprintf("Failed to count commits for repository %s/%s: %v", owner, repositoryName, err))
 		return 0, err
 	}
 
-	logger.LogInfo(fmt.Sprintf("Successfully retrieved total commits count for repository %s", repositoryName))
+	logger.LogInfo(fmt.Sprintf("Successfully retrieved total commits count for repository %s/%s", owner, repositoryName))
 	return totalCommits, nil
 }
 
-// DeleteAllCommits deletes all commits for the given repository name.
+// likeEscaper escapes the LIKE wildcards in a search term so it matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// onBranch returns a subquery matching the commits of the outer query that are recorded on a branch
+func onBranch(db *gorm.DB, branch string) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&domain.CommitBranch{}).
+		Select("1").
+		Where("commit_branches.owner = commits.owner AND commit_branches.repository = commits.repository AND commit_branches.commit_hash = commits.hash").
+		Where("commit_branches.branch = ?", branch)
+}
+
+// applyCommitFilter adds the conditions of a commit filter to a query
+func applyCommitFilter(query *gorm.DB, filter domain.CommitFilter) *gorm.DB {
+	if filter.Author != "" {
+		query = query.Where("(LOWER(author) = LOWER(?) OR LOWER(author_login) = LOWER(?))", filter.Author, filter.Author)
+	}
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", filter.Email)
+	}
+	if filter.Identity != "" {
+		query = query.Where(authorIdentity+" = ?", filter.Identity)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("commit_date >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("commit_date <= ?", filter.Until)
+	}
+	if filter.Release != "" {
+		query = query.Where("release_tag = ?", filter.Release)
+	}
+	if filter.Branch != "" {
+		query = query.Where("EXISTS (?)", onBranch(query, filter.Branch))
+	}
+	if filter.MessageContains != "" {
+		query = query.Where(`LOWER(message) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(strings.ToLower(filter.MessageContains))+"%")
+	}
+	return query
+}
+
+// DeleteAllCommits deletes all commits for the given repository owner and name.
 // It returns a boolean indicating success and an error if the delete operation fails.
-func (c *CommitRepositoryImpl) DeleteAllCommits(ctx context.Context, repositoryName string) (bool, error) {
-	if repositoryName == "" {
-		return false, errors.New("repository name must not be empty")
+func (c *CommitRepositoryImpl) DeleteAllCommits(ctx context.Context, owner, repositoryName string) (bool, error) {
+	if owner == "" || repositoryName == "" {
+		return false, errors.New("repository owner and name must not be empty")
 	}
 
-	result := c.DB.WithContext(ctx).Where("repository = ?", repositoryName).Delete(&domain.Commit{})
+	// Drop the files recorded by enrichment along with the commits
+	if err := c.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).Delete(&domain.CommitFile{}).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to delete commit files for repository %s/%s: %v", owner, repositoryName, err))
+		return false, err
+	}
+	// And the branches they were recorded on
+	if err := c.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).Delete(&domain.CommitBranch{}).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to delete commit branches for repository %s/%s: %v", owner, repositoryName, err))
+		return false, err
+	}
+
+	result := c.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).Delete(&domain.Commit{})
 	if result.Error != nil {
-		logger.LogWarning(fmt.Sprintf("Failed to delete commits for repository %s: %v", repositoryName, result.Error))
+		logger.LogWarning(fmt.Sprintf("Failed to delete commits for repository %s/%s: %v", owner, repositoryName, result.Error))
 		return false, result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		logger.LogWarning(fmt.Sprintf("No commits found for repository %s to delete", repositoryName))
+		logger.LogWarning(fmt.Sprintf("No commits found for repository %s/%s to delete", owner, repositoryName))
 		return false, nil
 	}
 
-	logger.LogInfo(fmt.Sprintf("Successfully deleted all commits for repository %s", repositoryName))
+	logger.LogInfo(fmt.Sprintf("Successfully deleted all commits for repository %s/%s", owner, repositoryName))
 	return true, nil
 }
 
-// GetLastCommitByRepositoryName retrieves the latest commit for a given repository name.
+// GetLastCommitByRepositoryName retrieves the latest commit for a given repository owner and name.
 // It returns nil if no commit is found, or an error if the query fails.
-func (c *CommitRepositoryImpl) GetLastCommitByRepositoryName(ctx context.Context, repoName string) (*domain.Commit, error) {
+func (c *CommitRepositoryImpl) GetLastCommitByRepositoryName(ctx context.Context, owner, repoName string) (*domain.Commit, error) {
 	var commit domain.Commit
 	err := c.DB.WithContext(ctx).
-		Where("repository = ?", repoName).
+		Where("owner = ? AND repository = ?", owner, repoName).
 		Order("commit_date DESC").
 		First(&commit).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.LogWarning(fmt.Sprintf("No commit found for repository %s", repoName))
+			logger.LogWarning(fmt.Sprintf("No commit found for repository %s/%s", owner, repoName))
 			return nil, nil
 		}
-		logger.LogWarning(fmt.Sprintf("Failed to retrieve latest commit for repository %s: %v", repoName, err))
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve latest commit for repository %s/%s: %v", owner, repoName, err))
 		return nil, fmt.Errorf("failed to get latest commit: %w", err)
 	}
 
-	logger.LogInfo(fmt.Sprintf("Successfully retrieved latest commit for repository %s", repoName))
+	logger.LogInfo(fmt.Sprintf("Successfully retrieved latest commit for repository %s/%s", owner, repoName))
+	return &commit, nil
+}
+
+// GetLastBranchCommit retrieves the latest commit of a repository recorded on a branch.
+// It returns nil if no commit of the branch is stored, or an error if the query fails.
+func (c *CommitRepositoryImpl) GetLastBranchCommit(ctx context.Context, owner, repoName, branch string) (*domain.Commit, error) {
+	var commit domain.Commit
+	err := applyCommitFilter(c.DB.WithContext(ctx), domain.CommitFilter{Branch: branch}).
+		Where("owner = ? AND repository = ?", owner, repoName).
+		Order("commit_date DESC").
+		First(&commit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve latest commit of branch %s for repository %s/%s: %v", branch, owner, repoName, err))
+		return nil, fmt.Errorf("failed to get latest commit of branch %s: %w", branch, err)
+	}
+	return &commit, nil
+}
+
+// SaveCommitBranches records that the commits of a repository with the given hashes are on a branch.
+// Commits already recorded on it are left as they are.
+func (c *CommitRepositoryImpl) SaveCommitBranches(ctx context.Context, owner, repositoryName, branch string, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	memberships := make([]domain.CommitBranch, len(hashes))
+	for i, hash := range hashes {
+		memberships[i] = domain.CommitBranch{Owner: owner, Repository: repositoryName, CommitHash: hash, Branch: branch}
+	}
+	err := c.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(memberships, commitBatchSize).Error
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to record %d commits on branch %s for repository %s/%s: %v", len(hashes), branch, owner, repositoryName, err))
+		return err
+	}
+	return nil
+}
+
+// AssignUnbranchedCommits records every commit of a repository not recorded on any branch as being on the given one.
+// It returns the number of commits recorded.
+func (c *CommitRepositoryImpl) AssignUnbranchedCommits(ctx context.Context, owner, repositoryName, branch string) (int64, error) {
+	result := c.DB.WithContext(ctx).Exec(`INSERT INTO commit_branches (owner, repository, commit_hash, branch)
+		SELECT owner, repository, hash, CAST(? AS TEXT) FROM commits
+		WHERE owner = ? AND repository = ? AND NOT EXISTS (
+			SELECT 1 FROM commit_branches
+			WHERE commit_branches.owner = commits.owner AND commit_branches.repository = commits.repository AND commit_branches.commit_hash = commits.hash
+		)`, branch, owner, repositoryName)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to record the commits of %s/%s on branch %s: %w", owner, repositoryName, branch, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetCommit retrieves a commit by its repository owner, repository name and hash.
+// It returns nil if the commit is not stored, or an error if the query fails.
+func (c *CommitRepositoryImpl) GetCommit(ctx context.Context, owner, repositoryName, hash string) (*domain.Commit, error) {
+	var commit domain.Commit
+	err := c.DB.WithContext(ctx).
+		Where("owner = ? AND repository = ? AND hash = ?", owner, repositoryName, hash).
+		First(&commit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve commit %s for repository %s/%s: %v", hash, owner, repositoryName, err))
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
 	return &commit, nil
 }
+
+// GetCommitFiles retrieves the files changed by a commit, ordered by filename.
+// It returns an error if the query fails.
+func (c *CommitRepositoryImpl) GetCommitFiles(ctx context.Context, owner, repositoryName, hash string) ([]domain.CommitFile, error) {
+	var files []domain.CommitFile
+	err := c.DB.WithContext(ctx).
+		Where("owner = ? AND repository = ? AND commit_hash = ?", owner, repositoryName, hash).
+		Order("filename ASC").
+		Find(&files).Error
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve files of commit %s for repository %s/%s: %v", hash, owner, repositoryName, err))
+		return nil, fmt.Errorf("failed to get commit files: %w", err)
+	}
+	return files, nil
+}
+
+// GetUnenrichedCommits retrieves up to limit commits of a repository, newest first, that have no stats or files recorded yet.
+// It returns an error if the query fails.
+func (c *CommitRepositoryImpl) GetUnenrichedCommits(ctx context.Context, owner, repositoryName string, limit int) ([]domain.Commit, error) {
+	var commits []domain.Commit
+	err := c.DB.WithContext(ctx).
+		Where("owner = ? AND repository = ? AND enriched_at IS NULL", owner, repositoryName).
+		Order("commit_date DESC").
+		Limit(limit).
+		Find(&commits).Error
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to retrieve unenriched commits for repository %s/%s: %v", owner, repositoryName, err))
+		return nil, fmt.Errorf("failed to get unenriched commits: %w", err)
+	}
+	return commits, nil
+}
+
+// SaveCommitDetail records the change stats of a stored commit and replaces its changed files, in one transaction.
+// It returns an error if the commit is not stored or the save fails.
+func (c *CommitRepositoryImpl) SaveCommitDetail(ctx context.Context, detail *domain.CommitDetail) error {
+	commit := detail.Commit
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Commit{}).
+			Where("owner = ? AND repository = ? AND hash = ?", commit.Owner, commit.Repository, commit.Hash).
+			Updates(map[string]interface{}{
+				"additions":   commit.Additions,
+				"deletions":   commit.Deletions,
+				"changes":     commit.Changes,
+				"enriched_at": commit.EnrichedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to save stats of commit %s: %w", commit.Hash, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("commit %s of %s/%s is not stored", commit.Hash, commit.Owner, commit.Repository)
+		}
+
+		err := tx.Where("owner = ? AND repository = ? AND commit_hash = ?", commit.Owner, commit.Repository, commit.Hash).
+			Delete(&domain.CommitFile{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to replace files of commit %s: %w", commit.Hash, err)
+		}
+		if len(detail.Files) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(detail.Files, commitBatchSize).Error; err != nil {
+			return fmt.Errorf("failed to save files of commit %s: %w", commit.Hash, err)
+		}
+		return nil
+	})
+}
+
+// activityBucket returns the SQL expression for the start of the bucket the timestamp column falls in, in UTC.
+// Postgres truncates the timestamp directly, while SQLite, which is used in tests, computes the bucket's date as text.
+func activityBucket(db *gorm.DB, interval domain.ActivityInterval, column string) string {
+	if db.Dialector.Name() == "sqlite" {
+		switch interval {
+		case domain.ActivityWeek:
+			return fmt.Sprintf("DATE(%[1]s, '-' || ((CAST(STRFTIME('%%w', %[1]s) AS INTEGER) + 6) %% 7) || ' days')", column)
+		case domain.ActivityMonth:
+			return fmt.Sprintf("STRFTIME('%%Y-%%m-01', %s)", column)
+		default:
+			return fmt.Sprintf("DATE(%s)", column)
+		}
+	}
+	switch interval {
+	case domain.ActivityWeek:
+		return fmt.Sprintf("DATE_TRUNC('week', %s AT TIME ZONE 'UTC')", column)
+	case domain.ActivityMonth:
+		return fmt.Sprintf("DATE_TRUNC('month', %s AT TIME ZONE 'UTC')", column)
+	default:
+		return fmt.Sprintf("DATE_TRUNC('day', %s AT TIME ZONE 'UTC')", column)
+	}
+}
+
+// activityRow is a bucket count as scanned from the database
+type activityRow struct {
+	Bucket   scannedTime
+	Identity string
+	Author   string
+	Count    int
+}
+
+// GetCommitActivity counts the commits of a repository made between since and until per bucket of the interval,
+// and per author identity when byAuthor is set. Empty buckets are not returned. A zero since or until leaves that
+// end of the window open.
+func (c *CommitRepositoryImpl) GetCommitActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time, byAuthor bool) ([]domain.ActivityCount, error) {
+	bucket := activityBucket(c.DB, interval, "commit_date")
+	query := applyCommitFilter(c.DB.WithContext(ctx), domain.CommitFilter{Since: since, Until: until}).
+		Model(&domain.Commit{}).
+		Where("owner = ? AND repository = ?", owner, repositoryName)
+	if byAuthor {
+		query = query.
+			Select(bucket + " AS bucket, " + authorIdentity + " AS identity, MAX(author) AS author, COUNT(*) AS count").
+			Group(bucket + ", " + authorIdentity)
+	} else {
+		query = query.Select(bucket + " AS bucket, COUNT(*) AS count").Group(bucket)
+	}
+
+	var rows []activityRow
+	if err := query.Order("bucket ASC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count commit activity for repository %s/%s: %w", owner, repositoryName, err)
+	}
+
+	counts := make([]domain.ActivityCount, len(rows))
+	for i, row := range rows {
+		counts[i] = domain.ActivityCount{Bucket: row.Bucket.UTC(), Identity: row.Identity, Author: row.Author, Count: row.Count}
+	}
+	if !byAuthor {
+		return counts, nil
+	}
+
+	// Name authors resolved to a managed identity after it
+	keys := make([]string, 0, len(counts))
+	for _, count := range counts {
+		keys = append(keys, count.Identity)
+	}
+	identities, err := managedIdentities(ctx, c.DB, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, count := range counts {
+		if identity, ok := identities[count.Identity]; ok && identity.Name != "" {
+			counts[i].Author = identity.Name
+		}
+	}
+	return counts, nil
+}
+
+// EachAuthorDate calls fn with the author date of every commit of a repository that matches the filter, in no
+// particular order. The dates are streamed rather than loaded at once, so large histories can be walked.
+func (c *CommitRepositoryImpl) EachAuthorDate(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, fn func(date time.Time) error) error {
+	rows, err := applyCommitFilter(c.DB.WithContext(ctx), filter).
+		Model(&domain.Commit{}).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Select(authorDate).
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve author dates for repository %s/%s: %w", owner, repositoryName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var date scannedTime
+		if err := rows.Scan(&date); err != nil {
+			return fmt.Errorf("failed to read author date: %w", err)
+		}
+		if err := fn(date.Time); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetUnparsedCommits retrieves up to limit commits, across all repositories, whose message has not been split into its
+// Conventional Commits parts, ordered by owner, repository and hash and starting after the commit after, if any.
+// Only the key fields and the message are loaded. The subject of commits stored before messages were parsed is NULL.
+func (c *CommitRepositoryImpl) GetUnparsedCommits(ctx context.Context, after *domain.Commit, limit int) ([]domain.Commit, error) {
+	query := c.DB.WithContext(ctx).
+		Model(&domain.Commit{}).
+		Select("owner", "repository", "hash", "message").
+		Where("(subject IS NULL OR subject = '') AND message <> ''")
+	if after != nil {
+		query = query.Where("(owner > ? OR (owner = ? AND (repository > ? OR (repository = ? AND hash > ?))))",
+			after.Owner, after.Owner, after.Repository, after.Repository, after.Hash)
+	}
+
+	var commits []domain.Commit
+	if err := query.Order("owner ASC, repository ASC, hash ASC").Limit(limit).Find(&commits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unparsed commits: %w", err)
+	}
+	return commits, nil
+}
+
+// GetMissingParents counts the commits of a repository whose parents are NULL, as they are for commits stored before
+// parents were, along with the commit dates of the oldest and newest of them. Commits without parents, such as a root
+// commit, are stored with an empty list instead.
+func (c *CommitRepositoryImpl) GetMissingParents(ctx context.Context, owner, repositoryName string) (domain.MissingParents, error) {
+	var missing domain.MissingParents
+	var first, last scannedTime
+	err := c.DB.WithContext(ctx).
+		Model(&domain.Commit{}).
+		Select("COUNT(*), MIN(commit_date), MAX(commit_date)").
+		Where("owner = ? AND repository = ? AND parents IS NULL", owner, repositoryName).
+		Row().Scan(&missing.Count, &first, &last)
+	if err != nil {
+		return missing, fmt.Errorf("failed to count the commits of %s/%s without parents: %w", owner, repositoryName, err)
+	}
+	missing.First, missing.Last = first.Time, last.Time
+	return missing, nil
+}
+
+// SaveCommitMessageParts records the Conventional Commits parts and trailers of stored commits, in one transaction
+func (c *CommitRepositoryImpl) SaveCommitMessageParts(ctx context.Context, commits []domain.Commit) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, commit := range commits {
+			err := tx.Model(&domain.Commit{}).
+				Where("owner = ? AND repository = ? AND hash = ?", commit.Owner, commit.Repository, commit.Hash).
+				Select("commit_type", "scope", "breaking", "subject", "co_authors", "signed_off_by").
+				Updates(&commit).Error
+			if err != nil {
+				return fmt.Errorf("failed to save message parts of commit %s: %w", commit.Hash, err)
+			}
+		}
+		return nil
+	})
+}
+
+// commitTypeRow is a bucket count of one commit type as scanned from the database
+type commitTypeRow struct {
+	Bucket     scannedTime
+	CommitType string
+	Count      int
+	Breaking   int
+}
+
+// GetCommitTypeActivity counts the commits of a repository made between since and until per bucket of the interval
+// and Conventional Commits type, along with how many are breaking. Empty buckets are not returned, and commits
+// whose message does not follow the convention have an empty type. A zero since or until leaves that end open.
+func (c *CommitRepositoryImpl) GetCommitTypeActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) ([]domain.CommitTypeCount, error) {
+	bucket := activityBucket(c.DB, interval, "commit_date")
+	var rows []commitTypeRow
+	err := applyCommitFilter(c.DB.WithContext(ctx), domain.CommitFilter{Since: since, Until: until}).
+		Model(&domain.Commit{}).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Select(bucket + " AS bucket, commit_type, COUNT(*) AS count, SUM(CASE WHEN breaking THEN 1 ELSE 0 END) AS breaking").
+		Group(bucket + ", commit_type").
+		Order("bucket ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count commit types for repository %s/%s: %w", owner, repositoryName, err)
+	}
+
+	counts := make([]domain.CommitTypeCount, len(rows))
+	for i, row := range rows {
+		counts[i] = domain.CommitTypeCount{Bucket: row.Bucket.UTC(), Type: row.CommitType, Count: row.Count, Breaking: row.Breaking}
+	}
+	return counts, nil
+}
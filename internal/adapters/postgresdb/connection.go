@@ -45,8 +45,17 @@ func Connect(cfg config.Config) (*gorm.DB, error) {
 
 	logger.LogInfo("Successfully connected to the database.")
 
+	// Remove duplicate commits so the unique commit index can be created
+	if err = dedupCommits(db); err != nil {
+		return nil, fmt.Errorf("failed to deduplicate commits: %w", err)
+	}
+	// And duplicate active jobs so the unique active job index can be created
+	if err = failDuplicateActiveJobs(db); err != nil {
+		return nil, fmt.Errorf("failed to deduplicate active jobs: %w", err)
+	}
+
 	// Automatically migrate the schema (create/update tables based on the provided models)
-	err = db.AutoMigrate(&domain.Commit{}, &domain.Repository{})
+	err = db.AutoMigrate(&domain.Commit{}, &domain.Repository{}, &domain.Job{}, &domain.Backfill{}, &domain.CommitFile{}, &domain.CommitBranch{}, &domain.Identity{}, &domain.IdentityAlias{}, &domain.PullRequest{}, &domain.Issue{}, &domain.IssueTransition{}, &domain.Tag{}, &domain.Release{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate database schema: %v", err)
 	}
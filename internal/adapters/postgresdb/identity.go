@@ -0,0 +1,226 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+
+	"gorm.io/gorm"
+)
+
+// IdentityRepositoryImpl implements the PostgresIdentity interface using GORM
+type IdentityRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewIdentityRepository creates a new instance of IdentityRepositoryImpl.
+// It returns an error if the provided database connection is nil.
+func NewIdentityRepository(db *gorm.DB) (ports.PostgresIdentity, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	return &IdentityRepositoryImpl{DB: db}, nil
+}
+
+// CreateIdentity stores a new identity along with its aliases and fills in their IDs
+func (i *IdentityRepositoryImpl) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	if err := i.DB.WithContext(ctx).Create(identity).Error; err != nil {
+		return fmt.Errorf("failed to create identity %s: %w", identity.Key, err)
+	}
+	return nil
+}
+
+// UpdateIdentity saves the name, email, login and key of an existing identity; its aliases are left as they are
+func (i *IdentityRepositoryImpl) UpdateIdentity(ctx context.Context, identity *domain.Identity) error {
+	err := i.DB.WithContext(ctx).Model(identity).Select("key", "name", "email", "login", "updated_at").Updates(identity).Error
+	if err != nil {
+		return fmt.Errorf("failed to update identity %d: %w", identity.ID, err)
+	}
+	return nil
+}
+
+// DeleteIdentity deletes an identity and its aliases.
+// It returns false if there is no identity with the ID.
+func (i *IdentityRepositoryImpl) DeleteIdentity(ctx context.Context, id uint) (bool, error) {
+	deleted := false
+	err := i.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("identity_id = ?", id).Delete(&domain.IdentityAlias{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.Identity{}, id)
+		deleted = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete identity %d: %w", id, err)
+	}
+	return deleted, nil
+}
+
+// GetIdentity retrieves an identity with its aliases by ID.
+// It returns nil if there is none.
+func (i *IdentityRepositoryImpl) GetIdentity(ctx context.Context, id uint) (*domain.Identity, error) {
+	var identity domain.Identity
+	err := i.DB.WithContext(ctx).Preload("Aliases").First(&identity, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve identity %d: %w", id, err)
+	}
+	return &identity, nil
+}
+
+// FindIdentityByEmail retrieves the identity with the given email, compared case-insensitively, with its aliases.
+// It returns nil if there is none.
+func (i *IdentityRepositoryImpl) FindIdentityByEmail(ctx context.Context, email string) (*domain.Identity, error) {
+	var identity domain.Identity
+	err := i.DB.WithContext(ctx).Preload("Aliases").Where("LOWER(email) = LOWER(?)", email).Order("id ASC").First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find identity by email: %w", err)
+	}
+	return &identity, nil
+}
+
+// ListIdentities retrieves identities with their aliases ordered by key, with pagination support.
+// It also returns the total number of identities.
+func (i *IdentityRepositoryImpl) ListIdentities(ctx context.Context, page, limit int) ([]domain.Identity, int64, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, errors.New("page and limit must be greater than 0")
+	}
+
+	var total int64
+	if err := i.DB.WithContext(ctx).Model(&domain.Identity{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count identities: %w", err)
+	}
+
+	var identities []domain.Identity
+	err := i.DB.WithContext(ctx).Preload("Aliases").Order("key ASC").Limit(limit).Offset((page - 1) * limit).Find(&identities).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve identities: %w", err)
+	}
+	return identities, total, nil
+}
+
+// GetAllIdentities retrieves every identity with its aliases
+func (i *IdentityRepositoryImpl) GetAllIdentities(ctx context.Context) ([]domain.Identity, error) {
+	var identities []domain.Identity
+	if err := i.DB.WithContext(ctx).Preload("Aliases").Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve identities: %w", err)
+	}
+	return identities, nil
+}
+
+// GetIdentitiesByKeys retrieves the identities with the given keys
+func (i *IdentityRepositoryImpl) GetIdentitiesByKeys(ctx context.Context, keys []string) ([]domain.Identity, error) {
+	var identities []domain.Identity
+	if len(keys) == 0 {
+		return identities, nil
+	}
+	if err := i.DB.WithContext(ctx).Where("key IN ?", keys).Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve identities: %w", err)
+	}
+	return identities, nil
+}
+
+// AddAlias stores a new alias of an identity and fills in its ID
+func (i *IdentityRepositoryImpl) AddAlias(ctx context.Context, alias *domain.IdentityAlias) error {
+	if err := i.DB.WithContext(ctx).Create(alias).Error; err != nil {
+		return fmt.Errorf("failed to add alias to identity %d: %w", alias.IdentityID, err)
+	}
+	return nil
+}
+
+// DeleteAlias deletes an alias of an identity.
+// It returns false if the identity has no alias with the ID.
+func (i *IdentityRepositoryImpl) DeleteAlias(ctx context.Context, identityID, aliasID uint) (bool, error) {
+	result := i.DB.WithContext(ctx).Where("identity_id = ?", identityID).Delete(&domain.IdentityAlias{}, aliasID)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete alias %d: %w", aliasID, result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetAuthorTuples retrieves the distinct author name, email, login and resolved identity combinations of the commits
+// of a repository, or of every repository when owner is empty. With unresolvedOnly, only commits without an identity count.
+// The login and identity of commits stored before they were kept are NULL, and are read as empty.
+func (i *IdentityRepositoryImpl) GetAuthorTuples(ctx context.Context, owner, repositoryName string, unresolvedOnly bool) ([]domain.AuthorTuple, error) {
+	query := i.DB.WithContext(ctx).Model(&domain.Commit{})
+	if owner != "" {
+		query = query.Where("owner = ? AND repository = ?", owner, repositoryName)
+	}
+	if unresolvedOnly {
+		query = query.Where("COALESCE(identity, '') = ''")
+	}
+
+	var tuples []domain.AuthorTuple
+	err := query.Select("DISTINCT author, email, COALESCE(author_login, '') AS author_login, COALESCE(identity, '') AS identity").Scan(&tuples).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve commit authors: %w", err)
+	}
+	return tuples, nil
+}
+
+// GetEmailLogins retrieves the GitHub login each lowercased email has committed with, across all repositories
+func (i *IdentityRepositoryImpl) GetEmailLogins(ctx context.Context) (map[string]string, error) {
+	var rows []struct {
+		Email string
+		Login string
+	}
+	err := i.DB.WithContext(ctx).
+		Model(&domain.Commit{}).
+		Select("LOWER(email) AS email, MIN(LOWER(author_login)) AS login").
+		Where("email <> '' AND author_login <> ''").
+		Group("LOWER(email)").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve email logins: %w", err)
+	}
+
+	logins := make(map[string]string, len(rows))
+	for _, row := range rows {
+		logins[row.Email] = row.Login
+	}
+	return logins, nil
+}
+
+// SetIdentity attributes the commits with the author fields of tuple, in a repository or in every repository
+// when owner is empty, to identity. A NULL login or identity matches an empty one, as GetAuthorTuples reads them.
+// It returns the number of commits updated.
+func (i *IdentityRepositoryImpl) SetIdentity(ctx context.Context, owner, repositoryName string, tuple domain.AuthorTuple, identity string) (int64, error) {
+	query := i.DB.WithContext(ctx).
+		Model(&domain.Commit{}).
+		Where("author = ? AND email = ? AND COALESCE(author_login, '') = ? AND COALESCE(identity, '') = ?", tuple.Author, tuple.Email, tuple.AuthorLogin, tuple.Identity)
+	if owner != "" {
+		query = query.Where("owner = ? AND repository = ?", owner, repositoryName)
+	}
+
+	result := query.Update("identity", identity)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to set commit identity: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// managedIdentities retrieves the managed identities with the given keys, by key.
+// Aggregations use it to label the identities they group commits by.
+func managedIdentities(ctx context.Context, db *gorm.DB, keys []string) (map[string]domain.Identity, error) {
+	byKey := make(map[string]domain.Identity)
+	if len(keys) == 0 {
+		return byKey, nil
+	}
+
+	var identities []domain.Identity
+	if err := db.WithContext(ctx).Where("key IN ?", keys).Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve author identities: %w", err)
+	}
+	for _, identity := range identities {
+		byKey[identity.Key] = identity
+	}
+	return byKey, nil
+}
@@ -0,0 +1,262 @@
+package postgresdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IssueRepositoryImpl implements the PostgresIssue interface using GORM
+type IssueRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewIssueRepository creates a new instance of IssueRepositoryImpl.
+// It returns an error if the provided database connection is nil.
+func NewIssueRepository(db *gorm.DB) (ports.PostgresIssue, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	return &IssueRepositoryImpl{DB: db}, nil
+}
+
+// issueUpsert makes saving an issue that is already stored update it in place.
+// Issues are matched on the unique (owner, repository, number) index.
+var issueUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "owner"}, {Name: "repository"}, {Name: "number"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"title", "state", "state_reason", "author", "labels", "assignees", "comments",
+		"created_at", "updated_at", "closed_at", "time_to_close", "html_url",
+	}),
+}
+
+// SaveIssues saves a batch of issues, updating any that are already stored.
+// It returns an error if the save operation fails.
+func (i *IssueRepositoryImpl) SaveIssues(ctx context.Context, issues []domain.Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	if err := i.DB.WithContext(ctx).Clauses(issueUpsert).CreateInBatches(issues, commitBatchSize).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to save %d issues for repository %s: %v", len(issues), issues[0].Repository, err))
+		return err
+	}
+	return nil
+}
+
+// SaveIssueTransitions saves a batch of issue transitions; transitions already stored are left as they are.
+// It returns an error if the save operation fails.
+func (i *IssueRepositoryImpl) SaveIssueTransitions(ctx context.Context, transitions []domain.IssueTransition) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+	err := i.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "owner"}, {Name: "repository"}, {Name: "event_id"}}, DoNothing: true}).
+		CreateInBatches(transitions, commitBatchSize).Error
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to save %d issue transitions for repository %s: %v", len(transitions), transitions[0].Repository, err))
+		return err
+	}
+	return nil
+}
+
+// ListIssues retrieves the issues of a repository matching the filter, newest first, with pagination support.
+// It also returns the total number of issues matching the filter.
+func (i *IssueRepositoryImpl) ListIssues(ctx context.Context, owner, repositoryName string, filter domain.IssueFilter, page, limit int) ([]domain.Issue, int64, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, errors.New("page and limit must be greater than 0")
+	}
+
+	var total int64
+	err := applyIssueFilter(i.DB.WithContext(ctx).Model(&domain.Issue{}), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count issues for %s/%s: %w", owner, repositoryName, err)
+	}
+
+	// The number breaks ties between issues opened in the same second, so pages do not overlap
+	var issues []domain.Issue
+	err = applyIssueFilter(i.DB.WithContext(ctx), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Order("created_at DESC, number DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&issues).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve issues for %s/%s: %w", owner, repositoryName, err)
+	}
+	return issues, total, nil
+}
+
+// applyIssueFilter narrows an issue query down to the issues matching the filter
+func applyIssueFilter(query *gorm.DB, filter domain.IssueFilter) *gorm.DB {
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	if filter.Author != "" {
+		query = query.Where("LOWER(author) = LOWER(?)", filter.Author)
+	}
+	// Labels and assignees are stored as JSON arrays of strings, so an element is matched with its quotes
+	if filter.Label != "" {
+		query = query.Where(`labels LIKE ? ESCAPE '\'`, jsonElementPattern(filter.Label))
+	}
+	if filter.Assignee != "" {
+		query = query.Where(`LOWER(assignees) LIKE ? ESCAPE '\'`, jsonElementPattern(strings.ToLower(filter.Assignee)))
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+	return query
+}
+
+// jsonElementPattern returns the LIKE pattern matching a JSON array of strings that contains value
+func jsonElementPattern(value string) string {
+	encoded, _ := json.Marshal(value)
+	return "%" + likeEscaper.Replace(string(encoded)) + "%"
+}
+
+// GetIssue retrieves an issue of a repository by number, with the transitions recorded for it, oldest first.
+// It returns nil if the issue is not stored.
+func (i *IssueRepositoryImpl) GetIssue(ctx context.Context, owner, repositoryName string, number int) (*domain.Issue, error) {
+	var issue domain.Issue
+	err := i.DB.WithContext(ctx).
+		Where("owner = ? AND repository = ? AND number = ?", owner, repositoryName, number).
+		First(&issue).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve issue %d of %s/%s: %w", number, owner, repositoryName, err)
+	}
+
+	err = i.DB.WithContext(ctx).
+		Where("owner = ? AND repository = ? AND number = ?", owner, repositoryName, number).
+		Order("created_at ASC, event_id ASC").
+		Find(&issue.Transitions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the transitions of issue %d of %s/%s: %w", number, owner, repositoryName, err)
+	}
+	return &issue, nil
+}
+
+// GetLatestIssueUpdate retrieves when the most recently updated issue of a repository was last updated.
+// It returns the zero time if no issue is stored.
+func (i *IssueRepositoryImpl) GetLatestIssueUpdate(ctx context.Context, owner, repositoryName string) (time.Time, error) {
+	var latest scannedTime
+	err := i.DB.WithContext(ctx).Model(&domain.Issue{}).
+		Select("MAX(updated_at)").
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Row().Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to retrieve the latest issue update for %s/%s: %w", owner, repositoryName, err)
+	}
+	return latest.Time, nil
+}
+
+// GetLatestIssueTransition retrieves when the most recent issue transition of a repository was made.
+// It returns the zero time if no transition is stored.
+func (i *IssueRepositoryImpl) GetLatestIssueTransition(ctx context.Context, owner, repositoryName string) (time.Time, error) {
+	var latest scannedTime
+	err := i.DB.WithContext(ctx).Model(&domain.IssueTransition{}).
+		Select("MAX(created_at)").
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Row().Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to retrieve the latest issue transition for %s/%s: %w", owner, repositoryName, err)
+	}
+	return latest.Time, nil
+}
+
+// issueRateRow is a bucket count as scanned from the database
+type issueRateRow struct {
+	Bucket      scannedTime
+	Count       int
+	TimeToClose int64
+}
+
+// GetIssueRates counts the issues of a repository opened and closed between since and until per bucket of the interval,
+// oldest first. Issues are counted as closed when they were last closed. Empty buckets are not returned.
+// A zero since or until leaves that end of the window open.
+func (i *IssueRepositoryImpl) GetIssueRates(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) ([]domain.IssueRateCount, error) {
+	window := func(column string) *gorm.DB {
+		query := i.DB.WithContext(ctx).Model(&domain.Issue{}).
+			Where("owner = ? AND repository = ?", owner, repositoryName).
+			Where(column + " IS NOT NULL")
+		if !since.IsZero() {
+			query = query.Where(column+" >= ?", since)
+		}
+		if !until.IsZero() {
+			query = query.Where(column+" <= ?", until)
+		}
+		return query
+	}
+
+	var opened, closed []issueRateRow
+	bucket := activityBucket(i.DB, interval, "created_at")
+	err := window("created_at").Select(bucket + " AS bucket, COUNT(*) AS count").Group(bucket).Scan(&opened).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count the issues opened in %s/%s: %w", owner, repositoryName, err)
+	}
+	bucket = activityBucket(i.DB, interval, "closed_at")
+	err = window("closed_at").Select(bucket + " AS bucket, COUNT(*) AS count, COALESCE(SUM(time_to_close), 0) AS time_to_close").Group(bucket).Scan(&closed).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count the issues closed in %s/%s: %w", owner, repositoryName, err)
+	}
+
+	// Merge both series into one count per bucket
+	byBucket := make(map[time.Time]*domain.IssueRateCount)
+	countOf := func(bucket time.Time) *domain.IssueRateCount {
+		bucket = bucket.UTC()
+		if _, ok := byBucket[bucket]; !ok {
+			byBucket[bucket] = &domain.IssueRateCount{Bucket: bucket}
+		}
+		return byBucket[bucket]
+	}
+	for _, row := range opened {
+		countOf(row.Bucket.Time).Opened = row.Count
+	}
+	for _, row := range closed {
+		count := countOf(row.Bucket.Time)
+		count.Closed = row.Count
+		count.TimeToClose = row.TimeToClose
+	}
+
+	counts := make([]domain.IssueRateCount, 0, len(byBucket))
+	for _, count := range byBucket {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(a, b int) bool { return counts[a].Bucket.Before(counts[b].Bucket) })
+	return counts, nil
+}
+
+// CountIssuesBefore counts the issues of a repository opened before the given time, and those of them last closed before it.
+// It returns an error if the query fails.
+func (i *IssueRepositoryImpl) CountIssuesBefore(ctx context.Context, owner, repositoryName string, before time.Time) (int64, int64, error) {
+	var opened, closed int64
+	err := i.DB.WithContext(ctx).Model(&domain.Issue{}).
+		Where("owner = ? AND repository = ? AND created_at < ?", owner, repositoryName, before).
+		Count(&opened).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count the issues opened in %s/%s: %w", owner, repositoryName, err)
+	}
+	err = i.DB.WithContext(ctx).Model(&domain.Issue{}).
+		Where("owner = ? AND repository = ? AND closed_at < ?", owner, repositoryName, before).
+		Count(&closed).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count the issues closed in %s/%s: %w", owner, repositoryName, err)
+	}
+	return opened, closed, nil
+}
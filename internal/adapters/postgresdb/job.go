@@ -0,0 +1,133 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobRepositoryImpl implements the PostgresJob interface using GORM
+type JobRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewJobRepository creates a new instance of JobRepositoryImpl.
+// It returns an error if the provided database connection is nil.
+func NewJobRepository(db *gorm.DB) (ports.PostgresJob, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	return &JobRepositoryImpl{DB: db}, nil
+}
+
+// CreateJob stores a new job and fills in its ID
+func (j *JobRepositoryImpl) CreateJob(ctx context.Context, job *domain.Job) error {
+	return j.DB.WithContext(ctx).Create(job).Error
+}
+
+// UpdateJob saves every field of an existing job
+func (j *JobRepositoryImpl) UpdateJob(ctx context.Context, job *domain.Job) error {
+	return j.DB.WithContext(ctx).Save(job).Error
+}
+
+// GetJob retrieves a job by its ID
+func (j *JobRepositoryImpl) GetJob(ctx context.Context, id uint) (domain.Job, error) {
+	var job domain.Job
+	err := j.DB.WithContext(ctx).First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.Job{}, fmt.Errorf("job %d not found", id)
+	}
+	return job, err
+}
+
+// ListJobs retrieves jobs newest first, optionally only those in the given status, with pagination support.
+// It also returns the total number of matching jobs.
+func (j *JobRepositoryImpl) ListJobs(ctx context.Context, status domain.JobStatus, page, limit int) ([]domain.Job, int64, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, errors.New("page and limit must be greater than 0")
+	}
+
+	query := j.DB.WithContext(ctx).Model(&domain.Job{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	var jobs []domain.Job
+	err := query.Order("id DESC").Limit(limit).Offset((page - 1) * limit).Find(&jobs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve jobs: %w", err)
+	}
+	return jobs, total, nil
+}
+
+// FindActiveJob retrieves the queued or running job of the given type for a repository.
+// It returns nil if there is none.
+func (j *JobRepositoryImpl) FindActiveJob(ctx context.Context, jobType, owner, repositoryName string) (*domain.Job, error) {
+	var job domain.Job
+	err := j.DB.WithContext(ctx).
+		Where("type = ? AND owner = ? AND repository = ?", jobType, owner, repositoryName).
+		Where("status IN ?", []domain.JobStatus{domain.JobQueued, domain.JobRunning}).
+		Order("id ASC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimDueJobs marks up to limit queued jobs whose RunAfter has passed as running and returns them, oldest first.
+func (j *JobRepositoryImpl) ClaimDueJobs(ctx context.Context, now time.Time, limit int) ([]domain.Job, error) {
+	var claimed []domain.Job
+	err := j.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []domain.Job
+		err := tx.Where("status = ? AND run_after <= ?", domain.JobQueued, now).
+			Order("id ASC").
+			Limit(limit).
+			Find(&jobs).Error
+		if err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			// Only claim the job if nobody else changed its status in the meantime
+			result := tx.Model(&domain.Job{}).
+				Where("id = ? AND status = ?", job.ID, domain.JobQueued).
+				Updates(map[string]interface{}{"status": domain.JobRunning, "updated_at": now})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				job.Status = domain.JobRunning
+				claimed = append(claimed, job)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	return claimed, nil
+}
+
+// RequeueRunningJobs puts every job left running back in the queue.
+// Jobs are only left running when the service stopped while working on them.
+func (j *JobRepositoryImpl) RequeueRunningJobs(ctx context.Context) (int64, error) {
+	result := j.DB.WithContext(ctx).
+		Model(&domain.Job{}).
+		Where("status = ?", domain.JobRunning).
+		Update("status", domain.JobQueued)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,79 @@
+package postgresdb
+
+import (
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// commitIdentityIndex is the unique index on (owner, repository, hash) that keeps commits from being stored twice
+const commitIdentityIndex = "idx_commits_owner_repo_hash"
+
+// dedupCommits prepares a commits table created before commits were unique for the commitIdentityIndex.
+// Earlier versions inserted every fetched commit, so overlapping polls left duplicate rows behind;
+// this fills in the owner column from the stored repository metadata and deletes all but one copy of each commit.
+// It only runs while the index is missing, so it happens once.
+func dedupCommits(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&domain.Commit{}) || migrator.HasIndex(&domain.Commit{}, commitIdentityIndex) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if !tx.Migrator().HasColumn(&domain.Commit{}, "Owner") {
+			if err := tx.Migrator().AddColumn(&domain.Commit{}, "Owner"); err != nil {
+				return fmt.Errorf("failed to add owner column to commits: %w", err)
+			}
+		}
+
+		// Commits were only keyed by repository name, so take the owner from the repository they belong to
+		if tx.Migrator().HasTable(&domain.Repository{}) {
+			err := tx.Exec(`UPDATE commits SET owner = repositories.owner FROM repositories
+				WHERE commits.repository = repositories.name AND (commits.owner IS NULL OR commits.owner = '')`).Error
+			if err != nil {
+				return fmt.Errorf("failed to backfill commit owners: %w", err)
+			}
+		}
+		if err := tx.Exec(`UPDATE commits SET owner = '' WHERE owner IS NULL`).Error; err != nil {
+			return fmt.Errorf("failed to backfill commit owners: %w", err)
+		}
+
+		// Keep the first stored copy of every commit
+		result := tx.Exec(`DELETE FROM commits a USING commits b
+			WHERE a.ctid > b.ctid AND a.owner = b.owner AND a.repository = b.repository AND a.hash = b.hash`)
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove duplicate commits: %w", result.Error)
+		}
+
+		logger.LogInfo(fmt.Sprintf("Removed %d duplicate commits", result.RowsAffected))
+		return nil
+	})
+}
+
+// activeJobIndex is the unique index that allows a repository one queued or running job of each type
+const activeJobIndex = "idx_jobs_active"
+
+// failDuplicateActiveJobs prepares a jobs table created before active jobs were unique for the activeJobIndex.
+// Concurrent enqueues could queue the same job twice; every active job but the first of each type and repository
+// is marked failed. It only runs while the index is missing, so it happens once.
+func failDuplicateActiveJobs(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&domain.Job{}) || migrator.HasIndex(&domain.Job{}, activeJobIndex) {
+		return nil
+	}
+
+	result := db.Exec(`UPDATE jobs SET status = ?, error = ? WHERE status IN ? AND EXISTS (
+		SELECT 1 FROM jobs earlier WHERE earlier.type = jobs.type AND earlier.owner = jobs.owner
+			AND earlier.repository = jobs.repository AND earlier.status IN ? AND earlier.id < jobs.id)`,
+		domain.JobFailed, "duplicate of an earlier active job",
+		[]domain.JobStatus{domain.JobQueued, domain.JobRunning}, []domain.JobStatus{domain.JobQueued, domain.JobRunning})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.LogInfo(fmt.Sprintf("Failed %d duplicate active jobs", result.RowsAffected))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PullRequestRepositoryImpl implements the PostgresPullRequest interface using GORM
+type PullRequestRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewPullRequestRepository creates a new instance of PullRequestRepositoryImpl.
+// It returns an error if the provided database connection is nil.
+func NewPullRequestRepository(db *gorm.DB) (ports.PostgresPullRequest, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	return &PullRequestRepositoryImpl{DB: db}, nil
+}
+
+// pullRequestUpsert makes saving a pull request that is already stored update it in place.
+// Pull requests are matched on the unique (owner, repository, number) index.
+var pullRequestUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "owner"}, {Name: "repository"}, {Name: "number"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"title", "state", "draft", "author", "base_ref", "head_ref",
+		"created_at", "updated_at", "closed_at", "merged_at", "merge_commit_sha",
+		"reviews", "approvals", "first_review_at", "html_url",
+	}),
+}
+
+// SavePullRequests saves a batch of pull requests, updating any that are already stored.
+// It returns an error if the save operation fails.
+func (p *PullRequestRepositoryImpl) SavePullRequests(ctx context.Context, pulls []domain.PullRequest) error {
+	if len(pulls) == 0 {
+		return nil
+	}
+	if err := p.DB.WithContext(ctx).Clauses(pullRequestUpsert).CreateInBatches(pulls, commitBatchSize).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to save %d pull requests for repository %s: %v", len(pulls), pulls[0].Repository, err))
+		return err
+	}
+	return nil
+}
+
+// ListPullRequests retrieves the pull requests of a repository matching the filter, newest first, with pagination support.
+// It also returns the total number of pull requests matching the filter.
+func (p *PullRequestRepositoryImpl) ListPullRequests(ctx context.Context, owner, repositoryName string, filter domain.PullRequestFilter, page, limit int) ([]domain.PullRequest, int64, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, errors.New("page and limit must be greater than 0")
+	}
+
+	var total int64
+	err := applyPullRequestFilter(p.DB.WithContext(ctx).Model(&domain.PullRequest{}), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count pull requests for %s/%s: %w", owner, repositoryName, err)
+	}
+
+	// The number breaks ties between pull requests opened in the same second, so pages do not overlap
+	var pulls []domain.PullRequest
+	err = applyPullRequestFilter(p.DB.WithContext(ctx), filter).
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Order("created_at DESC, number DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&pulls).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve pull requests for %s/%s: %w", owner, repositoryName, err)
+	}
+	return pulls, total, nil
+}
+
+// applyPullRequestFilter narrows a pull request query down to the pull requests matching the filter
+func applyPullRequestFilter(query *gorm.DB, filter domain.PullRequestFilter) *gorm.DB {
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	if filter.Author != "" {
+		query = query.Where("LOWER(author) = LOWER(?)", filter.Author)
+	}
+	if filter.Base != "" {
+		query = query.Where("base_ref = ?", filter.Base)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at <= ?", filter.Until)
+	}
+	return query
+}
+
+// GetLatestPullRequestUpdate retrieves when the most recently updated pull request of a repository was last updated.
+// It returns the zero time if no pull request is stored.
+func (p *PullRequestRepositoryImpl) GetLatestPullRequestUpdate(ctx context.Context, owner, repositoryName string) (time.Time, error) {
+	var latest scannedTime
+	err := p.DB.WithContext(ctx).Model(&domain.PullRequest{}).
+		Select("MAX(updated_at)").
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Row().Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to retrieve the latest pull request update for %s/%s: %w", owner, repositoryName, err)
+	}
+	return latest.Time, nil
+}
+
+// LinkMergeCommits records on the commits of a repository the pull request that merged them,
+// matching each commit not linked yet to the pull request whose merge commit it is.
+// It returns the number of commits linked.
+func (p *PullRequestRepositoryImpl) LinkMergeCommits(ctx context.Context, owner, repositoryName string) (int64, error) {
+	// Each subquery is built afresh, as GORM renders them lazily and a shared one would take the last Select
+	mergedBy := func(column string) *gorm.DB {
+		return p.DB.Model(&domain.PullRequest{}).
+			Select(column).
+			Where("pull_requests.owner = commits.owner AND pull_requests.repository = commits.repository AND pull_requests.merge_commit_sha = commits.hash").
+			Where("pull_requests.state = ?", domain.PullRequestMerged)
+	}
+
+	result := p.DB.WithContext(ctx).Table("commits").
+		Where("owner = ? AND repository = ? AND (pull_number IS NULL OR pull_number = 0)", owner, repositoryName).
+		Where("EXISTS (?)", mergedBy("1")).
+		Update("pull_number", gorm.Expr("(?)", mergedBy("MIN(pull_requests.number)")))
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to link merge commits for %s/%s: %w", owner, repositoryName, result.Error)
+	}
+	return result.RowsAffected, nil
+}
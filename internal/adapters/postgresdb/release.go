@@ -0,0 +1,138 @@
+package postgresdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReleaseRepositoryImpl implements the PostgresRelease interface using GORM
+type ReleaseRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NewReleaseRepository creates a new instance of ReleaseRepositoryImpl.
+// It returns an error if the provided database connection is nil.
+func NewReleaseRepository(db *gorm.DB) (ports.PostgresRelease, error) {
+	if db == nil {
+		return nil, errors.New("database connection is nil")
+	}
+	return &ReleaseRepositoryImpl{DB: db}, nil
+}
+
+// releaseUpsert makes saving a release that is already stored update it in place.
+// Releases are matched on the unique (owner, repository, tag_name) index.
+var releaseUpsert = clause.OnConflict{
+	Columns: []clause.Column{{Name: "owner"}, {Name: "repository"}, {Name: "tag_name"}},
+	DoUpdates: clause.AssignmentColumns([]string{
+		"name", "commit_sha", "prerelease", "author", "created_at", "published_at", "html_url",
+	}),
+}
+
+// SaveTags saves a batch of tags, moving any already stored to the commit they now point to.
+// It returns an error if the save operation fails.
+func (r *ReleaseRepositoryImpl) SaveTags(ctx context.Context, tags []domain.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	err := r.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "owner"}, {Name: "repository"}, {Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"commit_sha"}),
+		}).
+		CreateInBatches(tags, commitBatchSize).Error
+	if err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to save %d tags for repository %s: %v", len(tags), tags[0].Repository, err))
+		return err
+	}
+	return nil
+}
+
+// SaveReleases saves a batch of releases, updating any that are already stored.
+// It returns an error if the save operation fails.
+func (r *ReleaseRepositoryImpl) SaveReleases(ctx context.Context, releases []domain.Release) error {
+	if len(releases) == 0 {
+		return nil
+	}
+	if err := r.DB.WithContext(ctx).Clauses(releaseUpsert).CreateInBatches(releases, commitBatchSize).Error; err != nil {
+		logger.LogWarning(fmt.Sprintf("Failed to save %d releases for repository %s: %v", len(releases), releases[0].Repository, err))
+		return err
+	}
+	return nil
+}
+
+// GetTagCommits retrieves the commit each stored tag of a repository points to, by tag name
+func (r *ReleaseRepositoryImpl) GetTagCommits(ctx context.Context, owner, repositoryName string) (map[string]string, error) {
+	var tags []domain.Tag
+	if err := r.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve tags for %s/%s: %w", owner, repositoryName, err)
+	}
+	commits := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		commits[tag.Name] = tag.CommitSHA
+	}
+	return commits, nil
+}
+
+// GetReleases retrieves every release of a repository, in no particular order
+func (r *ReleaseRepositoryImpl) GetReleases(ctx context.Context, owner, repositoryName string) ([]domain.Release, error) {
+	var releases []domain.Release
+	if err := r.DB.WithContext(ctx).Where("owner = ? AND repository = ?", owner, repositoryName).Find(&releases).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve releases for %s/%s: %w", owner, repositoryName, err)
+	}
+	return releases, nil
+}
+
+// EachCommitNode calls fn with the hash, parents and release of every commit of a repository, in no particular order.
+// The commits are streamed rather than loaded at once, so large histories can be walked. The parents of commits stored
+// before parents were are NULL, and those commits are marked as having unknown parents.
+func (r *ReleaseRepositoryImpl) EachCommitNode(ctx context.Context, owner, repositoryName string, fn func(node domain.CommitNode) error) error {
+	rows, err := r.DB.WithContext(ctx).Table("commits").
+		Select("hash, parents, COALESCE(release_tag, '')").
+		Where("owner = ? AND repository = ?", owner, repositoryName).
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to read the commit history of %s/%s: %w", owner, repositoryName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var node domain.CommitNode
+		var parents *string
+		if err := rows.Scan(&node.Hash, &parents, &node.ReleaseTag); err != nil {
+			return fmt.Errorf("failed to read the commit history of %s/%s: %w", owner, repositoryName, err)
+		}
+		node.UnknownParents = parents == nil
+		if parents != nil && *parents != "" {
+			if err := json.Unmarshal([]byte(*parents), &node.Parents); err != nil {
+				return fmt.Errorf("invalid parents of commit %s: %w", node.Hash, err)
+			}
+		}
+		if err := fn(node); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SetCommitReleases records the tag of the first release containing each of the given commits of a repository;
+// an empty tag records that they have not been released
+func (r *ReleaseRepositoryImpl) SetCommitReleases(ctx context.Context, owner, repositoryName, tag string, hashes []string) error {
+	for start := 0; start < len(hashes); start += commitBatchSize {
+		chunk := hashes[start:min(start+commitBatchSize, len(hashes))]
+		err := r.DB.WithContext(ctx).Table("commits").
+			Where("owner = ? AND repository = ? AND hash IN ?", owner, repositoryName, chunk).
+			Update("release_tag", tag).Error
+		if err != nil {
+			return fmt.Errorf("failed to record release %q on commits of %s/%s: %w", tag, owner, repositoryName, err)
+		}
+	}
+	return nil
+}
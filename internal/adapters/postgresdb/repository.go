@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"github-service/internal/core/domain"
 	"github-service/internal/ports"
+	"strings"
+	"time"
 
 	"gorm.io/gorm" // Importing the GORM (Object-Relational Mapping) library for database interactions
 )
@@ -27,7 +29,7 @@ func NewRepository(db *gorm.DB) (ports.PostgresRepository, error) {
 // SaveRepository saves a repository to the database, creating a new one if it doesn't exist, or updating an existing one
 func (r *RepositoryImpl) SaveRepository(ctx context.Context, repository *domain.Repository) error {
 	var existingRepo domain.Repository
-	err := r.DB.WithContext(ctx).Where("name = ?", repository.Name).First(&existingRepo).Error
+	err := r.DB.WithContext(ctx).Where("owner = ? AND name = ?", repository.Owner, repository.Name).First(&existingRepo).Error
 	if err != nil {
 		// If the repository does not exist, create a new one
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -39,40 +41,182 @@ func (r *RepositoryImpl) SaveRepository(ctx context.Context, repository *domain.
 	return r.DB.WithContext(ctx).Model(&existingRepo).Updates(repository).Error
 }
 
-// GetTopNCommitAuthors retrieves the top N commit authors, with pagination support
-func (r *RepositoryImpl) GetTopNCommitAuthors(ctx context.Context, repositoryName string, page, limit int) (domain.TopAuthorsCount, error) {
-	var authors domain.TopAuthorsCount
+// authorIdentity is the SQL expression commits are attributed to an author by: the identity they were resolved to,
+// falling back for commits not resolved yet to the author's lowercased GitHub login, or their lowercased email
+// when it is not linked to an account, or their name when neither is known, as domain.CanonicalKey does
+const authorIdentity = "COALESCE(NULLIF(identity, ''), NULLIF(LOWER(author_login), ''), NULLIF(LOWER(email), ''), author)"
 
-	err := r.DB.WithContext(ctx).
+// authorDate is the SQL expression for when a commit was authored, which authors are ranked by rather than when the
+// commit was applied, falling back to the commit date for commits stored before author dates were
+const authorDate = "COALESCE(author_date, commit_date)"
+
+// authorStatsRow is a leaderboard row as scanned from the database
+type authorStatsRow struct {
+	Identity        string
+	Author          string
+	Login           string
+	Email           string
+	Count           int
+	FirstCommit     scannedTime
+	LastCommit      scannedTime
+	ActiveDays      int
+	LinesAdded      int
+	LinesRemoved    int
+	EnrichedCommits int
+}
+
+// GetTopNCommitAuthors retrieves the top N commit authors of commits made between since and until, with pagination support.
+// A zero since or until leaves that end of the window open.
+func (r *RepositoryImpl) GetTopNCommitAuthors(ctx context.Context, owner, repositoryName string, since, until time.Time, page, limit int) (domain.TopAuthorsCount, error) {
+	query := r.DB.WithContext(ctx).
 		Model(&domain.Commit{}).
-		Where("repository = ?", repositoryName).
-		Select("author, count(author) as count").
-		Group("author").
-		Order("count DESC, author ASC"). // Order by count descending, then by author name ascending
+		Where("owner = ? AND repository = ?", owner, repositoryName)
+	if !since.IsZero() {
+		query = query.Where(authorDate+" >= ?", since)
+	}
+	if !until.IsZero() {
+		query = query.Where(authorDate+" <= ?", until)
+	}
+
+	var rows []authorStatsRow
+	err := query.
+		Select(authorIdentity + ` AS identity,
+			MAX(author) AS author,
+			MAX(author_login) AS login,
+			MAX(LOWER(email)) AS email,
+			COUNT(*) AS count,
+			MIN(` + authorDate + `) AS first_commit,
+			MAX(` + authorDate + `) AS last_commit,
+			COUNT(DISTINCT DATE(` + authorDate + `)) AS active_days,
+			COALESCE(SUM(CASE WHEN enriched_at IS NOT NULL THEN additions ELSE 0 END), 0) AS lines_added,
+			COALESCE(SUM(CASE WHEN enriched_at IS NOT NULL THEN deletions ELSE 0 END), 0) AS lines_removed,
+			COUNT(enriched_at) AS enriched_commits`).
+		Group(authorIdentity).
+		Order("count DESC, identity ASC"). // Order by count descending, then by identity ascending
 		Limit(limit).
 		Offset((page - 1) * limit).
-		Scan(&authors).Error
+		Scan(&rows).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return domain.TopAuthorsCount{}, fmt.Errorf("no authors found for repository %s: %w", repositoryName, err)
+			return domain.TopAuthorsCount{}, fmt.Errorf("no authors found for repository %s/%s: %w", owner, repositoryName, err)
 		}
 		return domain.TopAuthorsCount{}, fmt.Errorf("failed to retrieve authors: %w", err)
 	}
-	return authors, nil
+
+	authors := make(domain.TopAuthorsCount, len(rows))
+	for i, row := range rows {
+		authors[i] = domain.AuthorStats{
+			Identity:        row.Identity,
+			Author:          row.Author,
+			Login:           row.Login,
+			Email:           row.Email,
+			Count:           row.Count,
+			FirstCommit:     row.FirstCommit.Time,
+			LastCommit:      row.LastCommit.Time,
+			ActiveDays:      row.ActiveDays,
+			LinesAdded:      row.LinesAdded,
+			LinesRemoved:    row.LinesRemoved,
+			EnrichedCommits: row.EnrichedCommits,
+		}
+	}
+	return authors, r.labelManagedIdentities(ctx, authors)
+}
+
+// labelManagedIdentities replaces the name, login and email seen on the commits of authors resolved to a managed identity
+// with the ones recorded on the identity
+func (r *RepositoryImpl) labelManagedIdentities(ctx context.Context, authors domain.TopAuthorsCount) error {
+	keys := make([]string, len(authors))
+	for i, author := range authors {
+		keys[i] = author.Identity
+	}
+	identities, err := managedIdentities(ctx, r.DB, keys)
+	if err != nil {
+		return err
+	}
+
+	for i, author := range authors {
+		identity, ok := identities[author.Identity]
+		if !ok {
+			continue
+		}
+		if identity.Name != "" {
+			authors[i].Author = identity.Name
+		}
+		if identity.Login != "" {
+			authors[i].Login = identity.Login
+		}
+		if identity.Email != "" {
+			authors[i].Email = strings.ToLower(identity.Email)
+		}
+	}
+	return nil
+}
+
+// topLevelDirectory returns the SQL expression for the top-level directory of a changed file, or "." for files at the root.
+// Postgres and SQLite, which is used in tests, name their string search functions differently.
+func topLevelDirectory(db *gorm.DB) string {
+	if db.Dialector.Name() == "sqlite" {
+		return "CASE WHEN INSTR(commit_files.filename, '/') > 0 THEN SUBSTR(commit_files.filename, 1, INSTR(commit_files.filename, '/') - 1) ELSE '.' END"
+	}
+	return "CASE WHEN STRPOS(commit_files.filename, '/') > 0 THEN SPLIT_PART(commit_files.filename, '/', 1) ELSE '.' END"
+}
+
+// GetDirectoryContributions retrieves how much each author identity changed the files under each top-level directory
+// of a repository, from the changed files of the enriched commits made between since and until.
+// A zero since or until leaves that end of the window open.
+func (r *RepositoryImpl) GetDirectoryContributions(ctx context.Context, owner, repositoryName string, since, until time.Time) ([]domain.DirectoryContribution, error) {
+	directory := topLevelDirectory(r.DB)
+	query := r.DB.WithContext(ctx).
+		Table("commit_files").
+		Joins("JOIN commits ON commits.owner = commit_files.owner AND commits.repository = commit_files.repository AND commits.hash = commit_files.commit_hash").
+		Where("commit_files.owner = ? AND commit_files.repository = ?", owner, repositoryName)
+	if !since.IsZero() {
+		query = query.Where(authorDate+" >= ?", since)
+	}
+	if !until.IsZero() {
+		query = query.Where(authorDate+" <= ?", until)
+	}
+
+	var contributions []domain.DirectoryContribution
+	err := query.
+		Select(directory + " AS directory, " + authorIdentity + ` AS identity,
+			MAX(commits.author) AS author,
+			COALESCE(SUM(commit_files.additions + commit_files.deletions), 0) AS lines,
+			COUNT(*) AS files`).
+		Group(directory + ", " + authorIdentity).
+		Scan(&contributions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve directory contributions for repository %s/%s: %w", owner, repositoryName, err)
+	}
+
+	keys := make([]string, len(contributions))
+	for i, contribution := range contributions {
+		keys[i] = contribution.Identity
+	}
+	identities, err := managedIdentities(ctx, r.DB, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, contribution := range contributions {
+		if identity, ok := identities[contribution.Identity]; ok && identity.Name != "" {
+			contributions[i].Author = identity.Name
+		}
+	}
+	return contributions, nil
 }
 
-// GetRepositoryByName retrieves a repository based on its name
-func (r *RepositoryImpl) GetRepositoryByName(ctx context.Context, repositoryName string) (domain.Repository, error) {
+// GetRepositoryByName retrieves a repository based on its owner and name
+func (r *RepositoryImpl) GetRepositoryByName(ctx context.Context, owner, repositoryName string) (domain.Repository, error) {
 	var repository domain.Repository
-	err := r.DB.WithContext(ctx).Where("name = ?", repositoryName).First(&repository).Error
+	err := r.DB.WithContext(ctx).Where("owner = ? AND name = ?", owner, repositoryName).First(&repository).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return domain.Repository{}, fmt.Errorf("repository %s not found", repositoryName)
+		return domain.Repository{}, fmt.Errorf("repository %s/%s not found", owner, repositoryName)
 	}
 	return repository, err
 }
 
-// DeleteRepository deletes a repository with the given name
+// DeleteRepository deletes a repository with the given owner and name
 func (r *RepositoryImpl) DeleteRepository(ctx context.Context, owner, repositoryName string) (bool, error) {
 	result := r.DB.WithContext(ctx).Where("name = ? AND owner = ?", repositoryName, owner).Delete(&domain.Repository{})
 	if result.Error != nil {
@@ -1,6 +1,9 @@
 package postgresdb
 
 import (
+	"database/sql/driver"
+	"fmt"
+	"github-service/internal/core/domain"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,8 +11,8 @@ import (
 
 type Repository struct {
 	gorm.Model
-	Owner            string    `json:"owner"`
-	Name             string    `json:"name"`
+	Owner            string    `json:"owner" gorm:"uniqueIndex:idx_repositories_owner_name,priority:1"`
+	Name             string    `json:"name" gorm:"uniqueIndex:idx_repositories_owner_name,priority:2"`
 	Description      string    `json:"description"`
 	URL              string    `json:"html_url"`
 	Language         string    `json:"language"`
@@ -18,17 +21,88 @@ type Repository struct {
 	OpenIssuesCount  int       `json:"open_issues_count"`
 	WatchersCount    int       `json:"watchers_count"`
 	SubscribersCount int       `json:"subscribers_count"`
+	DefaultBranch    string    `json:"default_branch"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 type Commit struct {
 	gorm.Model
-	Hash       string    `json:"sha"`
-	Message    string    `json:"message"`
-	Author     string    `json:"author"`
-	CommitDate time.Time `json:"date"`
-	Email      string    `json:"email"`
-	URL        string    `json:"url"`
-	Repository string    `json:"repository"`
+	Owner          string                `json:"owner" gorm:"uniqueIndex:idx_commits_owner_repo_hash,priority:1"`
+	Hash           string                `json:"sha" gorm:"uniqueIndex:idx_commits_owner_repo_hash,priority:3"`
+	Message        string                `json:"message"`
+	Author         string                `json:"author"`
+	Email          string                `json:"email"`
+	AuthorLogin    string                `json:"author_login"`
+	AuthorDate     time.Time             `json:"author_date"`
+	Identity       string                `json:"identity" gorm:"index"`
+	Committer      string                `json:"committer"`
+	CommitterEmail string                `json:"committer_email"`
+	CommitterLogin string                `json:"committer_login"`
+	CommitDate     time.Time             `json:"date"`
+	Parents        []string              `json:"parents" gorm:"serializer:json"`
+	TreeSHA        string                `json:"tree_sha"`
+	CommentCount   int                   `json:"comment_count"`
+	URL            string                `json:"url"`
+	HTMLURL        string                `json:"html_url"`
+	Repository     string                `json:"repository" gorm:"uniqueIndex:idx_commits_owner_repo_hash,priority:2"`
+	CommitType     string                `json:"type" gorm:"index"`
+	Scope          string                `json:"scope"`
+	Breaking       bool                  `json:"breaking"`
+	Subject        string                `json:"subject"`
+	CoAuthors      []domain.CommitPerson `json:"co_authors" gorm:"serializer:json"`
+	SignedOffBy    []domain.CommitPerson `json:"signed_off_by" gorm:"serializer:json"`
+	PullNumber     int                   `json:"pull_number" gorm:"index"`
+	ReleaseTag     string                `json:"release" gorm:"index"`
+	Additions      int                   `json:"additions"`
+	Deletions      int                   `json:"deletions"`
+	Changes        int                   `json:"changes"`
+	EnrichedAt     *time.Time            `json:"enriched_at" gorm:"index"`
+}
+
+// sqliteTimeFormats are the layouts SQLite returns timestamps in when they come out of an aggregate or a date function,
+// which loses the column type the driver would otherwise use to parse them
+var sqliteTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	time.DateOnly,
+}
+
+// scannedTime is a timestamp read from an aggregate such as MIN or MAX.
+// Postgres returns a time.Time, while SQLite returns the stored text.
+type scannedTime struct {
+	time.Time
+}
+
+// Scan implements sql.Scanner
+func (t *scannedTime) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a time", value)
+	}
+
+	for _, layout := range sqliteTimeFormats {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse time %q", text)
+}
+
+// Value implements driver.Valuer
+func (t scannedTime) Value() (driver.Value, error) {
+	return t.Time, nil
 }
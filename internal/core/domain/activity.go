@@ -0,0 +1,111 @@
+package domain
+
+import "time"
+
+// ActivityInterval is the width of the buckets commit activity is counted in
+type ActivityInterval string
+
+const (
+	ActivityDay   ActivityInterval = "day"
+	ActivityWeek  ActivityInterval = "week" // Weeks start on Monday, as ISO 8601 weeks do
+	ActivityMonth ActivityInterval = "month"
+)
+
+// Valid reports whether the interval is one of the supported widths
+func (i ActivityInterval) Valid() bool {
+	return i == ActivityDay || i == ActivityWeek || i == ActivityMonth
+}
+
+// Truncate returns the start, in UTC, of the bucket t falls in
+func (i ActivityInterval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch i {
+	case ActivityWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case ActivityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// Next returns the start of the bucket after the one starting at start
+func (i ActivityInterval) Next(start time.Time) time.Time {
+	switch i {
+	case ActivityWeek:
+		return start.AddDate(0, 0, 7)
+	case ActivityMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// ActivityCount is the number of commits in one bucket, by one author when the counts are broken down by author
+type ActivityCount struct {
+	Bucket   time.Time
+	Identity string
+	Author   string
+	Count    int
+}
+
+// ActivityPoint is the number of commits made in the bucket starting at Start
+type ActivityPoint struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// AuthorActivity is the commit activity of one author
+type AuthorActivity struct {
+	Identity string          `json:"identity"`
+	Author   string          `json:"author"`
+	Total    int             `json:"total"`
+	Buckets  []ActivityPoint `json:"buckets"`
+}
+
+// CommitActivity is a time series of commit counts over consecutive buckets, including the empty ones,
+// optionally broken down by author, most commits first
+type CommitActivity struct {
+	Interval ActivityInterval `json:"interval"`
+	Total    int              `json:"total"`
+	Buckets  []ActivityPoint  `json:"buckets"`
+	Authors  []AuthorActivity `json:"authors,omitempty"`
+}
+
+// Punchcard counts commits by the weekday and hour of day they were made at in a timezone
+type Punchcard struct {
+	Timezone string     `json:"timezone"`
+	Total    int        `json:"total"`
+	Counts   [7][24]int `json:"counts"` // Indexed by weekday, Sunday first, then by hour of day
+}
+
+// CommitTypeOther is the type commits whose message does not follow Conventional Commits are counted under
+const CommitTypeOther = "other"
+
+// CommitTypeCount is the number of commits of one Conventional Commits type in one bucket,
+// and how many of them are breaking changes
+type CommitTypeCount struct {
+	Bucket   time.Time
+	Type     string
+	Count    int
+	Breaking int
+}
+
+// CommitTypeSeries is the number of commits of one type per bucket
+type CommitTypeSeries struct {
+	Type    string          `json:"type"`
+	Total   int             `json:"total"`
+	Share   float64         `json:"share"` // Percentage of all commits in the window
+	Buckets []ActivityPoint `json:"buckets"`
+}
+
+// CommitTypeActivity is the mix of Conventional Commits types over consecutive buckets, including the empty ones,
+// most common type first
+type CommitTypeActivity struct {
+	Interval     ActivityInterval   `json:"interval"`
+	Total        int                `json:"total"`
+	Conventional int                `json:"conventional"` // Commits whose message follows Conventional Commits
+	Breaking     []ActivityPoint    `json:"breaking"`     // Breaking changes per bucket
+	Types        []CommitTypeSeries `json:"types"`
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// BackfillStatus is the state of a historical backfill
+type BackfillStatus string
+
+const (
+	BackfillRunning   BackfillStatus = "running"
+	BackfillCompleted BackfillStatus = "completed"
+)
+
+// Backfill tracks the pull of a repository's commit history from RangeStart to RangeEnd.
+// The range is walked oldest first in slices of SliceDays, and Cursor is checkpointed after every
+// slice is stored, so an interrupted backfill resumes from the last completed slice.
+type Backfill struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Owner         string         `json:"owner" gorm:"uniqueIndex:idx_backfills_owner_repo"`
+	Repository    string         `json:"repository" gorm:"uniqueIndex:idx_backfills_owner_repo"`
+	RangeStart    time.Time      `json:"range_start"`
+	RangeEnd      time.Time      `json:"range_end"`
+	Cursor        time.Time      `json:"cursor"` // Every commit before the cursor has been stored
+	SliceDays     int            `json:"slice_days"`
+	StartPage     int            `json:"start_page"` // Page to resume the first slice from; cleared once it is stored
+	CommitsStored int            `json:"commits_stored"`
+	Status        BackfillStatus `json:"status"`
+	JobID         uint           `json:"job_id"` // The job currently running the backfill
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	FinishedAt    *time.Time     `json:"finished_at,omitempty"`
+}
+
+// Progress returns the percentage of the range covered so far
+func (b Backfill) Progress() float64 {
+	if b.Status == BackfillCompleted {
+		return 100
+	}
+	total := b.RangeEnd.Sub(b.RangeStart)
+	if total <= 0 {
+		return 0
+	}
+	done := b.Cursor.Sub(b.RangeStart)
+	if done <= 0 {
+		return 0
+	}
+	return float64(done) / float64(total) * 100
+}
+
+// BackfillProgress is the response structure for a backfill and how far it has got
+type BackfillProgress struct {
+	Backfill
+	Percent float64 `json:"percent"`
+}
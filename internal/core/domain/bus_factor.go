@@ -0,0 +1,90 @@
+package domain
+
+import "sort"
+
+// BusFactorMetric is what an author's share of a repository is measured in
+type BusFactorMetric string
+
+const (
+	BusFactorCommits BusFactorMetric = "commits"
+	BusFactorLines   BusFactorMetric = "lines" // Lines added plus lines removed, from enriched commits
+	BusFactorFiles   BusFactorMetric = "files" // File changes, for directories where only binary files changed
+)
+
+// DirectoryContribution is how much one author changed the files under one top-level directory
+type DirectoryContribution struct {
+	Directory string // "." for files at the root of the repository
+	Identity  string
+	Author    string
+	Lines     int // Lines added plus lines removed
+	Files     int // Number of file changes, which counts changes to binary files as well
+}
+
+// KeyAuthor is one of the authors a bus factor is made of
+type KeyAuthor struct {
+	Identity string  `json:"identity"`
+	Author   string  `json:"author"`
+	Value    int     `json:"value"` // Commits, lines or file changes, depending on the metric
+	Share    float64 `json:"share"` // Percentage of the total
+}
+
+// BusFactor is the smallest number of authors who together account for the threshold share of a repository,
+// or of one of its top-level directories
+type BusFactor struct {
+	Directory  string          `json:"directory,omitempty"`
+	Metric     BusFactorMetric `json:"metric"`
+	Total      int             `json:"total"`
+	Authors    int             `json:"authors"` // Number of authors who contributed at all
+	BusFactor  int             `json:"bus_factor"`
+	KeyAuthors []KeyAuthor     `json:"key_authors"` // The authors counted in the bus factor, largest share first
+}
+
+// BusFactorReport is the knowledge concentration of a repository as a whole and per top-level directory
+type BusFactorReport struct {
+	Threshold   float64     `json:"threshold"`
+	Repository  BusFactor   `json:"repository"`
+	Directories []BusFactor `json:"directories,omitempty"` // Only when the changed files of commits are stored
+}
+
+// AuthorShare is the amount an author contributed, by which a bus factor is computed
+type AuthorShare struct {
+	Identity string
+	Author   string
+	Value    int
+}
+
+// ComputeBusFactor returns the bus factor of the given contributions for a threshold percentage.
+// Authors are taken largest contribution first until they account for at least threshold percent of the total.
+func ComputeBusFactor(shares []AuthorShare, metric BusFactorMetric, threshold float64) BusFactor {
+	sorted := make([]AuthorShare, 0, len(shares))
+	total := 0
+	for _, share := range shares {
+		if share.Value > 0 {
+			sorted = append(sorted, share)
+			total += share.Value
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Value != sorted[j].Value {
+			return sorted[i].Value > sorted[j].Value
+		}
+		return sorted[i].Identity < sorted[j].Identity
+	})
+
+	factor := BusFactor{Metric: metric, Total: total, Authors: len(sorted), KeyAuthors: []KeyAuthor{}}
+	covered := 0
+	for _, share := range sorted {
+		if total == 0 || float64(covered)*100 >= threshold*float64(total) {
+			break
+		}
+		covered += share.Value
+		factor.KeyAuthors = append(factor.KeyAuthors, KeyAuthor{
+			Identity: share.Identity,
+			Author:   share.Author,
+			Value:    share.Value,
+			Share:    float64(share.Value) * 100 / float64(total),
+		})
+	}
+	factor.BusFactor = len(factor.KeyAuthors)
+	return factor
+}
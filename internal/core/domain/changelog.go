@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChangelogFormat is the format a changelog is rendered in
+type ChangelogFormat string
+
+const (
+	ChangelogJSON     ChangelogFormat = "json"
+	ChangelogMarkdown ChangelogFormat = "markdown"
+)
+
+// ChangelogRef is a bound of a changelog: a tag, a commit SHA or a date
+type ChangelogRef struct {
+	Ref  string     `json:"ref"`
+	SHA  string     `json:"sha,omitempty"`  // The commit a tag or SHA resolved to; empty for a date
+	Date *time.Time `json:"date,omitempty"` // The date a date ref stands for; nil for a tag or SHA
+}
+
+// ChangelogEntry is a commit as it appears in a changelog
+type ChangelogEntry struct {
+	SHA        string   `json:"sha"`
+	Type       string   `json:"type,omitempty"`
+	Scope      string   `json:"scope,omitempty"`
+	Subject    string   `json:"subject"`
+	PullNumber int      `json:"pull_number,omitempty"` // The pull request the change came from; 0 when unknown
+	Authors    []string `json:"authors"`               // The author, as @login when known, followed by the co-authors
+	HTMLURL    string   `json:"html_url"`
+}
+
+// Changelog is the list of changes made to a repository between two refs, grouped by kind
+type Changelog struct {
+	From            *ChangelogRef    `json:"from,omitempty"` // nil when the changelog starts at the first commit
+	To              *ChangelogRef    `json:"to,omitempty"`   // nil when the changelog runs to the latest commit
+	Total           int              `json:"total"`
+	BreakingChanges []ChangelogEntry `json:"breaking_changes"`
+	Features        []ChangelogEntry `json:"features"`
+	Fixes           []ChangelogEntry `json:"fixes"`
+	Other           []ChangelogEntry `json:"other"`
+	Contributors    []string         `json:"contributors"`
+}
+
+// Markdown renders the changelog as a Markdown document, leaving out empty sections
+func (c Changelog) Markdown() string {
+	var b strings.Builder
+	from, to := "the first commit", "the latest commit"
+	if c.From != nil {
+		from = c.From.Ref
+	}
+	if c.To != nil {
+		to = c.To.Ref
+	}
+	fmt.Fprintf(&b, "## Changes from %s to %s\n", from, to)
+	if c.Total == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+
+	sections := []struct {
+		title   string
+		entries []ChangelogEntry
+	}{
+		{"Breaking changes", c.BreakingChanges},
+		{"Features", c.Features},
+		{"Fixes", c.Fixes},
+		{"Other", c.Other},
+	}
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", section.title)
+		for _, entry := range section.entries {
+			b.WriteString("- ")
+			if entry.Scope != "" {
+				fmt.Fprintf(&b, "**%s:** ", entry.Scope)
+			}
+			b.WriteString(entry.Subject)
+			if entry.PullNumber != 0 {
+				fmt.Fprintf(&b, " (#%d)", entry.PullNumber)
+			}
+			fmt.Fprintf(&b, " (%s) by %s\n", shortSHA(entry.SHA), strings.Join(entry.Authors, ", "))
+		}
+	}
+	fmt.Fprintf(&b, "\n### Contributors\n\n%s\n", strings.Join(c.Contributors, ", "))
+	return b.String()
+}
+
+// shortSHA abbreviates a commit SHA the way git does by default
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
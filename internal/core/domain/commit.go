@@ -1,20 +1,160 @@
 package domain
 
-import "time"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
 
+// Commit is a commit of a monitored repository.
+// A commit is identified by its repository owner, repository name and hash.
+// The author wrote the change and the committer applied it, so they differ for
+// rebased, cherry-picked and squash-merged commits.
 type Commit struct {
-	Hash       string    `json:"sha"`
-	Message    string    `json:"message"`
-	Author     string    `json:"author"`
-	CommitDate time.Time `json:"date"`
-	Email      string    `json:"email"`
-	URL        string    `json:"url"`
-	Repository string    `json:"repository"`
+	Owner          string    `json:"owner" gorm:"uniqueIndex:idx_commits_owner_repo_hash,priority:1"`
+	Hash           string    `json:"sha" gorm:"uniqueIndex:idx_commits_owner_repo_hash,priority:3"`
+	Message        string    `json:"message"`
+	Author         string    `json:"author"`
+	Email          string    `json:"email"`
+	AuthorLogin    string    `json:"author_login"` // GitHub login of the author; empty when the email is not linked to an account
+	AuthorDate     time.Time `json:"author_date"`
+	Identity       string    `json:"identity" gorm:"index"` // The resolved author identity; empty until it is resolved
+	Committer      string    `json:"committer"`
+	CommitterEmail string    `json:"committer_email"`
+	CommitterLogin string    `json:"committer_login"`
+	CommitDate     time.Time `json:"date"` // The committer date, which GitHub's since and until filters apply to
+	Parents        []string  `json:"parents" gorm:"serializer:json"`
+	TreeSHA        string    `json:"tree_sha"`
+	CommentCount   int       `json:"comment_count"`
+	URL            string    `json:"url"`
+	HTMLURL        string    `json:"html_url"`
+	Repository     string    `json:"repository" gorm:"uniqueIndex:idx_commits_owner_repo_hash,priority:2"`
+
+	// Conventional Commits parts and trailers, parsed from the message when the commit is saved
+	CommitType  string         `json:"type,omitempty" gorm:"index"` // feat, fix, chore...; empty when the message does not follow the convention
+	Scope       string         `json:"scope,omitempty"`
+	Breaking    bool           `json:"breaking"`
+	Subject     string         `json:"subject"` // The header's description, or the message's first line
+	CoAuthors   []CommitPerson `json:"co_authors,omitempty" gorm:"serializer:json"`
+	SignedOffBy []CommitPerson `json:"signed_off_by,omitempty" gorm:"serializer:json"`
+
+	// The number of the pull request this commit merged, set by the pull request sync; 0 when there is none
+	PullNumber int `json:"pull_number,omitempty" gorm:"index"`
+
+	// The tag of the first release that contains this commit, set by the release sync; empty until it is released
+	ReleaseTag string `json:"release,omitempty" gorm:"index"`
+
+	// Change stats, filled in by enrichment from the single-commit endpoint
+	Additions  int        `json:"additions"`
+	Deletions  int        `json:"deletions"`
+	Changes    int        `json:"changes"`
+	EnrichedAt *time.Time `json:"enriched_at,omitempty" gorm:"index"` // When the stats and files were recorded; nil until then
+}
+
+// CommitPerson is a person named in a commit message trailer
+type CommitPerson struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// CommitFile is a file changed by a commit, recorded by enrichment
+type CommitFile struct {
+	ID               uint   `json:"-" gorm:"primaryKey"`
+	Owner            string `json:"-" gorm:"uniqueIndex:idx_commit_files_commit_filename,priority:1"`
+	Repository       string `json:"-" gorm:"uniqueIndex:idx_commit_files_commit_filename,priority:2"`
+	CommitHash       string `json:"-" gorm:"uniqueIndex:idx_commit_files_commit_filename,priority:3"`
+	Filename         string `json:"filename" gorm:"uniqueIndex:idx_commit_files_commit_filename,priority:4"`
+	PreviousFilename string `json:"previous_filename,omitempty"` // Set when the file was renamed
+	Status           string `json:"status"`                      // added, removed, modified, renamed, copied, changed or unchanged
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Changes          int    `json:"changes"`
+}
+
+// CommitBranch records that a commit is on a branch, that is reachable from its head when the branch was pulled
+type CommitBranch struct {
+	ID         uint   `gorm:"primaryKey"`
+	Owner      string `gorm:"uniqueIndex:idx_commit_branches_commit_branch,priority:1"`
+	Repository string `gorm:"uniqueIndex:idx_commit_branches_commit_branch,priority:2"`
+	CommitHash string `gorm:"uniqueIndex:idx_commit_branches_commit_branch,priority:3"`
+	Branch     string `gorm:"uniqueIndex:idx_commit_branches_commit_branch,priority:4;index"`
+}
+
+// BranchSyncPayload is the payload of a sync_branches job
+type BranchSyncPayload struct {
+	Branches []string `json:"branches"` // Branch names or glob patterns to pull
+}
+
+// CommitDetail is an enriched commit along with the files it changed
+type CommitDetail struct {
+	Commit
+	Files []CommitFile `json:"files"`
+}
+
+// CommitFetchOptions controls which commits are pulled from GitHub and how far pagination goes
+type CommitFetchOptions struct {
+	Since     time.Time // Only commits at or after this time; no lower bound when zero
+	Until     time.Time // Only commits at or before this time; no upper bound when zero
+	StartPage int       // Page to start (or resume) from; the first page when zero
+	MaxPages  int       // Maximum number of pages to walk; the configured cap when zero, unlimited when negative
+	Branch    string    // Branch whose history is pulled and recorded on the commits; the default branch when empty
+}
+
+// CommitSort is the order commits are listed in
+type CommitSort string
+
+const (
+	CommitSortDate     CommitSort = "date"  // Oldest first
+	CommitSortDateDesc CommitSort = "-date" // Newest first
+)
+
+// CommitFilter narrows down the stored commits a query returns. Empty fields do not filter.
+type CommitFilter struct {
+	Author          string     // Author name or GitHub login, case-insensitive
+	Email           string     // Author email, case-insensitive
+	Identity        string     // Key of the identity the author was resolved to
+	Since           time.Time  // Only commits at or after this commit date
+	Until           time.Time  // Only commits at or before this commit date
+	MessageContains string     // Case-insensitive substring of the commit message
+	Release         string     // Tag of the release the commits were first shipped in
+	Branch          string     // Name of a branch the commits are on
+	Sort            CommitSort // Newest first when empty
+}
+
+// CommitCursor marks a position in a listing of commits ordered by (commit date, hash).
+// It is handed to clients as an opaque token.
+type CommitCursor struct {
+	Date     time.Time  `json:"d"`
+	Hash     string     `json:"h"`
+	Sort     CommitSort `json:"s,omitempty"` // The order of the listing the cursor belongs to
+	Backward bool       `json:"b,omitempty"` // Whether the cursor pages towards the start of the listing
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c CommitCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCommitCursor decodes a token returned by CommitCursor.Encode
+func ParseCommitCursor(token string) (CommitCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return CommitCursor{}, ErrInvalidCursor
+	}
+	var cursor CommitCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Hash == "" {
+		return CommitCursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
 }
 
-// PaginatedResponse is the response structure for paginated commit data
+// PaginatedResponse is the response structure for paginated commit data.
+// Page-based listings fill in the page numbers and cursor-based listings the cursors.
 type PaginatedResponse struct {
-	CurrentPage int      `json:"current_page"`
-	TotalPages  int      `json:"total_pages"`
+	CurrentPage int      `json:"current_page,omitempty"`
+	TotalPages  int      `json:"total_pages,omitempty"`
+	NextCursor  string   `json:"next_cursor,omitempty"`
+	PrevCursor  string   `json:"prev_cursor,omitempty"`
 	Commits     []Commit `json:"commits"`
 }
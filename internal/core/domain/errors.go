@@ -0,0 +1,31 @@
+package domain
+
+import "errors"
+
+// ErrNotModified signals that GitHub reported no changes since the last fetch, so there is nothing to sync
+var ErrNotModified = errors.New("not modified since last fetch")
+
+// ErrInvalidCursor signals that a pagination cursor token could not be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrIdentityNotFound signals that there is no identity, or no alias of an identity, with the requested ID
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// ErrInvalidIdentity signals that an identity or alias has nothing commits could be matched against
+var ErrInvalidIdentity = errors.New("an identity needs a name, email or login, and an alias a name or email")
+
+// ErrReleaseNotFound signals that there is no release with the requested tag
+var ErrReleaseNotFound = errors.New("release not found")
+
+// ErrTooManyBuckets signals that a time series was requested over more buckets than a response may hold
+var ErrTooManyBuckets = errors.New("too many buckets; narrow the date range or widen the interval")
+
+// ErrRefNotFound signals that a ref is neither a stored tag or commit SHA nor a date
+var ErrRefNotFound = errors.New("ref not found")
+
+// ErrCommitUnavailable signals that GitHub cannot serve a stored commit, such as one dropped by a force-push
+var ErrCommitUnavailable = errors.New("commit not available from GitHub")
+
+// ErrHistoryIncomplete signals that history had to be walked through commits stored before their parents were,
+// which cannot be followed until the commits are pulled again
+var ErrHistoryIncomplete = errors.New("history incomplete: commits stored before their parents were are being pulled again")
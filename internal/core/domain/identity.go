@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Identity is a person who may author commits under several names, emails and GitHub logins.
+// Commits are attributed to an identity when they match its login or email, or one of its aliases.
+type Identity struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	Key       string          `json:"key" gorm:"uniqueIndex"` // The canonical identity commits are grouped by, derived from the other fields
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	Login     string          `json:"login"`
+	Aliases   []IdentityAlias `json:"aliases" gorm:"foreignKey:IdentityID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// IdentityAlias attributes the commits made with an email, a name, or a name and email pair to an identity,
+// like an entry of a .mailmap file
+type IdentityAlias struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	IdentityID uint   `json:"identity_id" gorm:"index"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+}
+
+// IdentitiesResponse is the response structure for paginated identities
+type IdentitiesResponse struct {
+	CurrentPage int        `json:"current_page"`
+	TotalPages  int        `json:"total_pages"`
+	Identities  []Identity `json:"identities"`
+}
+
+// AuthorTuple is a distinct combination of the author fields found on commits, which resolve to the same identity
+type AuthorTuple struct {
+	Author      string
+	Email       string
+	AuthorLogin string
+	Identity    string // The identity the commits are currently attributed to
+}
+
+// CanonicalKey returns the identity key for a login, email and name: the lowercased login when there is one,
+// otherwise the lowercased email, otherwise the name.
+func CanonicalKey(login, email, name string) string {
+	if login != "" {
+		return strings.ToLower(login)
+	}
+	if email != "" {
+		return strings.ToLower(email)
+	}
+	return name
+}
+
+// noreplyEmail matches the private emails GitHub commits with, e.g. 1234+octocat@users.noreply.github.com
+var noreplyEmail = regexp.MustCompile(`^(?:\d+\+)?([A-Za-z0-9-]+)@users\.noreply\.github\.com$`)
+
+// NoreplyLogin returns the GitHub login a private noreply email belongs to, or "" for any other email
+func NoreplyLogin(email string) string {
+	match := noreplyEmail.FindStringSubmatch(strings.ToLower(email))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// IdentityResolver works out the identity commits are attributed to
+type IdentityResolver struct {
+	byNameEmail map[[2]string]string
+	byEmail     map[string]string
+	byName      map[string]string
+	byLogin     map[string]string
+	emailLogins map[string]string
+}
+
+// NewIdentityResolver creates a resolver from the managed identities and the GitHub logins
+// each lowercased email has been seen committing with
+func NewIdentityResolver(identities []Identity, emailLogins map[string]string) *IdentityResolver {
+	r := &IdentityResolver{
+		byNameEmail: make(map[[2]string]string),
+		byEmail:     make(map[string]string),
+		byName:      make(map[string]string),
+		byLogin:     make(map[string]string),
+		emailLogins: emailLogins,
+	}
+	for _, identity := range identities {
+		if identity.Login != "" {
+			r.byLogin[strings.ToLower(identity.Login)] = identity.Key
+		}
+		if identity.Email != "" {
+			r.byEmail[strings.ToLower(identity.Email)] = identity.Key
+		}
+		for _, alias := range identity.Aliases {
+			email, name := strings.ToLower(alias.Email), strings.ToLower(alias.Name)
+			switch {
+			case email != "" && name != "":
+				r.byNameEmail[[2]string{name, email}] = identity.Key
+			case email != "":
+				r.byEmail[email] = identity.Key
+			case name != "":
+				r.byName[name] = identity.Key
+			}
+		}
+	}
+	return r
+}
+
+// Resolve returns the identity key of commits with the given author name, email and GitHub login.
+// Managed identities and their aliases take precedence; otherwise commits are clustered by GitHub login,
+// including logins recovered from noreply emails or from other commits with the same email.
+func (r *IdentityResolver) Resolve(name, email, login string) string {
+	displayName := name
+	name, email, login = strings.ToLower(name), strings.ToLower(email), strings.ToLower(login)
+
+	if key, ok := r.byNameEmail[[2]string{name, email}]; ok && email != "" {
+		return key
+	}
+	if key, ok := r.byEmail[email]; ok && email != "" {
+		return key
+	}
+	if login == "" {
+		login = NoreplyLogin(email)
+	}
+	if login == "" {
+		login = r.emailLogins[email]
+	}
+	if key, ok := r.byLogin[login]; ok && login != "" {
+		return key
+	}
+	if key, ok := r.byName[name]; ok && name != "" {
+		return key
+	}
+	if login != "" {
+		return login
+	}
+	if email != "" {
+		return email
+	}
+	return displayName
+}
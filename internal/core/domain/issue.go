@@ -0,0 +1,100 @@
+package domain
+
+import "time"
+
+// IssueState is whether an issue is open or closed
+type IssueState string
+
+const (
+	IssueOpen   IssueState = "open"
+	IssueClosed IssueState = "closed"
+)
+
+// Issue is an issue of a monitored repository; pull requests, which GitHub also lists as issues, are left out.
+// An issue is identified by its repository owner, repository name and number.
+type Issue struct {
+	ID          uint       `json:"-" gorm:"primaryKey"`
+	Owner       string     `json:"owner" gorm:"uniqueIndex:idx_issues_owner_repo_number,priority:1"`
+	Repository  string     `json:"repository" gorm:"uniqueIndex:idx_issues_owner_repo_number,priority:2"`
+	Number      int        `json:"number" gorm:"uniqueIndex:idx_issues_owner_repo_number,priority:3"`
+	Title       string     `json:"title"`
+	State       IssueState `json:"state" gorm:"index"`
+	StateReason string     `json:"state_reason,omitempty"` // completed, not_planned or reopened
+	Author      string     `json:"author" gorm:"index"`    // GitHub login of the author
+	Labels      []string   `json:"labels" gorm:"serializer:json"`
+	Assignees   []string   `json:"assignees" gorm:"serializer:json"` // GitHub logins
+	Comments    int        `json:"comments"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime:false;index"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime:false;index"` // When GitHub last saw a change, which incremental syncs resume from
+	ClosedAt    *time.Time `json:"closed_at,omitempty" gorm:"index"`             // The last time the issue was closed
+	TimeToClose *int64     `json:"time_to_close,omitempty"`                      // Seconds from opening to ClosedAt; nil while open
+	HTMLURL     string     `json:"html_url"`
+
+	// The lifecycle of the issue, oldest first; only filled in when a single issue is requested
+	Transitions []IssueTransition `json:"transitions,omitempty" gorm:"-"`
+}
+
+// IssueEvent is a change in the state of an issue
+type IssueEvent string
+
+const (
+	IssueEventOpened   IssueEvent = "opened"
+	IssueEventClosed   IssueEvent = "closed"
+	IssueEventReopened IssueEvent = "reopened"
+)
+
+// IssueTransition records an issue being opened, closed or reopened.
+// Closes and reopens come from GitHub's issue events, identified by their event ID; openings are derived from the issue.
+type IssueTransition struct {
+	ID         uint       `json:"-" gorm:"primaryKey"`
+	Owner      string     `json:"-" gorm:"uniqueIndex:idx_issue_transitions_owner_repo_event,priority:1;index:idx_issue_transitions_issue,priority:1"`
+	Repository string     `json:"-" gorm:"uniqueIndex:idx_issue_transitions_owner_repo_event,priority:2;index:idx_issue_transitions_issue,priority:2"`
+	EventID    int64      `json:"-" gorm:"uniqueIndex:idx_issue_transitions_owner_repo_event,priority:3"`
+	Number     int        `json:"-" gorm:"index:idx_issue_transitions_issue,priority:3"`
+	Event      IssueEvent `json:"event"`
+	Actor      string     `json:"actor,omitempty"` // GitHub login of whoever made the change
+	CreatedAt  time.Time  `json:"at" gorm:"autoCreateTime:false;index"`
+}
+
+// IssueFilter narrows a listing of issues. Zero fields do not filter.
+type IssueFilter struct {
+	State    IssueState
+	Label    string    // Name of a label the issue carries
+	Assignee string    // GitHub login of an assignee, case-insensitive
+	Author   string    // GitHub login of the author, case-insensitive
+	Since    time.Time // Only issues opened at or after this time
+	Until    time.Time // Only issues opened at or before this time
+}
+
+// IssuesResponse is the response structure for paginated issues
+type IssuesResponse struct {
+	CurrentPage int     `json:"current_page"`
+	TotalPages  int     `json:"total_pages"`
+	Issues      []Issue `json:"issues"`
+}
+
+// IssueRateCount is the number of issues opened and closed in one bucket,
+// along with the total seconds the issues closed in it took to close
+type IssueRateCount struct {
+	Bucket      time.Time
+	Opened      int
+	Closed      int
+	TimeToClose int64
+}
+
+// IssueRatePoint is the number of issues opened and closed in the bucket starting at Start
+type IssueRatePoint struct {
+	Start              time.Time `json:"start"`
+	Opened             int       `json:"opened"`
+	Closed             int       `json:"closed"`
+	Open               int       `json:"open"`                  // Issues open at the end of the bucket
+	AverageTimeToClose float64   `json:"average_time_to_close"` // Seconds the issues closed in the bucket took on average
+}
+
+// IssueRates is a time series of the issues opened and closed over consecutive buckets, including the empty ones
+type IssueRates struct {
+	Interval ActivityInterval `json:"interval"`
+	Opened   int              `json:"opened"`
+	Closed   int              `json:"closed"`
+	Buckets  []IssueRatePoint `json:"buckets"`
+}
@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+// JobStatus is the lifecycle state of a background job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobSyncCommits is the type of job that pulls a window of commits from GitHub and saves them
+const JobSyncCommits = "sync_commits"
+
+// JobBackfillCommits is the type of job that walks a repository's history slice by slice, as tracked by its Backfill
+const JobBackfillCommits = "backfill_commits"
+
+// JobEnrichCommits is the type of job that records the change stats and files of a repository's stored commits
+const JobEnrichCommits = "enrich_commits"
+
+// JobSyncPullRequests is the type of job that pulls the pull requests of a repository updated since the last sync,
+// with their reviews, and links their merge commits to them
+const JobSyncPullRequests = "sync_pull_requests"
+
+// JobSyncIssues is the type of job that pulls the issues of a repository updated since the last sync,
+// and the times they were closed and reopened
+const JobSyncIssues = "sync_issues"
+
+// JobSyncReleases is the type of job that pulls the releases and tags of a repository
+// and maps its commits to the first release that contains them
+const JobSyncReleases = "sync_releases"
+
+// JobSyncBranches is the type of job that pulls the commits made on the tracked branches of a repository
+// other than its default branch, recording which branches each commit is on
+const JobSyncBranches = "sync_branches"
+
+// JobBackfillParents is the type of job that pulls the commits of a repository stored before their parents were
+// from GitHub again, so its history can be walked through them
+const JobBackfillParents = "backfill_parents"
+
+// JobParseCommitMessages is the type of job that splits the messages of commits stored before messages were parsed
+// on ingestion into their Conventional Commits parts
+const JobParseCommitMessages = "parse_commit_messages"
+
+// JobResolveIdentities is the type of job that attributes commits to author identities.
+// Jobs with an owner and repository resolve that repository's new commits; jobs without re-resolve every commit.
+const JobResolveIdentities = "resolve_identities"
+
+// Job is a unit of background sync work. Jobs are persisted so their outcome can be inspected
+// and so queued or interrupted work is picked up again after a restart.
+// A repository has at most one queued or running job of each type.
+type Job struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Type        string     `json:"type" gorm:"index:idx_jobs_type_repo;uniqueIndex:idx_jobs_active,priority:1,where:status = 'queued' OR status = 'running'"`
+	Owner       string     `json:"owner" gorm:"index:idx_jobs_type_repo;uniqueIndex:idx_jobs_active,priority:2,where:status = 'queued' OR status = 'running'"`
+	Repository  string     `json:"repository" gorm:"index:idx_jobs_type_repo;uniqueIndex:idx_jobs_active,priority:3,where:status = 'queued' OR status = 'running'"`
+	Payload     string     `json:"payload"` // JSON-encoded parameters for the job type
+	Status      JobStatus  `json:"status" gorm:"index"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	Error       string     `json:"error,omitempty"`
+	RunAfter    time.Time  `json:"run_after"` // A retried job waits until this time before running again
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// JobsResponse is the response structure for a page of jobs
+type JobsResponse struct {
+	CurrentPage int   `json:"current_page"`
+	TotalPages  int   `json:"total_pages"`
+	Jobs        []Job `json:"jobs"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// PullRequestState is where a pull request is in its lifecycle
+type PullRequestState string
+
+const (
+	PullRequestOpen   PullRequestState = "open"
+	PullRequestClosed PullRequestState = "closed" // Closed without being merged
+	PullRequestMerged PullRequestState = "merged"
+)
+
+// PullRequest is a pull request of a monitored repository.
+// A pull request is identified by its repository owner, repository name and number.
+type PullRequest struct {
+	ID             uint             `json:"-" gorm:"primaryKey"`
+	Owner          string           `json:"owner" gorm:"uniqueIndex:idx_pull_requests_owner_repo_number,priority:1"`
+	Repository     string           `json:"repository" gorm:"uniqueIndex:idx_pull_requests_owner_repo_number,priority:2"`
+	Number         int              `json:"number" gorm:"uniqueIndex:idx_pull_requests_owner_repo_number,priority:3"`
+	Title          string           `json:"title"`
+	State          PullRequestState `json:"state" gorm:"index"`
+	Draft          bool             `json:"draft"`
+	Author         string           `json:"author" gorm:"index"` // GitHub login of the author
+	BaseRef        string           `json:"base_ref"`            // The branch the changes are merged into
+	HeadRef        string           `json:"head_ref"`            // The branch the changes come from
+	CreatedAt      time.Time        `json:"created_at" gorm:"autoCreateTime:false;index"`
+	UpdatedAt      time.Time        `json:"updated_at" gorm:"autoUpdateTime:false;index"` // When GitHub last saw a change, which incremental syncs resume from
+	ClosedAt       *time.Time       `json:"closed_at,omitempty"`
+	MergedAt       *time.Time       `json:"merged_at,omitempty"`
+	MergeCommitSHA string           `json:"merge_commit_sha,omitempty" gorm:"index"` // The merge, squash or rebase commit on the base branch
+	Reviews        int              `json:"reviews"`                                 // Submitted reviews, excluding pending ones
+	Approvals      int              `json:"approvals"`
+	FirstReviewAt  *time.Time       `json:"first_review_at,omitempty"` // When the first review was submitted, for review latency
+	HTMLURL        string           `json:"html_url"`
+}
+
+// PullReviewStats summarises the reviews submitted on a pull request
+type PullReviewStats struct {
+	Reviews       int
+	Approvals     int
+	FirstReviewAt *time.Time
+}
+
+// PullRequestFilter narrows a listing of pull requests. Zero fields do not filter.
+type PullRequestFilter struct {
+	State  PullRequestState
+	Author string    // GitHub login of the author, case-insensitive
+	Base   string    // Base branch
+	Since  time.Time // Only pull requests created at or after this time
+	Until  time.Time // Only pull requests created at or before this time
+}
+
+// PullRequestsResponse is the response structure for paginated pull requests
+type PullRequestsResponse struct {
+	CurrentPage  int           `json:"current_page"`
+	TotalPages   int           `json:"total_pages"`
+	PullRequests []PullRequest `json:"pull_requests"`
+}
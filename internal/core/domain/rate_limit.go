@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// RateLimit is the GitHub API quota currently available to the service
+type RateLimit struct {
+	Known        bool       `json:"known"`
+	Limit        int        `json:"limit"`
+	Remaining    int        `json:"remaining"`
+	Reset        time.Time  `json:"reset"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+}
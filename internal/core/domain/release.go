@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Tag is a tag of a monitored repository and the commit it points to
+type Tag struct {
+	ID         uint   `json:"-" gorm:"primaryKey"`
+	Owner      string `json:"-" gorm:"uniqueIndex:idx_tags_owner_repo_name,priority:1"`
+	Repository string `json:"-" gorm:"uniqueIndex:idx_tags_owner_repo_name,priority:2"`
+	Name       string `json:"name" gorm:"uniqueIndex:idx_tags_owner_repo_name,priority:3"`
+	CommitSHA  string `json:"sha" gorm:"index"`
+}
+
+// Release is a published release of a monitored repository.
+// A release is identified by its repository owner, repository name and tag.
+type Release struct {
+	ID          uint       `json:"-" gorm:"primaryKey"`
+	Owner       string     `json:"owner" gorm:"uniqueIndex:idx_releases_owner_repo_tag,priority:1"`
+	Repository  string     `json:"repository" gorm:"uniqueIndex:idx_releases_owner_repo_tag,priority:2"`
+	TagName     string     `json:"tag" gorm:"uniqueIndex:idx_releases_owner_repo_tag,priority:3"`
+	Name        string     `json:"name"`
+	CommitSHA   string     `json:"sha"` // The commit the tag points to; empty until the tag is known
+	Prerelease  bool       `json:"prerelease"`
+	Author      string     `json:"author"` // GitHub login of whoever published the release
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime:false"`
+	PublishedAt *time.Time `json:"published_at,omitempty" gorm:"index"`
+	HTMLURL     string     `json:"html_url"`
+}
+
+// ReleasedAt returns when the release was published, or created if it has no publication time
+func (r Release) ReleasedAt() time.Time {
+	if r.PublishedAt != nil {
+		return *r.PublishedAt
+	}
+	return r.CreatedAt
+}
+
+// CommitNode is a stored commit with its parents and the release it was first shipped in, for walking history
+type CommitNode struct {
+	Hash           string
+	Parents        []string
+	UnknownParents bool // Set for commits stored before their parents were, whose history cannot be followed
+	ReleaseTag     string
+}
+
+// MissingParents describes the stored commits of a repository whose parents are unknown
+// because they were stored before parents were
+type MissingParents struct {
+	Count int64
+	First time.Time // Commit date of the oldest of them
+	Last  time.Time // Commit date of the newest of them
+}
+
+// ReleasesResponse is the response structure for paginated releases
+type ReleasesResponse struct {
+	CurrentPage int       `json:"current_page"`
+	TotalPages  int       `json:"total_pages"`
+	Releases    []Release `json:"releases"`
+}
+
+// ReleaseCommitsResponse is the response structure for the paginated commits first shipped in a release
+type ReleaseCommitsResponse struct {
+	Release     Release  `json:"release"`
+	Previous    *Release `json:"previous,omitempty"` // The release before it; nil for the first release
+	CurrentPage int      `json:"current_page"`
+	TotalPages  int      `json:"total_pages"`
+	Commits     []Commit `json:"commits"`
+}
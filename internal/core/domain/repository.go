@@ -2,9 +2,10 @@ package domain
 
 import "time"
 
+// Repository is the metadata of a monitored repository, identified by its owner and name
 type Repository struct {
-	Owner            string    `json:"owner"`
-	Name             string    `json:"name"`
+	Owner            string    `json:"owner" gorm:"uniqueIndex:idx_repositories_owner_name,priority:1"`
+	Name             string    `json:"name" gorm:"uniqueIndex:idx_repositories_owner_name,priority:2"`
 	Description      string    `json:"description"`
 	URL              string    `json:"html_url"`
 	Language         string    `json:"language"`
@@ -13,15 +14,39 @@ type Repository struct {
 	OpenIssuesCount  int       `json:"open_issues_count"`
 	WatchersCount    int       `json:"watchers_count"`
 	SubscribersCount int       `json:"subscribers_count"`
+	DefaultBranch    string    `json:"default_branch"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
-type TopAuthorsCount []struct {
-	Author string `json:"author"`
-	Count  int    `json:"count"`
+
+// AuthorStats is the contribution of one author to a repository.
+// Commits are attributed to the author's resolved Identity, so an author who commits under several
+// display names, emails or logins is counted once.
+type AuthorStats struct {
+	Identity        string    `json:"identity"` // The key of the identity the commits are grouped by
+	Author          string    `json:"author"`   // The identity's name, or a display name the author committed under
+	Login           string    `json:"login,omitempty"`
+	Email           string    `json:"email,omitempty"`
+	Count           int       `json:"count"`
+	FirstCommit     time.Time `json:"first_commit"`
+	LastCommit      time.Time `json:"last_commit"`
+	ActiveDays      int       `json:"active_days"`      // Distinct days with at least one commit
+	LinesAdded      int       `json:"lines_added"`      // Summed over enriched commits only
+	LinesRemoved    int       `json:"lines_removed"`    // Summed over enriched commits only
+	EnrichedCommits int       `json:"enriched_commits"` // How many of the commits have line stats
 }
 
+// TopAuthorsCount is an author leaderboard, most commits first
+type TopAuthorsCount []AuthorStats
+
+// RepoData is a watchlist entry naming a repository to monitor
 type RepoData struct {
-	Owner    string `json:"owner"`
-	RepoName string `json:"repoName"`
+	Owner    string   `json:"owner"`
+	RepoName string   `json:"repoName"`
+	Branches []string `json:"branches,omitempty"` // Branches tracked besides the default one, by name or glob pattern such as release/*
+}
+
+// FullName returns the owner-qualified repository name, e.g. "chromium/chromium"
+func (r RepoData) FullName() string {
+	return r.Owner + "/" + r.RepoName
 }
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+)
+
+// changelogPageSize is the number of commits read together while building a changelog
+const changelogPageSize = 500
+
+// pullReference matches the pull request number GitHub appends to the subject of squash-merged commits, e.g. "(#123)"
+var pullReference = regexp.MustCompile(`\s*\(#(\d+)\)$`)
+
+// ChangelogService builds changelogs of monitored repositories from their stored commits, tags and pull request links,
+// so it needs no calls to GitHub. Commits stored before their parents were are pulled again by a background job.
+type ChangelogService struct {
+	commits    ports.PostgresCommit
+	releases   ports.PostgresRelease
+	jobService *JobService
+}
+
+// NewChangelogService creates a ChangelogService
+func NewChangelogService(commits ports.PostgresCommit, releases ports.PostgresRelease, jobService *JobService) *ChangelogService {
+	return &ChangelogService{commits: commits, releases: releases, jobService: jobService}
+}
+
+// changelogBound is a resolved changelog ref: a commit for a tag or SHA, or a time for a date
+type changelogBound struct {
+	ref  *domain.ChangelogRef
+	hash string
+	date time.Time
+}
+
+// GetChangelog returns the changes made to a repository after from and up to to, grouped into breaking changes, features,
+// fixes and other changes, oldest first within each group. A tag or SHA bound follows history, so the changelog holds the
+// commits reachable from to but not from from; a date bound holds the commits made on or after from, and on or before to.
+// An empty bound leaves that end open. Merge commits are left out. It returns domain.ErrRefNotFound if a ref cannot be resolved.
+// Commits stored before their parents were can neither be followed nor told apart from merges, so when the changelog
+// reaches one, a job pulling them again is queued and domain.ErrHistoryIncomplete is returned.
+func (s *ChangelogService) GetChangelog(ctx context.Context, owner, repositoryName, from, to string) (domain.Changelog, error) {
+	changelog, err := s.buildChangelog(ctx, owner, repositoryName, from, to)
+	if errors.Is(err, domain.ErrHistoryIncomplete) {
+		queueParentsBackfill(ctx, s.jobService, owner, repositoryName)
+	}
+	return changelog, err
+}
+
+// buildChangelog builds the changelog GetChangelog returns
+func (s *ChangelogService) buildChangelog(ctx context.Context, owner, repositoryName, from, to string) (domain.Changelog, error) {
+	changelog := domain.Changelog{
+		BreakingChanges: []domain.ChangelogEntry{},
+		Features:        []domain.ChangelogEntry{},
+		Fixes:           []domain.ChangelogEntry{},
+		Other:           []domain.ChangelogEntry{},
+		Contributors:    []string{},
+	}
+	lower, err := s.resolveRef(ctx, owner, repositoryName, from, false)
+	if err != nil {
+		return changelog, err
+	}
+	upper, err := s.resolveRef(ctx, owner, repositoryName, to, true)
+	if err != nil {
+		return changelog, err
+	}
+	changelog.From, changelog.To = lower.ref, upper.ref
+
+	// Commit bounds are applied through history, so the commit graph is only read when one is given
+	var included, excluded map[string]bool
+	if lower.hash != "" || upper.hash != "" {
+		nodes := make(map[string]domain.CommitNode)
+		err := s.releases.EachCommitNode(ctx, owner, repositoryName, func(node domain.CommitNode) error {
+			nodes[node.Hash] = node
+			return nil
+		})
+		if err != nil {
+			return changelog, err
+		}
+		// A tag can point to a commit that is not stored, whose history is unknown
+		for _, bound := range []changelogBound{lower, upper} {
+			if _, stored := nodes[bound.hash]; bound.hash != "" && !stored {
+				return changelog, fmt.Errorf("%w: the commit of %s is not stored", domain.ErrRefNotFound, bound.ref.Ref)
+			}
+		}
+		if upper.hash != "" {
+			if included, err = ancestors(nodes, upper.hash); err != nil {
+				return changelog, err
+			}
+		}
+		if lower.hash != "" {
+			if excluded, err = ancestors(nodes, lower.hash); err != nil {
+				return changelog, err
+			}
+		}
+	}
+
+	filter := domain.CommitFilter{Since: lower.date, Until: upper.date, Sort: domain.CommitSortDate}
+	contributors := make(map[string]bool)
+	for page := 1; ; page++ {
+		commits, err := s.commits.GetCommits(ctx, owner, repositoryName, filter, page, changelogPageSize)
+		if err != nil {
+			return changelog, err
+		}
+		for _, commit := range commits {
+			if (included != nil && !included[commit.Hash]) || excluded[commit.Hash] {
+				continue
+			}
+			// The parents of commits stored before parents were are nil, rather than empty
+			if commit.Parents == nil {
+				return changelog, fmt.Errorf("%w: commit %s", domain.ErrHistoryIncomplete, commit.Hash)
+			}
+			if len(commit.Parents) > 1 {
+				continue
+			}
+			entry := changelogEntry(commit)
+			switch {
+			case commit.Breaking:
+				changelog.BreakingChanges = append(changelog.BreakingChanges, entry)
+			case commit.CommitType == "feat":
+				changelog.Features = append(changelog.Features, entry)
+			case commit.CommitType == "fix":
+				changelog.Fixes = append(changelog.Fixes, entry)
+			default:
+				changelog.Other = append(changelog.Other, entry)
+			}
+			changelog.Total++
+			for _, author := range entry.Authors {
+				if !contributors[author] {
+					contributors[author] = true
+					changelog.Contributors = append(changelog.Contributors, author)
+				}
+			}
+		}
+		if len(commits) < changelogPageSize {
+			break
+		}
+	}
+	sort.Slice(changelog.Contributors, func(i, j int) bool {
+		return strings.ToLower(changelog.Contributors[i]) < strings.ToLower(changelog.Contributors[j])
+	})
+	return changelog, nil
+}
+
+// resolveRef resolves a changelog ref, trying it as a tag, then a date, then a commit SHA.
+// A date given without a time stands for the start of that day, or its end when it is the upper bound.
+func (s *ChangelogService) resolveRef(ctx context.Context, owner, repositoryName, ref string, upper bool) (changelogBound, error) {
+	if ref == "" {
+		return changelogBound{}, nil
+	}
+
+	tags, err := s.releases.GetTagCommits(ctx, owner, repositoryName)
+	if err != nil {
+		return changelogBound{}, err
+	}
+	if sha, ok := tags[ref]; ok {
+		return changelogBound{ref: &domain.ChangelogRef{Ref: ref, SHA: sha}, hash: sha}, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, ref); err == nil {
+		return changelogBound{ref: &domain.ChangelogRef{Ref: ref, Date: &date}, date: date}, nil
+	}
+	if date, err := time.Parse(time.DateOnly, ref); err == nil {
+		if upper {
+			date = date.Add(24*time.Hour - time.Nanosecond)
+		}
+		return changelogBound{ref: &domain.ChangelogRef{Ref: ref, Date: &date}, date: date}, nil
+	}
+
+	commit, err := s.commits.GetCommit(ctx, owner, repositoryName, ref)
+	if err != nil {
+		return changelogBound{}, err
+	}
+	if commit == nil {
+		return changelogBound{}, fmt.Errorf("%w: %s", domain.ErrRefNotFound, ref)
+	}
+	return changelogBound{ref: &domain.ChangelogRef{Ref: ref, SHA: commit.Hash}, hash: commit.Hash}, nil
+}
+
+// ancestors returns the stored commits reachable from the commit with the given hash, that commit included.
+// It returns domain.ErrHistoryIncomplete if it reaches a commit whose parents are unknown.
+func ancestors(nodes map[string]domain.CommitNode, hash string) (map[string]bool, error) {
+	reached := make(map[string]bool)
+	stack := []string{hash}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node, stored := nodes[hash]
+		if !stored || reached[hash] {
+			continue
+		}
+		if node.UnknownParents {
+			return nil, fmt.Errorf("%w: commit %s", domain.ErrHistoryIncomplete, hash)
+		}
+		reached[hash] = true
+		stack = append(stack, node.Parents...)
+	}
+	return reached, nil
+}
+
+// changelogEntry turns a commit into a changelog entry, crediting its author and co-authors and taking the pull request
+// it came from from its link, or else from the reference GitHub appends to the subject of squash merges
+func changelogEntry(commit domain.Commit) domain.ChangelogEntry {
+	entry := domain.ChangelogEntry{
+		SHA:        commit.Hash,
+		Type:       commit.CommitType,
+		Scope:      commit.Scope,
+		Subject:    commit.Subject,
+		PullNumber: commit.PullNumber,
+		HTMLURL:    commit.HTMLURL,
+	}
+	if entry.Subject == "" {
+		entry.Subject, _, _ = strings.Cut(commit.Message, "\n")
+	}
+	if match := pullReference.FindStringSubmatch(entry.Subject); match != nil {
+		entry.Subject = strings.TrimSuffix(entry.Subject, match[0])
+		if entry.PullNumber == 0 {
+			entry.PullNumber, _ = strconv.Atoi(match[1])
+		}
+	}
+
+	author := commit.Author
+	if commit.AuthorLogin != "" {
+		author = "@" + commit.AuthorLogin
+	}
+	entry.Authors = []string{author}
+	for _, coAuthor := range commit.CoAuthors {
+		if coAuthor.Name != "" && !strings.EqualFold(coAuthor.Name, commit.Author) {
+			entry.Authors = append(entry.Authors, coAuthor.Name)
+		}
+	}
+	return entry
+}
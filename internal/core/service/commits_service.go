@@ -2,114 +2,498 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github-service/config"
 	"github-service/internal/core/domain"
 	"github-service/internal/ports"
+	"sort"
 	"time"
 
+	"github-service/pkg/conventional"
 	"github-service/pkg/logger"
 )
 
 type CommitServiceImpl interface {
-	SaveCommits(ctx context.Context, owner, repoName string, since time.Time, until ...time.Time) ([]domain.Commit, error)
-	GetPaginatedCommits(ctx context.Context, repositoryName string, page, limit int) ([]domain.Commit, error)
-	GetCommitCount(ctx context.Context, repositoryName string) (int64, error)
-	DeleteCommits(ctx context.Context, repositoryName string) (bool, error)
-	LastCommit(ctx context.Context, repositoryName string) (*domain.Commit, error)
+	SaveCommits(ctx context.Context, owner, repoName string, opts domain.CommitFetchOptions) (int, error)
+	GetPaginatedCommits(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, page, limit int) ([]domain.Commit, error)
+	GetCommitCount(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter) (int64, error)
+	GetCommitsByCursor(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, cursor string, limit int) (domain.PaginatedResponse, error)
+	DeleteCommits(ctx context.Context, owner, repositoryName string) (bool, error)
+	LastCommit(ctx context.Context, owner, repositoryName string) (*domain.Commit, error)
+	LastBranchCommit(ctx context.Context, owner, repositoryName, branch string) (*domain.Commit, error)
+	AssignUnbranchedCommits(ctx context.Context, owner, repositoryName, branch string) (int64, error)
+	GetCommit(ctx context.Context, owner, repositoryName, sha string) (*domain.CommitDetail, error)
+	EnrichCommits(ctx context.Context, owner, repositoryName string) (int, error)
+	GetCommitActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time, byAuthor bool) (domain.CommitActivity, error)
+	GetPunchcard(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, location *time.Location) (domain.Punchcard, error)
+	ParseCommitMessages(ctx context.Context) (int, error)
+	BackfillParents(ctx context.Context, owner, repositoryName, branch string) (domain.MissingParents, error)
+	GetCommitTypeActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) (domain.CommitTypeActivity, error)
 }
 
+// enrichBatchSize is the number of unenriched commits EnrichCommits loads at a time
+const enrichBatchSize = 50
+
+// commitEnrichTimeout bounds how long GetCommit waits on GitHub to enrich a commit being read
+const commitEnrichTimeout = 5 * time.Second
+
+// parseBatchSize is the number of unparsed commits ParseCommitMessages loads at a time
+const parseBatchSize = 500
+
+// maxActivityBuckets caps the length of a commit activity time series, about ten years of days
+const maxActivityBuckets = 3660
+
 // CommitService provides operations for managing commits and config injection
 type CommitService struct {
 	pc            ports.PostgresCommit
+	backfills     ports.PostgresBackfill
 	cfg           *config.Config
 	githubService ports.GithubImpl
 }
 
 // NewCommitService creates a new instance of CommitService
-func NewCommitService(postgresCommitRepository ports.PostgresCommit, cfg *config.Config, githubService ports.GithubImpl) *CommitService {
-	return &CommitService{pc: postgresCommitRepository, cfg: cfg, githubService: githubService}
+func NewCommitService(postgresCommitRepository ports.PostgresCommit, backfills ports.PostgresBackfill, cfg *config.Config, githubService ports.GithubImpl) *CommitService {
+	return &CommitService{pc: postgresCommitRepository, backfills: backfills, cfg: cfg, githubService: githubService}
+}
+
+// SaveCommits walks every page of commits GitHub returns for the opts.Since to opts.Until window and saves
+// each page as it arrives, so a large history is stored incrementally. It returns the number of commits saved.
+// When opts.MaxPages is not set, the configured MAX_PAGES cap applies.
+func (cs *CommitService) SaveCommits(ctx context.Context, owner, repoName string, opts domain.CommitFetchOptions) (int, error) {
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && opts.Until.Before(opts.Since) {
+		return 0, fmt.Errorf("until (%s) must not be before since (%s)", opts.Until.Format(time.RFC3339), opts.Since.Format(time.RFC3339))
+	}
+	if opts.MaxPages == 0 {
+		opts.MaxPages = cs.cfg.MAX_PAGES
+	}
+
+	// Fetch commits from GitHub and save them page by page
+	logger.LogInfo(fmt.Sprintf("Fetching commits for %s/%s from github", owner, repoName))
+	saved := 0
+	err := cs.githubService.FetchCommit(ctx, owner, repoName, opts, func(page int, commits []domain.Commit) error {
+		if err := cs.pc.SaveCommits(ctx, commits); err != nil {
+			return err
+		}
+		// Every commit in a branch's history is on that branch
+		if opts.Branch != "" {
+			hashes := make([]string, len(commits))
+			for i, commit := range commits {
+				hashes[i] = commit.Hash
+			}
+			if err := cs.pc.SaveCommitBranches(ctx, owner, repoName, opts.Branch, hashes); err != nil {
+				return err
+			}
+		}
+		saved += len(commits)
+		return nil
+	})
+	if errors.Is(err, domain.ErrNotModified) {
+		logger.LogInfo(fmt.Sprintf("No new commits for %s/%s", owner, repoName))
+		return 0, nil
+	}
+	if err != nil {
+		return saved, err
+	}
+
+	return saved, nil
+}
+
+// GetPaginatedCommits returns paginated commits matching the filter from the database
+func (cs *CommitService) GetPaginatedCommits(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, page, limit int) ([]domain.Commit, error) {
+	return cs.pc.GetCommits(ctx, owner, repositoryName, filter, page, limit)
+}
+
+// GetCommitsByCursor returns a page of commits matching the filter that starts at an opaque cursor,
+// or at the beginning of the listing when the cursor is empty, along with the cursors of the pages around it.
+// A cursor keeps the order of the listing it came from. It returns domain.ErrInvalidCursor for a malformed cursor.
+func (cs *CommitService) GetCommitsByCursor(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, cursor string, limit int) (domain.PaginatedResponse, error) {
+	var position *domain.CommitCursor
+	if cursor != "" {
+		parsed, err := domain.ParseCommitCursor(cursor)
+		if err != nil {
+			return domain.PaginatedResponse{}, err
+		}
+		position = &parsed
+		filter.Sort = parsed.Sort
+	}
+
+	commits, more, err := cs.pc.GetCommitsByCursor(ctx, owner, repositoryName, filter, position, limit)
+	if err != nil {
+		return domain.PaginatedResponse{}, err
+	}
+
+	response := domain.PaginatedResponse{Commits: commits}
+	if len(commits) == 0 {
+		return response, nil
+	}
+	backward := position != nil && position.Backward
+	first, last := commits[0], commits[len(commits)-1]
+	// There is a next page if more commits were found going forward, or if this page was reached going backward
+	if backward || more {
+		response.NextCursor = domain.CommitCursor{Date: last.CommitDate, Hash: last.Hash, Sort: filter.Sort}.Encode()
+	}
+	// There is a previous page if more commits were found going backward, or if this page was reached going forward
+	if (backward && more) || (!backward && position != nil) {
+		response.PrevCursor = domain.CommitCursor{Date: first.CommitDate, Hash: first.Hash, Sort: filter.Sort, Backward: true}.Encode()
+	}
+	return response, nil
+}
+
+// GetCommitCount returns the total number of commits matching the filter for a repository
+func (cs *CommitService) GetCommitCount(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter) (int64, error) {
+	return cs.pc.GetTotalCommits(ctx, owner, repositoryName, filter)
 }
 
-func (cs *CommitService) SaveCommits(ctx context.Context, owner, repoName string, since time.Time, until ...time.Time) ([]domain.Commit, error) {
+func (cs *CommitService) DeleteCommits(ctx context.Context, owner, repositoryName string) (bool, error) {
+	if owner == "" || repositoryName == "" {
+		return false, fmt.Errorf("Repository owner and name cannot be empty")
+	}
+	ok, err := cs.pc.DeleteAllCommits(ctx, owner, repositoryName)
+	if err != nil {
+		return false, err
+	}
+	// The backfill checkpoint no longer reflects what is stored, so the next pull starts over
+	if err := cs.backfills.DeleteBackfill(ctx, owner, repositoryName); err != nil {
+		return false, err
+	}
+	// Neither do the commit listings GitHub would answer as unchanged, so they are fetched in full again
+	cs.githubService.ForgetCommits(owner, repositoryName)
+	return ok, nil
+}
 
-	// Fetch commits from GitHub
-	commits, err := cs.githubService.FetchCommit(ctx, owner, repoName, since)
-	fmt.Println(commits)
-	logger.LogInfo("fetching commiting from github")
+func (cs *CommitService) LastCommit(ctx context.Context, owner, repositoryName string) (*domain.Commit, error) {
+	if owner == "" || repositoryName == "" {
+		return &domain.Commit{}, fmt.Errorf("Repository owner and name cannot be empty")
+	}
+	commit, err := cs.pc.GetLastCommitByRepositoryName(ctx, owner, repositoryName)
 	if err != nil {
+		return &domain.Commit{}, err
+	}
+	return commit, nil
+}
+
+// LastBranchCommit returns the most recent stored commit of a branch of a repository, or nil if there is none
+func (cs *CommitService) LastBranchCommit(ctx context.Context, owner, repositoryName, branch string) (*domain.Commit, error) {
+	return cs.pc.GetLastBranchCommit(ctx, owner, repositoryName, branch)
+}
+
+// AssignUnbranchedCommits records the stored commits of a repository that are on no branch yet as being on the given one.
+// It returns the number of commits recorded.
+func (cs *CommitService) AssignUnbranchedCommits(ctx context.Context, owner, repositoryName, branch string) (int64, error) {
+	return cs.pc.AssignUnbranchedCommits(ctx, owner, repositoryName, branch)
+}
+
+// GetCommit returns a stored commit with its change stats and files. It returns nil if the commit is not stored.
+// A commit that has not been enriched yet is enriched first, waiting on GitHub for at most commitEnrichTimeout;
+// if that fails, the commit is returned without stats and files, with a nil EnrichedAt.
+func (cs *CommitService) GetCommit(ctx context.Context, owner, repositoryName, sha string) (*domain.CommitDetail, error) {
+	commit, err := cs.pc.GetCommit(ctx, owner, repositoryName, sha)
+	if err != nil || commit == nil {
 		return nil, err
 	}
-	for _, commit := range commits {
-		if err := cs.pc.SaveCommit(ctx, &commit); err != nil {
-			return nil, err
+	if commit.EnrichedAt == nil {
+		enrichCtx, cancel := context.WithTimeout(ctx, commitEnrichTimeout)
+		defer cancel()
+		detail, err := cs.enrichCommit(enrichCtx, owner, repositoryName, sha)
+		if errors.Is(err, domain.ErrCommitUnavailable) {
+			return cs.markUnavailable(ctx, commit)
+		}
+		if err != nil {
+			logger.LogWarning(fmt.Sprintf("Could not enrich commit %s of %s/%s, returning it without stats: %v", sha, owner, repositoryName, err))
+			return &domain.CommitDetail{Commit: *commit}, nil
 		}
+		return detail, nil
 	}
-	// var savedCommits []domain.Commit
 
-	// // Determine if "until" was provided
-	// if len(until) > 0 {
-	// 	// Case: "until" is provided, use both "since" and "until"
-	// 	untilDate := until[0] // Use the first value from the "until" slice
+	files, err := cs.pc.GetCommitFiles(ctx, owner, repositoryName, sha)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CommitDetail{Commit: *commit, Files: files}, nil
+}
 
-	// 	// Loop through the commits and filter based on "since" and "until"
-	// 	for _, commit := range commits {
+// EnrichCommits records the change stats and files of every stored commit of a repository that does not have them yet,
+// newest first. Commits GitHub no longer serves are marked enriched without stats so they do not hold up the older ones.
+// It returns the number of commits enriched.
+func (cs *CommitService) EnrichCommits(ctx context.Context, owner, repositoryName string) (int, error) {
+	enriched := 0
+	for {
+		commits, err := cs.pc.GetUnenrichedCommits(ctx, owner, repositoryName, enrichBatchSize)
+		if err != nil {
+			return enriched, err
+		}
+		if len(commits) == 0 {
+			return enriched, nil
+		}
 
-	// 		// Save commits that are after "since" and before "until"
-	// 		if commit.CommitDate.After(since) && commit.CommitDate.Before(untilDate) {
-	// 			if err := cs.pc.SaveCommit(ctx, &commit); err != nil {
-	// 				return nil, err
-	// 			}
-	// 			savedCommits = append(savedCommits, commit) // Track saved commits
-	// 		}
-	// 	}
-	// } else {
-	// 	// Case: "until" is not provided, only use "since"
-	// 	// Loop through the commits and filter based on "since" only
-	// 	for _, commit := range commits {
+		for _, commit := range commits {
+			_, err := cs.enrichCommit(ctx, owner, repositoryName, commit.Hash)
+			if errors.Is(err, domain.ErrCommitUnavailable) {
+				if _, err := cs.markUnavailable(ctx, &commit); err != nil {
+					return enriched, err
+				}
+				continue
+			}
+			if err != nil {
+				return enriched, err
+			}
+			enriched++
+		}
+		logger.LogInfo(fmt.Sprintf("Enriched %d commits for %s/%s", enriched, owner, repositoryName))
+	}
+}
 
-	// 		// Save commits that are after "since" (no upper bound)
-	// 		if commit.CommitDate.After(since) {
-	// 			if err := cs.pc.SaveCommit(ctx, &commit); err != nil {
-	// 				return nil, err
-	// 			}
-	// 			savedCommits = append(savedCommits, commit) // Track saved commits
-	// 		}
-	// 	}
-	// }
+// enrichCommit fetches a commit from GitHub's single-commit endpoint and records its stats and files
+func (cs *CommitService) enrichCommit(ctx context.Context, owner, repositoryName, sha string) (*domain.CommitDetail, error) {
+	detail, err := cs.githubService.FetchCommitDetail(ctx, owner, repositoryName, sha)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch commit %s: %w", sha, err)
+	}
 
-	return commits, nil
+	now := time.Now()
+	detail.EnrichedAt = &now
+	if err := cs.pc.SaveCommitDetail(ctx, detail); err != nil {
+		return nil, err
+	}
+	return detail, nil
 }
 
-// GetPaginatedCommits returns paginated commits from the database
-func (cs *CommitService) GetPaginatedCommits(ctx context.Context, repositoryName string, page, limit int) ([]domain.Commit, error) {
-	return cs.pc.GetCommits(ctx, repositoryName, page, limit)
+// markUnavailable marks a stored commit that GitHub does not serve as enriched without stats or files, so it is not
+// fetched again, and returns it as such
+func (cs *CommitService) markUnavailable(ctx context.Context, commit *domain.Commit) (*domain.CommitDetail, error) {
+	logger.LogWarning(fmt.Sprintf("Commit %s of %s/%s is not available from GitHub, recording it without stats", commit.Hash, commit.Owner, commit.Repository))
+	now := time.Now()
+	detail := &domain.CommitDetail{Commit: *commit}
+	detail.EnrichedAt = &now
+	if err := cs.pc.SaveCommitDetail(ctx, detail); err != nil {
+		return nil, err
+	}
+	return detail, nil
 }
 
-// GetCommitCount returns the total number of commits for a repository URL
-func (cs *CommitService) GetCommitCount(ctx context.Context, repositoryName string) (int64, error) {
-	return cs.pc.GetTotalCommits(ctx, repositoryName)
+// ParseCommitMessages splits the messages of commits stored before messages were parsed on ingestion
+// into their Conventional Commits parts and trailers. It returns the number of commits parsed.
+func (cs *CommitService) ParseCommitMessages(ctx context.Context) (int, error) {
+	parsed := 0
+	var after *domain.Commit
+	for {
+		commits, err := cs.pc.GetUnparsedCommits(ctx, after, parseBatchSize)
+		if err != nil {
+			return parsed, err
+		}
+		if len(commits) == 0 {
+			return parsed, nil
+		}
+
+		for i := range commits {
+			parseCommitMessage(&commits[i])
+		}
+		if err := cs.pc.SaveCommitMessageParts(ctx, commits); err != nil {
+			return parsed, err
+		}
+		parsed += len(commits)
+		after = &commits[len(commits)-1]
+	}
 }
 
-func (cs *CommitService) DeleteCommits(ctx context.Context, repositoryName string) (bool, error) {
-	if repositoryName == "" {
-		return false, fmt.Errorf("Repository cannot be empty")
+// BackfillParents pulls the commits of a repository stored before their parents were from GitHub again, so they are saved
+// with their parents. The given branch is pulled over the commit dates of those commits, which were all pulled from the
+// default branch; commits it no longer holds, such as ones dropped by a force-push, keep unknown parents.
+// It returns what is left of the commits with unknown parents.
+func (cs *CommitService) BackfillParents(ctx context.Context, owner, repositoryName, branch string) (domain.MissingParents, error) {
+	missing, err := cs.pc.GetMissingParents(ctx, owner, repositoryName)
+	if err != nil || missing.Count == 0 {
+		return missing, err
 	}
-	ok, err := cs.pc.DeleteAllCommits(ctx, repositoryName)
+
+	// The listings of that window were answered before, so they would come back unchanged without the commits
+	cs.githubService.ForgetCommits(owner, repositoryName)
+	opts := domain.CommitFetchOptions{Since: missing.First, Until: missing.Last, MaxPages: -1, Branch: branch}
+	saved, err := cs.SaveCommits(ctx, owner, repositoryName, opts)
 	if err != nil {
-		return false, err
+		return missing, fmt.Errorf("pulling the commits without parents failed after saving %d commits: %w", saved, err)
 	}
-	return ok, nil
+	return cs.pc.GetMissingParents(ctx, owner, repositoryName)
+}
+
+// parseCommitMessage fills in the Conventional Commits parts and trailers of a commit from its message
+func parseCommitMessage(commit *domain.Commit) {
+	message := conventional.Parse(commit.Message)
+	commit.CommitType = message.Type
+	commit.Scope = message.Scope
+	commit.Breaking = message.Breaking
+	commit.Subject = message.Subject
+	commit.CoAuthors = commitPeople(message.CoAuthors)
+	commit.SignedOffBy = commitPeople(message.SignedOffBy)
 }
 
-func (cs *CommitService) LastCommit(ctx context.Context, repositoryName string) (*domain.Commit, error) {
-	if repositoryName == "" {
-		return &domain.Commit{}, fmt.Errorf("Repository cannot be empty")
+// commitPeople converts the people named in trailers
+func commitPeople(people []conventional.Person) []domain.CommitPerson {
+	if len(people) == 0 {
+		return nil
 	}
-	commit, err := cs.pc.GetLastCommitByRepositoryName(ctx, repositoryName)
+	converted := make([]domain.CommitPerson, len(people))
+	for i, person := range people {
+		converted[i] = domain.CommitPerson{Name: person.Name, Email: person.Email}
+	}
+	return converted
+}
+
+// activityBuckets lays out the empty buckets of the interval from the one since falls in to the one until falls in,
+// or the current one when until is zero, along with the index of each bucket by its start
+func activityBuckets(interval domain.ActivityInterval, since, until time.Time) ([]domain.ActivityPoint, map[time.Time]int, error) {
+	if until.IsZero() {
+		until = time.Now()
+	}
+	buckets := []domain.ActivityPoint{}
+	index := make(map[time.Time]int)
+	for start := interval.Truncate(since); !start.After(until); start = interval.Next(start) {
+		if len(buckets) == maxActivityBuckets {
+			return nil, nil, domain.ErrTooManyBuckets
+		}
+		index[start] = len(buckets)
+		buckets = append(buckets, domain.ActivityPoint{Start: start})
+	}
+	return buckets, index, nil
+}
+
+// GetCommitActivity returns the number of commits made between since and until per bucket of the interval,
+// optionally broken down by author. Buckets without commits are included with a zero count, so series can be
+// charted directly. The series runs from the bucket of since, or of the first commit when since is zero,
+// to the bucket of until, or of now when until is zero.
+func (cs *CommitService) GetCommitActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time, byAuthor bool) (domain.CommitActivity, error) {
+	activity := domain.CommitActivity{Interval: interval, Buckets: []domain.ActivityPoint{}}
+	counts, err := cs.pc.GetCommitActivity(ctx, owner, repositoryName, interval, since, until, byAuthor)
 	if err != nil {
-		return &domain.Commit{}, err
+		return activity, err
 	}
-	return commit, nil
+
+	// Lay out every bucket of the window
+	if since.IsZero() && len(counts) == 0 {
+		return activity, nil
+	}
+	if since.IsZero() {
+		since = counts[0].Bucket
+	}
+	buckets, index, err := activityBuckets(interval, since, until)
+	if err != nil {
+		return activity, err
+	}
+	activity.Buckets = buckets
+
+	authors := make(map[string]*domain.AuthorActivity)
+	for _, count := range counts {
+		i, ok := index[count.Bucket]
+		if !ok {
+			continue
+		}
+		activity.Total += count.Count
+		activity.Buckets[i].Count += count.Count
+		if !byAuthor {
+			continue
+		}
+
+		author, ok := authors[count.Identity]
+		if !ok {
+			author = &domain.AuthorActivity{Identity: count.Identity, Author: count.Author, Buckets: make([]domain.ActivityPoint, len(activity.Buckets))}
+			for j, bucket := range activity.Buckets {
+				author.Buckets[j].Start = bucket.Start
+			}
+			authors[count.Identity] = author
+		}
+		author.Total += count.Count
+		author.Buckets[i].Count += count.Count
+	}
+
+	// Most active authors first
+	for _, author := range authors {
+		activity.Authors = append(activity.Authors, *author)
+	}
+	sort.Slice(activity.Authors, func(i, j int) bool {
+		if activity.Authors[i].Total != activity.Authors[j].Total {
+			return activity.Authors[i].Total > activity.Authors[j].Total
+		}
+		return activity.Authors[i].Identity < activity.Authors[j].Identity
+	})
+	return activity, nil
+}
+
+// GetPunchcard counts the commits of a repository that match the filter by the weekday and hour of day they were made at
+// in location. Commits are placed by when they were authored rather than when they were applied, so rebases and
+// merges do not move them; the offset the author recorded is not kept, so the punch card shows when the team works
+// as seen from one timezone.
+func (cs *CommitService) GetPunchcard(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, location *time.Location) (domain.Punchcard, error) {
+	punchcard := domain.Punchcard{Timezone: location.String()}
+	err := cs.pc.EachAuthorDate(ctx, owner, repositoryName, filter, func(date time.Time) error {
+		local := date.In(location)
+		punchcard.Counts[local.Weekday()][local.Hour()]++
+		punchcard.Total++
+		return nil
+	})
+	return punchcard, err
+}
+
+// GetCommitTypeActivity returns the number of commits of each Conventional Commits type made between since and until
+// per bucket of the interval, with empty buckets zero-filled as GetCommitActivity does. Commits whose message does not
+// follow the convention are counted as domain.CommitTypeOther.
+func (cs *CommitService) GetCommitTypeActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) (domain.CommitTypeActivity, error) {
+	activity := domain.CommitTypeActivity{Interval: interval, Breaking: []domain.ActivityPoint{}, Types: []domain.CommitTypeSeries{}}
+	counts, err := cs.pc.GetCommitTypeActivity(ctx, owner, repositoryName, interval, since, until)
+	if err != nil {
+		return activity, err
+	}
+
+	if since.IsZero() && len(counts) == 0 {
+		return activity, nil
+	}
+	if since.IsZero() {
+		since = counts[0].Bucket
+	}
+	buckets, index, err := activityBuckets(interval, since, until)
+	if err != nil {
+		return activity, err
+	}
+	activity.Breaking = buckets
+
+	types := make(map[string]*domain.CommitTypeSeries)
+	for _, count := range counts {
+		i, ok := index[count.Bucket]
+		if !ok {
+			continue
+		}
+		commitType := count.Type
+		if commitType == "" {
+			commitType = domain.CommitTypeOther
+		} else {
+			activity.Conventional += count.Count
+		}
+		activity.Total += count.Count
+		activity.Breaking[i].Count += count.Breaking
+
+		series, ok := types[commitType]
+		if !ok {
+			series = &domain.CommitTypeSeries{Type: commitType, Buckets: make([]domain.ActivityPoint, len(buckets))}
+			for j, bucket := range buckets {
+				series.Buckets[j].Start = bucket.Start
+			}
+			types[commitType] = series
+		}
+		series.Total += count.Count
+		series.Buckets[i].Count += count.Count
+	}
+
+	// Most common types first
+	for _, series := range types {
+		series.Share = float64(series.Total) * 100 / float64(activity.Total)
+		activity.Types = append(activity.Types, *series)
+	}
+	sort.Slice(activity.Types, func(i, j int) bool {
+		if activity.Types[i].Total != activity.Types[j].Total {
+			return activity.Types[i].Total > activity.Types[j].Total
+		}
+		return activity.Types[i].Type < activity.Types[j].Type
+	})
+	return activity, nil
 }
@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github-service/config"
 	"github-service/internal/adapters/github"
 	"github-service/internal/core/domain"
 	"github-service/internal/ports"
+	"github-service/pkg/httpClient"
 
 	"github-service/pkg/logger"
 )
@@ -25,22 +28,206 @@ func NewGithubService(cfg *config.Config, ctx context.Context, client *github.Gi
 	return &githubService{cfg: cfg, ctx: ctx, client: client}
 }
 
-// FetchAndSaveCommits fetches commits from GitHub and saves them to the database
-func (s *githubService) FetchCommit(ctx context.Context, owner, repo string, since time.Time) ([]domain.Commit, error) {
-	commits, err := s.client.FetchRepositoryCommits(ctx, owner, repo, since)
+// FetchCommit fetches commits from GitHub page by page and hands each converted page to handle
+func (s *githubService) FetchCommit(ctx context.Context, owner, repo string, opts domain.CommitFetchOptions, handle func(page int, commits []domain.Commit) error) error {
+	err := s.client.FetchRepositoryCommits(ctx, owner, repo, opts, func(page int, commits []github.Commit) error {
+		return handle(page, convertToDomainCommits(commits, owner, repo))
+	})
+	if errors.Is(err, httpclient.ErrNotModified) {
+		return domain.ErrNotModified
+	}
+	if err != nil {
+		logger.LogError(err)
+		return err
+	}
+	return nil
+}
+
+// FetchCommitDetail fetches a single commit from GitHub with its change stats and files
+func (s *githubService) FetchCommitDetail(ctx context.Context, owner, repo, sha string) (*domain.CommitDetail, error) {
+	apiCommit, err := s.client.FetchCommitDetail(ctx, owner, repo, sha)
+	var statusErr *httpclient.StatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusUnprocessableEntity) {
+		return nil, fmt.Errorf("%w: %w", domain.ErrCommitUnavailable, err)
+	}
+	if err != nil {
+		logger.LogError(err)
+		return nil, err
+	}
+
+	commit := convertToDomainCommits([]github.Commit{apiCommit.Commit}, owner, repo)[0]
+	commit.Additions = apiCommit.Stats.Additions
+	commit.Deletions = apiCommit.Stats.Deletions
+	commit.Changes = apiCommit.Stats.Total
+
+	files := make([]domain.CommitFile, len(apiCommit.Files))
+	for i, file := range apiCommit.Files {
+		files[i] = domain.CommitFile{
+			Owner:            owner,
+			Repository:       repo,
+			CommitHash:       commit.Hash,
+			Filename:         file.Filename,
+			PreviousFilename: file.PreviousFilename,
+			Status:           file.Status,
+			Additions:        file.Additions,
+			Deletions:        file.Deletions,
+			Changes:          file.Changes,
+		}
+	}
+	return &domain.CommitDetail{Commit: commit, Files: files}, nil
+}
+
+// FetchPullRequests fetches the pull requests updated since the given time and hands them to handle converted, least recently updated first
+func (s *githubService) FetchPullRequests(ctx context.Context, owner, repo string, since time.Time, handle func(pulls []domain.PullRequest) error) error {
+	err := s.client.FetchRepositoryPulls(ctx, owner, repo, since, func(pulls []github.PullRequest) error {
+		return handle(convertToDomainPullRequests(pulls, owner, repo))
+	})
+	if errors.Is(err, httpclient.ErrNotModified) {
+		return domain.ErrNotModified
+	}
+	if err != nil {
+		logger.LogError(err)
+		return err
+	}
+	return nil
+}
+
+// FetchPullReviewStats fetches the reviews of a pull request and counts them.
+// Pending reviews are drafts nobody else can see yet, so they are left out.
+func (s *githubService) FetchPullReviewStats(ctx context.Context, owner, repo string, number int) (domain.PullReviewStats, error) {
+	reviews, err := s.client.FetchPullReviews(ctx, owner, repo, number)
+	if err != nil {
+		logger.LogError(err)
+		return domain.PullReviewStats{}, err
+	}
+
+	var stats domain.PullReviewStats
+	for _, review := range reviews {
+		if review.State == "PENDING" {
+			continue
+		}
+		stats.Reviews++
+		if review.State == "APPROVED" {
+			stats.Approvals++
+		}
+		if review.SubmittedAt != nil && (stats.FirstReviewAt == nil || review.SubmittedAt.Before(*stats.FirstReviewAt)) {
+			stats.FirstReviewAt = review.SubmittedAt
+		}
+	}
+	return stats, nil
+}
+
+// FetchIssues fetches the issues updated since the given time and hands them to handle converted, least recently updated first
+func (s *githubService) FetchIssues(ctx context.Context, owner, repo string, since time.Time, handle func(issues []domain.Issue) error) error {
+	err := s.client.FetchRepositoryIssues(ctx, owner, repo, since, func(issues []github.Issue) error {
+		return handle(convertToDomainIssues(issues, owner, repo))
+	})
+	if errors.Is(err, httpclient.ErrNotModified) {
+		return domain.ErrNotModified
+	}
+	if err != nil {
+		logger.LogError(err)
+		return err
+	}
+	return nil
+}
+
+// FetchIssueTransitions fetches the issue events made since the given time and hands the closes and reopens of issues,
+// not pull requests, to handle, oldest first
+func (s *githubService) FetchIssueTransitions(ctx context.Context, owner, repo string, since time.Time, handle func(transitions []domain.IssueTransition) error) error {
+	err := s.client.FetchRepositoryIssueEvents(ctx, owner, repo, since, func(events []github.IssueEvent) error {
+		var transitions []domain.IssueTransition
+		for _, event := range events {
+			kind := domain.IssueEvent(event.Event)
+			if (kind != domain.IssueEventClosed && kind != domain.IssueEventReopened) || event.Issue.PullRequest != nil {
+				continue
+			}
+			transitions = append(transitions, domain.IssueTransition{
+				Owner:      owner,
+				Repository: repo,
+				EventID:    event.ID,
+				Number:     event.Issue.Number,
+				Event:      kind,
+				Actor:      userLogin(event.Actor),
+				CreatedAt:  event.CreatedAt,
+			})
+		}
+		return handle(transitions)
+	})
+	if errors.Is(err, httpclient.ErrNotModified) {
+		return domain.ErrNotModified
+	}
+	if err != nil {
+		logger.LogError(err)
+		return err
+	}
+	return nil
+}
+
+// FetchReleases fetches the releases of a repository from GitHub, leaving out drafts, which have not been released yet
+func (s *githubService) FetchReleases(ctx context.Context, owner, repo string) ([]domain.Release, error) {
+	apiReleases, err := s.client.FetchRepositoryReleases(ctx, owner, repo)
 	if err != nil {
 		logger.LogError(err)
 		return nil, err
 	}
 
-	domainCommits := convertToDomainCommits(commits, repo)
+	releases := make([]domain.Release, 0, len(apiReleases))
+	for _, release := range apiReleases {
+		if release.Draft {
+			continue
+		}
+		releases = append(releases, domain.Release{
+			Owner:       owner,
+			Repository:  repo,
+			TagName:     release.TagName,
+			Name:        release.Name,
+			Prerelease:  release.Prerelease,
+			Author:      userLogin(release.Author),
+			CreatedAt:   release.CreatedAt,
+			PublishedAt: release.PublishedAt,
+			HTMLURL:     release.HTMLURL,
+		})
+	}
+	return releases, nil
+}
+
+// FetchTags fetches the tags of a repository from GitHub with the commit each points to
+func (s *githubService) FetchTags(ctx context.Context, owner, repo string) ([]domain.Tag, error) {
+	apiTags, err := s.client.FetchRepositoryTags(ctx, owner, repo)
+	if err != nil {
+		logger.LogError(err)
+		return nil, err
+	}
 
-	return domainCommits, nil
+	tags := make([]domain.Tag, len(apiTags))
+	for i, tag := range apiTags {
+		tags[i] = domain.Tag{Owner: owner, Repository: repo, Name: tag.Name, CommitSHA: tag.Commit.SHA}
+	}
+	return tags, nil
+}
+
+// FetchBranches fetches the names of the branches of a repository from GitHub
+func (s *githubService) FetchBranches(ctx context.Context, owner, repo string) ([]string, error) {
+	apiBranches, err := s.client.FetchRepositoryBranches(ctx, owner, repo)
+	if err != nil {
+		logger.LogError(err)
+		return nil, err
+	}
+
+	names := make([]string, len(apiBranches))
+	for i, branch := range apiBranches {
+		names[i] = branch.Name
+	}
+	return names, nil
 }
 
 // FetchAndSaveCommits fetches commits from GitHub and saves them to the database
 func (s *githubService) FetchRepository(ctx context.Context, owner, repoName string) (*domain.Repository, error) {
 	apiRepo, err := s.client.FetchRepositoryMetaData(ctx, owner, repoName)
+	if errors.Is(err, httpclient.ErrNotModified) {
+		return nil, domain.ErrNotModified
+	}
 	if err != nil {
 		logger.LogError(err)
 		return &domain.Repository{}, err
@@ -55,6 +242,7 @@ func (s *githubService) FetchRepository(ctx context.Context, owner, repoName str
 		StarsGazersCount: apiRepo.StarsGazersCount,
 		OpenIssuesCount:  apiRepo.OpenIssuesCount,
 		WatchersCount:    apiRepo.WatchersCount,
+		DefaultBranch:    apiRepo.DefaultBranch,
 		CreatedAt:        apiRepo.CreatedAt,
 		UpdatedAt:        apiRepo.UpdatedAt,
 	}
@@ -62,19 +250,143 @@ func (s *githubService) FetchRepository(ctx context.Context, owner, repoName str
 	return repositoryMetadata, nil
 }
 
+// ForgetRepository drops the cached validators of a repository's metadata, so the next fetch returns it in full
+func (s *githubService) ForgetRepository(owner, repoName string) {
+	s.client.ForgetRepositoryMetaData(owner, repoName)
+}
+
+// ForgetCommits drops the cached validators of every commit listing of a repository, so the next fetches return every commit
+func (s *githubService) ForgetCommits(owner, repo string) {
+	s.client.ForgetRepositoryCommits(owner, repo)
+}
+
+// RateLimit returns the GitHub API quota currently available to the service
+func (s *githubService) RateLimit() domain.RateLimit {
+	quota, known, blockedUntil := s.client.RateLimit()
+	rateLimit := domain.RateLimit{
+		Known:     known,
+		Limit:     quota.Limit,
+		Remaining: quota.Remaining,
+		Reset:     quota.Reset,
+	}
+	if !blockedUntil.IsZero() {
+		rateLimit.BlockedUntil = &blockedUntil
+	}
+	return rateLimit
+}
+
 // convertToDomainCommits converts API commits to domain commits.
-func convertToDomainCommits(apiCommits []github.Commit, repo string) []domain.Commit {
+func convertToDomainCommits(apiCommits []github.Commit, owner, repo string) []domain.Commit {
 	domainCommits := make([]domain.Commit, len(apiCommits))
 	for i, commit := range apiCommits {
+		parents := make([]string, len(commit.Parents))
+		for j, parent := range commit.Parents {
+			parents[j] = parent.SHA
+		}
 		domainCommits[i] = domain.Commit{
-			Hash:       commit.SHA,
-			Message:    commit.Commit.Message,
-			Author:     commit.Commit.Committer.Name,
-			Email:      commit.Commit.Committer.Email,
-			CommitDate: commit.Commit.Committer.Date,
-			URL:        commit.Commit.URL,
-			Repository: repo,
+			Owner:          owner,
+			Hash:           commit.SHA,
+			Message:        commit.Commit.Message,
+			Author:         commit.Commit.Author.Name,
+			Email:          commit.Commit.Author.Email,
+			AuthorLogin:    userLogin(commit.Author),
+			AuthorDate:     commit.Commit.Author.Date,
+			Committer:      commit.Commit.Committer.Name,
+			CommitterEmail: commit.Commit.Committer.Email,
+			CommitterLogin: userLogin(commit.Committer),
+			CommitDate:     commit.Commit.Committer.Date,
+			Parents:        parents,
+			TreeSHA:        commit.Commit.Tree.SHA,
+			CommentCount:   commit.Commit.CommentCount,
+			URL:            commit.Commit.URL,
+			HTMLURL:        commit.HTMLURL,
+			Repository:     repo,
 		}
+		parseCommitMessage(&domainCommits[i])
 	}
 	return domainCommits
 }
+
+// convertToDomainPullRequests converts API pull requests to domain pull requests.
+// GitHub reports merged pull requests as closed, so the merged state is derived from the merge time.
+func convertToDomainPullRequests(apiPulls []github.PullRequest, owner, repo string) []domain.PullRequest {
+	pulls := make([]domain.PullRequest, len(apiPulls))
+	for i, pull := range apiPulls {
+		state := domain.PullRequestState(pull.State)
+		if pull.MergedAt != nil {
+			state = domain.PullRequestMerged
+		}
+		pulls[i] = domain.PullRequest{
+			Owner:          owner,
+			Repository:     repo,
+			Number:         pull.Number,
+			Title:          pull.Title,
+			State:          state,
+			Draft:          pull.Draft,
+			Author:         userLogin(pull.User),
+			BaseRef:        pull.Base.Ref,
+			HeadRef:        pull.Head.Ref,
+			CreatedAt:      pull.CreatedAt,
+			UpdatedAt:      pull.UpdatedAt,
+			ClosedAt:       pull.ClosedAt,
+			MergedAt:       pull.MergedAt,
+			MergeCommitSHA: pull.MergeCommitSHA,
+			HTMLURL:        pull.HTMLURL,
+		}
+	}
+	return pulls
+}
+
+// convertToDomainIssues converts API issues to domain issues, leaving out the pull requests GitHub lists among them
+func convertToDomainIssues(apiIssues []github.Issue, owner, repo string) []domain.Issue {
+	issues := make([]domain.Issue, 0, len(apiIssues))
+	for _, issue := range apiIssues {
+		if issue.PullRequest != nil {
+			continue
+		}
+		labels := make([]string, len(issue.Labels))
+		for i, label := range issue.Labels {
+			labels[i] = label.Name
+		}
+		assignees := make([]string, len(issue.Assignees))
+		for i, assignee := range issue.Assignees {
+			assignees[i] = assignee.Login
+		}
+
+		// A reopened issue is open again, whatever closing time GitHub still reports for it
+		closedAt := issue.ClosedAt
+		var timeToClose *int64
+		if domain.IssueState(issue.State) != domain.IssueClosed {
+			closedAt = nil
+		} else if closedAt != nil {
+			seconds := int64(closedAt.Sub(issue.CreatedAt).Seconds())
+			timeToClose = &seconds
+		}
+		issues = append(issues, domain.Issue{
+			Owner:       owner,
+			Repository:  repo,
+			Number:      issue.Number,
+			Title:       issue.Title,
+			State:       domain.IssueState(issue.State),
+			StateReason: issue.StateReason,
+			Author:      userLogin(issue.User),
+			Labels:      labels,
+			Assignees:   assignees,
+			Comments:    issue.Comments,
+			CreatedAt:   issue.CreatedAt,
+			UpdatedAt:   issue.UpdatedAt,
+			ClosedAt:    closedAt,
+			TimeToClose: timeToClose,
+			HTMLURL:     issue.HTMLURL,
+		})
+	}
+	return issues
+}
+
+// userLogin returns the login of a GitHub account, or an empty string when the identity is not linked to one
+func userLogin(user *github.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.Login
+}
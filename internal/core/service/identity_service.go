@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+	"github-service/pkg/mailmap"
+)
+
+// IdentityService manages author identities and attributes commits to them.
+// Any change to the identities queues a job that re-resolves every stored commit,
+// so author aggregations pick the change up without refetching anything.
+type IdentityService struct {
+	identities ports.PostgresIdentity
+	jobService *JobService
+}
+
+// NewIdentityService creates an IdentityService and registers the job that resolves commit identities
+func NewIdentityService(identities ports.PostgresIdentity, jobService *JobService) *IdentityService {
+	s := &IdentityService{identities: identities, jobService: jobService}
+	jobService.RegisterHandler(domain.JobResolveIdentities, s.runResolution)
+	return s
+}
+
+// ListIdentities returns a page of identities ordered by key, along with the total number of identities
+func (s *IdentityService) ListIdentities(ctx context.Context, page, limit int) ([]domain.Identity, int64, error) {
+	return s.identities.ListIdentities(ctx, page, limit)
+}
+
+// GetIdentity returns an identity with its aliases
+func (s *IdentityService) GetIdentity(ctx context.Context, id uint) (*domain.Identity, error) {
+	identity, err := s.identities.GetIdentity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if identity == nil {
+		return nil, domain.ErrIdentityNotFound
+	}
+	return identity, nil
+}
+
+// CreateIdentity stores a new identity along with its aliases
+func (s *IdentityService) CreateIdentity(ctx context.Context, identity *domain.Identity) error {
+	if identity.Name == "" && identity.Email == "" && identity.Login == "" {
+		return domain.ErrInvalidIdentity
+	}
+	for _, alias := range identity.Aliases {
+		if alias.Name == "" && alias.Email == "" {
+			return domain.ErrInvalidIdentity
+		}
+	}
+
+	identity.ID = 0
+	identity.Key = domain.CanonicalKey(identity.Login, identity.Email, identity.Name)
+	for i := range identity.Aliases {
+		identity.Aliases[i].ID = 0
+	}
+	if err := s.identities.CreateIdentity(ctx, identity); err != nil {
+		return err
+	}
+	s.queueResolution(ctx)
+	return nil
+}
+
+// UpdateIdentity replaces the name, email and login of an identity; its aliases are kept
+func (s *IdentityService) UpdateIdentity(ctx context.Context, id uint, update domain.Identity) (*domain.Identity, error) {
+	if update.Name == "" && update.Email == "" && update.Login == "" {
+		return nil, domain.ErrInvalidIdentity
+	}
+	identity, err := s.GetIdentity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	identity.Name, identity.Email, identity.Login = update.Name, update.Email, update.Login
+	identity.Key = domain.CanonicalKey(identity.Login, identity.Email, identity.Name)
+	if err := s.identities.UpdateIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+	s.queueResolution(ctx)
+	return identity, nil
+}
+
+// DeleteIdentity deletes an identity and its aliases; its commits fall back to automatic clustering
+func (s *IdentityService) DeleteIdentity(ctx context.Context, id uint) error {
+	deleted, err := s.identities.DeleteIdentity(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrIdentityNotFound
+	}
+	s.queueResolution(ctx)
+	return nil
+}
+
+// AddAlias attributes the commits made with the alias's name, email, or name and email pair to an identity
+func (s *IdentityService) AddAlias(ctx context.Context, id uint, alias domain.IdentityAlias) (*domain.IdentityAlias, error) {
+	if alias.Name == "" && alias.Email == "" {
+		return nil, domain.ErrInvalidIdentity
+	}
+	if _, err := s.GetIdentity(ctx, id); err != nil {
+		return nil, err
+	}
+
+	alias.ID = 0
+	alias.IdentityID = id
+	if err := s.identities.AddAlias(ctx, &alias); err != nil {
+		return nil, err
+	}
+	s.queueResolution(ctx)
+	return &alias, nil
+}
+
+// DeleteAlias removes an alias from an identity
+func (s *IdentityService) DeleteAlias(ctx context.Context, id, aliasID uint) error {
+	deleted, err := s.identities.DeleteAlias(ctx, id, aliasID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrIdentityNotFound
+	}
+	s.queueResolution(ctx)
+	return nil
+}
+
+// ImportMailmap merges the entries of a .mailmap file into the identities.
+// Each entry's proper email, or its commit email when it has none, picks the identity, which is created if no identity
+// has that email yet and named after the entry's proper name; the commit email, or commit name and email pair,
+// becomes an alias of it. It returns the number of entries imported.
+func (s *IdentityService) ImportMailmap(ctx context.Context, content io.Reader) (int, error) {
+	entries, err := mailmap.Parse(content)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mailmap: %w", err)
+	}
+
+	imported := 0
+	defer func() {
+		if imported > 0 {
+			s.queueResolution(ctx)
+		}
+	}()
+	for _, entry := range entries {
+		if err := s.importMailmapEntry(ctx, entry); err != nil {
+			return imported, fmt.Errorf("imported %d mailmap entries before failing: %w", imported, err)
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// importMailmapEntry merges one .mailmap entry into the identities
+func (s *IdentityService) importMailmapEntry(ctx context.Context, entry mailmap.Entry) error {
+	email := entry.ProperEmail
+	if email == "" {
+		email = entry.CommitEmail
+	}
+
+	identity, err := s.identities.FindIdentityByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		identity = &domain.Identity{Name: entry.ProperName, Email: email, Key: domain.CanonicalKey("", email, entry.ProperName)}
+		if err := s.identities.CreateIdentity(ctx, identity); err != nil {
+			return err
+		}
+	} else if entry.ProperName != "" && entry.ProperName != identity.Name {
+		identity.Name = entry.ProperName
+		if err := s.identities.UpdateIdentity(ctx, identity); err != nil {
+			return err
+		}
+	}
+
+	// The identity's own email already matches commits made with it, unless only some names may use it
+	if entry.CommitName == "" && strings.EqualFold(entry.CommitEmail, identity.Email) {
+		return nil
+	}
+	for _, alias := range identity.Aliases {
+		if strings.EqualFold(alias.Name, entry.CommitName) && strings.EqualFold(alias.Email, entry.CommitEmail) {
+			return nil
+		}
+	}
+	return s.identities.AddAlias(ctx, &domain.IdentityAlias{IdentityID: identity.ID, Name: entry.CommitName, Email: entry.CommitEmail})
+}
+
+// ResolveIdentities attributes commits to identities: the new commits of a repository,
+// or every stored commit when owner is empty. It returns the number of commits whose identity changed.
+func (s *IdentityService) ResolveIdentities(ctx context.Context, owner, repositoryName string) (int64, error) {
+	identities, err := s.identities.GetAllIdentities(ctx)
+	if err != nil {
+		return 0, err
+	}
+	emailLogins, err := s.identities.GetEmailLogins(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resolver := domain.NewIdentityResolver(identities, emailLogins)
+
+	tuples, err := s.identities.GetAuthorTuples(ctx, owner, repositoryName, owner != "")
+	if err != nil {
+		return 0, err
+	}
+
+	var updated int64
+	for _, tuple := range tuples {
+		identity := resolver.Resolve(tuple.Author, tuple.Email, tuple.AuthorLogin)
+		if identity == tuple.Identity {
+			continue
+		}
+		n, err := s.identities.SetIdentity(ctx, owner, repositoryName, tuple, identity)
+		if err != nil {
+			return updated, err
+		}
+		updated += n
+	}
+	return updated, nil
+}
+
+// queueResolution queues a job that re-resolves every commit after the identities changed.
+// Failing to queue it is only logged: the change is saved, and the next change queues the job again.
+func (s *IdentityService) queueResolution(ctx context.Context) {
+	if _, err := s.jobService.Enqueue(ctx, domain.JobResolveIdentities, "", "", nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue identity resolution: %w", err))
+	}
+}
+
+// runResolution runs a resolve_identities job
+func (s *IdentityService) runResolution(ctx context.Context, job *domain.Job) error {
+	updated, err := s.ResolveIdentities(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return fmt.Errorf("resolved %d commits before failing: %w", updated, err)
+	}
+	if job.Owner == "" {
+		logger.LogInfo(fmt.Sprintf("Re-resolved the identity of %d commits", updated))
+	} else {
+		logger.LogInfo(fmt.Sprintf("Resolved the identity of %d commits for %s/%s", updated, job.Owner, job.Repository))
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github-service/config"
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+)
+
+// issueSaveBatch is the number of issues saved together during a sync
+const issueSaveBatch = 100
+
+// IssueService syncs the issues of monitored repositories along with the times they were closed and reopened,
+// and reports how fast they are opened and closed
+type IssueService struct {
+	issues        ports.PostgresIssue
+	githubService ports.GithubImpl
+	jobService    *JobService
+	syncIssues    bool
+}
+
+// NewIssueService creates an IssueService and registers the job that syncs issues
+func NewIssueService(issues ports.PostgresIssue, githubService ports.GithubImpl, jobService *JobService, cfg *config.Config) *IssueService {
+	s := &IssueService{issues: issues, githubService: githubService, jobService: jobService, syncIssues: cfg.SYNC_ISSUES}
+	jobService.RegisterHandler(domain.JobSyncIssues, s.runIssueSync)
+	return s
+}
+
+// QueueIssueSync queues a job that syncs the issues of a repository, when enabled.
+// Failing to queue it is only logged: the next scheduled run queues it again.
+func (s *IssueService) QueueIssueSync(ctx context.Context, rData domain.RepoData) {
+	if !s.syncIssues {
+		return
+	}
+	if _, err := s.jobService.Enqueue(ctx, domain.JobSyncIssues, rData.Owner, rData.RepoName, nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue issue sync for %s: %w", rData.FullName(), err))
+	}
+}
+
+// SyncIssues saves the issues of a repository updated since the last sync, then the closes and reopens made since the
+// last one recorded. The first sync fetches every issue and event. It returns the number of issues saved.
+func (s *IssueService) SyncIssues(ctx context.Context, owner, repositoryName string) (int, error) {
+	// Issues updated in the same second as the latest one are fetched again, which is harmless as saves are idempotent
+	since, err := s.issues.GetLatestIssueUpdate(ctx, owner, repositoryName)
+	if err != nil {
+		return 0, err
+	}
+
+	// The issues arrive least recently updated first and are saved in that order, so whatever is stored
+	// before a failure is a prefix of them and the next sync resumes right after it
+	saved := 0
+	err = s.githubService.FetchIssues(ctx, owner, repositoryName, since, func(issues []domain.Issue) error {
+		for start := 0; start < len(issues); start += issueSaveBatch {
+			batch := issues[start:min(start+issueSaveBatch, len(issues))]
+			if err := s.issues.SaveIssues(ctx, batch); err != nil {
+				return err
+			}
+			saved += len(batch)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, domain.ErrNotModified) {
+		return saved, err
+	}
+
+	// Transitions are keyed by event, so those made in the same second as the latest one are skipped when fetched again
+	since, err = s.issues.GetLatestIssueTransition(ctx, owner, repositoryName)
+	if err != nil {
+		return saved, err
+	}
+	err = s.githubService.FetchIssueTransitions(ctx, owner, repositoryName, since, func(transitions []domain.IssueTransition) error {
+		return s.issues.SaveIssueTransitions(ctx, transitions)
+	})
+	if err != nil && !errors.Is(err, domain.ErrNotModified) {
+		return saved, err
+	}
+	return saved, nil
+}
+
+// GetIssues returns a page of the issues of a repository matching the filter, newest first,
+// along with the total number of issues matching it
+func (s *IssueService) GetIssues(ctx context.Context, owner, repositoryName string, filter domain.IssueFilter, page, limit int) ([]domain.Issue, int64, error) {
+	return s.issues.ListIssues(ctx, owner, repositoryName, filter, page, limit)
+}
+
+// GetIssue returns an issue with its lifecycle: its opening followed by every close and reopen recorded for it.
+// It returns nil if the issue is not stored.
+func (s *IssueService) GetIssue(ctx context.Context, owner, repositoryName string, number int) (*domain.Issue, error) {
+	issue, err := s.issues.GetIssue(ctx, owner, repositoryName, number)
+	if err != nil || issue == nil {
+		return issue, err
+	}
+	opened := domain.IssueTransition{Event: domain.IssueEventOpened, Actor: issue.Author, CreatedAt: issue.CreatedAt}
+	issue.Transitions = append([]domain.IssueTransition{opened}, issue.Transitions...)
+	return issue, nil
+}
+
+// GetIssueRates returns the number of issues opened and closed between since and until per bucket of the interval,
+// how many were open at the end of each bucket and how long the issues closed in it took on average. Buckets without
+// activity are included with zero counts. The series runs from the bucket of since, or of the first issue when since is
+// zero, to the bucket of until, or of now when until is zero.
+func (s *IssueService) GetIssueRates(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) (domain.IssueRates, error) {
+	rates := domain.IssueRates{Interval: interval, Buckets: []domain.IssueRatePoint{}}
+	counts, err := s.issues.GetIssueRates(ctx, owner, repositoryName, interval, since, until)
+	if err != nil {
+		return rates, err
+	}
+
+	// Lay out every bucket of the window
+	if since.IsZero() && len(counts) == 0 {
+		return rates, nil
+	}
+	start := since
+	if start.IsZero() {
+		start = counts[0].Bucket
+	}
+	points, index, err := activityBuckets(interval, start, until)
+	if err != nil {
+		return rates, err
+	}
+	rates.Buckets = make([]domain.IssueRatePoint, len(points))
+	for i, point := range points {
+		rates.Buckets[i].Start = point.Start
+	}
+
+	// Issues opened before the window and not closed by its start make up the backlog it begins with
+	var opened, closed int64
+	if !since.IsZero() {
+		if opened, closed, err = s.issues.CountIssuesBefore(ctx, owner, repositoryName, since); err != nil {
+			return rates, err
+		}
+	}
+
+	timeToClose := make([]int64, len(points))
+	for _, count := range counts {
+		i, ok := index[count.Bucket]
+		if !ok {
+			continue
+		}
+		rates.Buckets[i].Opened += count.Opened
+		rates.Buckets[i].Closed += count.Closed
+		timeToClose[i] += count.TimeToClose
+	}
+
+	open := int(opened - closed)
+	for i := range rates.Buckets {
+		bucket := &rates.Buckets[i]
+		open += bucket.Opened - bucket.Closed
+		bucket.Open = open
+		if bucket.Closed > 0 {
+			bucket.AverageTimeToClose = float64(timeToClose[i]) / float64(bucket.Closed)
+		}
+		rates.Opened += bucket.Opened
+		rates.Closed += bucket.Closed
+	}
+	return rates, nil
+}
+
+// runIssueSync runs a sync_issues job.
+// Issues saved before a failure are kept, and a retry resumes from the most recently updated of them.
+func (s *IssueService) runIssueSync(ctx context.Context, job *domain.Job) error {
+	saved, err := s.SyncIssues(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return fmt.Errorf("saved %d issues before failing: %w", saved, err)
+	}
+	logger.LogInfo(fmt.Sprintf("Saved %d issues for %s/%s", saved, job.Owner, job.Repository))
+	return nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+	"github-service/pkg/utils"
+)
+
+// jobPollInterval is how often the dispatcher looks for due jobs when it has not been woken up
+const jobPollInterval = 5 * time.Second
+
+// JobHandler runs a job of one type; returning an error fails the attempt
+type JobHandler func(ctx context.Context, job *domain.Job) error
+
+// JobService runs background jobs on a bounded pool of workers.
+// Jobs are persisted before they run, failed attempts are retried with exponential backoff
+// until MaxAttempts is reached, and jobs interrupted by a shutdown run again after a restart.
+type JobService struct {
+	jobs         ports.PostgresJob
+	workers      int
+	maxAttempts  int
+	retryBackoff time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	wake     chan struct{}
+}
+
+// NewJobService creates a JobService with the given number of workers and attempts per job
+func NewJobService(jobs ports.PostgresJob, workers, maxAttempts int, retryBackoff time.Duration) *JobService {
+	return &JobService{
+		jobs:         jobs,
+		workers:      workers,
+		maxAttempts:  maxAttempts,
+		retryBackoff: retryBackoff,
+		handlers:     make(map[string]JobHandler),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// RegisterHandler sets the handler that runs jobs of jobType
+func (js *JobService) RegisterHandler(jobType string, handler JobHandler) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.handlers[jobType] = handler
+}
+
+// Enqueue persists a new job for a repository with the JSON-encoded payload and queues it.
+// If a job of the same type for the repository is already queued or running, that job is returned instead,
+// including when a concurrent call queues it first.
+func (js *JobService) Enqueue(ctx context.Context, jobType, owner, repositoryName string, payload interface{}) (*domain.Job, error) {
+	active, err := js.jobs.FindActiveJob(ctx, jobType, owner, repositoryName)
+	if err != nil {
+		return nil, fmt.Errorf("could not check for active jobs: %w", err)
+	}
+	if active != nil {
+		logger.LogInfo(fmt.Sprintf("Job %s for %s/%s already %s as job %d", jobType, owner, repositoryName, active.Status, active.ID))
+		return active, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode job payload: %w", err)
+	}
+
+	job := &domain.Job{
+		Type:        jobType,
+		Owner:       owner,
+		Repository:  repositoryName,
+		Payload:     string(data),
+		Status:      domain.JobQueued,
+		MaxAttempts: js.maxAttempts,
+		RunAfter:    time.Now(),
+	}
+	if err := js.jobs.CreateJob(ctx, job); err != nil {
+		// A repository can only have one active job of each type, so another caller may have queued it first
+		if active, findErr := js.jobs.FindActiveJob(ctx, jobType, owner, repositoryName); findErr == nil && active != nil {
+			logger.LogInfo(fmt.Sprintf("Job %s for %s/%s already %s as job %d", jobType, owner, repositoryName, active.Status, active.ID))
+			return active, nil
+		}
+		return nil, fmt.Errorf("could not save job: %w", err)
+	}
+
+	logger.LogInfo(fmt.Sprintf("Queued job %d: %s for %s/%s", job.ID, jobType, owner, repositoryName))
+	js.notify()
+	return job, nil
+}
+
+// GetJob returns a job by its ID
+func (js *JobService) GetJob(ctx context.Context, id uint) (domain.Job, error) {
+	return js.jobs.GetJob(ctx, id)
+}
+
+// ListJobs returns a page of jobs, newest first, optionally only those in the given status,
+// along with the total number of matching jobs
+func (js *JobService) ListJobs(ctx context.Context, status domain.JobStatus, page, limit int) ([]domain.Job, int64, error) {
+	return js.jobs.ListJobs(ctx, status, page, limit)
+}
+
+// Start requeues jobs interrupted by the last shutdown and starts the dispatcher and workers.
+// They stop when ctx is cancelled.
+func (js *JobService) Start(ctx context.Context) {
+	if n, err := js.jobs.RequeueRunningJobs(ctx); err != nil {
+		logger.LogError(fmt.Errorf("could not requeue interrupted jobs: %w", err))
+	} else if n > 0 {
+		logger.LogInfo(fmt.Sprintf("Requeued %d interrupted jobs", n))
+	}
+
+	work := make(chan domain.Job)
+	for i := 0; i < js.workers; i++ {
+		go js.worker(ctx, work)
+	}
+	go js.dispatch(ctx, work)
+}
+
+// notify wakes the dispatcher without blocking
+func (js *JobService) notify() {
+	select {
+	case js.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch claims due jobs and hands them to the workers.
+// Sending blocks until a worker is free, so no more jobs are claimed than can run.
+func (js *JobService) dispatch(ctx context.Context, work chan<- domain.Job) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := js.jobs.ClaimDueJobs(ctx, time.Now(), js.workers)
+		if err != nil {
+			logger.LogError(err)
+		}
+		for _, job := range jobs {
+			select {
+			case work <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// Look again straight away while there may be more due jobs
+		if len(jobs) == js.workers {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-js.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// worker runs jobs until ctx is cancelled
+func (js *JobService) worker(ctx context.Context, work <-chan domain.Job) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-work:
+			js.run(ctx, &job)
+		}
+	}
+}
+
+// run performs one attempt of a job and records the outcome
+func (js *JobService) run(ctx context.Context, job *domain.Job) {
+	js.mu.RLock()
+	handler, ok := js.handlers[job.Type]
+	js.mu.RUnlock()
+
+	startedAt := time.Now()
+	job.Attempts++
+	job.StartedAt = &startedAt
+	job.FinishedAt = nil
+	job.Status = domain.JobRunning
+	if err := js.jobs.UpdateJob(ctx, job); err != nil {
+		logger.LogError(fmt.Errorf("could not mark job %d running: %w", job.ID, err))
+	}
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		err = handler(ctx, job)
+	}
+
+	// The service is shutting down; leave the job running so it is requeued on the next start
+	if ctx.Err() != nil {
+		return
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	switch {
+	case err == nil:
+		job.Status = domain.JobSucceeded
+		job.Error = ""
+		logger.LogInfo(fmt.Sprintf("Job %d (%s for %s/%s) succeeded", job.ID, job.Type, job.Owner, job.Repository))
+	case ok && job.Attempts < job.MaxAttempts:
+		job.Status = domain.JobQueued
+		job.Error = err.Error()
+		delay := utils.ExponentialBackoff(job.Attempts, js.retryBackoff)
+		job.RunAfter = finishedAt.Add(delay)
+		time.AfterFunc(delay, js.notify)
+		logger.LogWarning(fmt.Sprintf("Job %d attempt %d/%d failed, retrying at %s: %v", job.ID, job.Attempts, job.MaxAttempts, job.RunAfter.Format(time.RFC3339), err))
+	default:
+		job.Status = domain.JobFailed
+		job.Error = err.Error()
+		logger.LogError(fmt.Errorf("job %d (%s for %s/%s) failed after %d attempts: %w", job.ID, job.Type, job.Owner, job.Repository, job.Attempts, err))
+	}
+
+	if err := js.jobs.UpdateJob(ctx, job); err != nil {
+		logger.LogError(fmt.Errorf("could not record outcome of job %d: %w", job.ID, err))
+	}
+}
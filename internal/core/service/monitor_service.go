@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github-service/config"
 	"github-service/internal/core/domain"
 	"github-service/internal/ports"
 	"github-service/pkg/logger"
 	"github-service/pkg/utils"
-	"log"
+	"path"
 	"time"
 )
 
@@ -15,18 +18,42 @@ type MonitorService struct {
 	commitService       CommitServiceImpl
 	repositoryService   RepositoryServiceImpl
 	githubService       ports.GithubImpl
+	jobService          *JobService
+	backfills           ports.PostgresBackfill
+	backfillSliceDays   int
+	enrichCommits       bool
+	syncPullRequests    bool
+	syncReleases        bool
 	maxRetryAttempts    int
 	initialRetryBackoff time.Duration
 }
 
-func NewMonitorService(commitService CommitServiceImpl, repositoryService RepositoryServiceImpl, maxRetryAttempts int, initialRetryBackoff time.Duration, githubService ports.GithubImpl) *MonitorService {
-	return &MonitorService{
+func NewMonitorService(commitService CommitServiceImpl, repositoryService RepositoryServiceImpl, maxRetryAttempts int, initialRetryBackoff time.Duration, githubService ports.GithubImpl, jobService *JobService, backfills ports.PostgresBackfill, cfg *config.Config) *MonitorService {
+	// Default to monthly slices
+	backfillSliceDays := cfg.BACKFILL_SLICE_DAYS
+	if backfillSliceDays <= 0 {
+		backfillSliceDays = 30
+	}
+	m := &MonitorService{
 		commitService:       commitService,
 		repositoryService:   repositoryService,
 		maxRetryAttempts:    maxRetryAttempts,
 		initialRetryBackoff: initialRetryBackoff,
 		githubService:       githubService,
+		jobService:          jobService,
+		backfills:           backfills,
+		backfillSliceDays:   backfillSliceDays,
+		enrichCommits:       cfg.ENRICH_COMMITS,
+		syncPullRequests:    cfg.SYNC_PULL_REQUESTS,
+		syncReleases:        cfg.SYNC_RELEASES,
 	}
+	jobService.RegisterHandler(domain.JobSyncCommits, m.runCommitSync)
+	jobService.RegisterHandler(domain.JobBackfillCommits, m.runBackfill)
+	jobService.RegisterHandler(domain.JobEnrichCommits, m.runEnrichment)
+	jobService.RegisterHandler(domain.JobParseCommitMessages, m.runMessageParsing)
+	jobService.RegisterHandler(domain.JobSyncBranches, m.runBranchSync)
+	jobService.RegisterHandler(domain.JobBackfillParents, m.runParentsBackfill)
+	return m
 }
 
 // MonitorRepository oversees monitoring both repository and commit information for changes.
@@ -50,16 +77,28 @@ func (m *MonitorService) MonitorRepository(ctx context.Context, rData domain.Rep
 	return nil
 }
 
+// RateLimit returns the GitHub API quota currently available for monitoring
+func (m *MonitorService) RateLimit() domain.RateLimit {
+	return m.githubService.RateLimit()
+}
+
 // SyncRepositoryInfo fetches and updates repository information.
+// It returns false without an error when GitHub reports the repository unchanged since the last sync.
 func (ms *MonitorService) SyncRepositoryInfo(ctx context.Context, r domain.RepoData) (bool, error) {
 
 	updatedRepository, err := ms.githubService.FetchRepository(ctx, r.Owner, r.RepoName)
+	if errors.Is(err, domain.ErrNotModified) {
+		logger.LogInfo(fmt.Sprintf("Repository %s/%s unchanged, skipping sync", r.Owner, r.RepoName))
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
 
 	ok, err := ms.repositoryService.UpdateInsert(ctx, updatedRepository)
 	if !ok {
+		// The response's validators are cached already; drop them so the next sync is not told the metadata it failed to store is unchanged
+		ms.githubService.ForgetRepository(r.Owner, r.RepoName)
 		return false, err
 	}
 
@@ -68,96 +107,433 @@ func (ms *MonitorService) SyncRepositoryInfo(ctx context.Context, r domain.RepoD
 
 // syncRepositoryAndCommits fetches and updates both repository information and commits.
 func (m *MonitorService) syncRepositoryAndCommits(ctx context.Context, rData domain.RepoData) error {
-	ok, err := m.SyncRepositoryInfo(ctx, rData)
+	if rateLimit := m.RateLimit(); rateLimit.Known && rateLimit.Remaining == 0 {
+		logger.LogWarning(fmt.Sprintf("GitHub quota exhausted, syncing %s/%s will wait until %s", rData.Owner, rData.RepoName, rateLimit.Reset.Format(time.RFC3339)))
+	}
+
+	if _, err := m.SyncRepositoryInfo(ctx, rData); err != nil {
+		return err
+	}
+
+	// Commits are pulled whether or not the metadata changed: the commit listing is sent as its own
+	// conditional request, which tells whether there is anything new to save
+	if err := m.MonitorRepositoryCommits(ctx, rData); err != nil {
+		return fmt.Errorf("could not queue commit sync for %s: %w", rData.FullName(), err)
+	}
+	return m.QueueBranchSync(ctx, rData)
+}
 
+// MonitorRepositoryCommits queues a job that pulls the commits made on the default branch since the last saved one.
+// A repository without saved commits gets a backfill of its whole history instead, unless a completed backfill found
+// none, and no pull is queued while a backfill is still running since the backfill already covers that window.
+func (m *MonitorService) MonitorRepositoryCommits(ctx context.Context, rData domain.RepoData) error {
+	backfill, err := m.backfills.GetBackfill(ctx, rData.Owner, rData.RepoName)
 	if err != nil {
 		return err
 	}
-	if ok {
-		m.MonitorRepositoryCommits(ctx, rData.RepoName)
+	if backfill != nil && backfill.Status != domain.BackfillCompleted {
+		logger.LogInfo(fmt.Sprintf("Backfill of %s/%s still running, skipping incremental sync", rData.Owner, rData.RepoName))
+		return nil
+	}
+
+	// Retrieve the last saved commit of the default branch
+	branch, lastCommit, err := m.lastDefaultBranchCommit(ctx, rData.Owner, rData.RepoName)
+	if err != nil { // Handle DB error, except for no rows (no last commit case)
+		return fmt.Errorf("could not get last saved commit: %w", err)
+	}
+
+	// No last commit found, backfill from the repository creation date
+	if lastCommit == nil && backfill == nil {
+		_, err := m.AddRepositoryCommitsToMonitor(ctx, rData, time.Time{}, time.Time{}, 1)
+		return err
+	}
+
+	// A completed backfill that found no commits only leaves the commits made since it ended to pull
+	var since time.Time
+	if lastCommit != nil {
+		since = nextSyncStart(lastCommit)
+	} else {
+		since = backfill.RangeEnd
 	}
 
+	// Queue a job to save commits from the last commit date to now
+	_, err = m.jobService.Enqueue(ctx, domain.JobSyncCommits, rData.Owner, rData.RepoName, domain.CommitFetchOptions{Since: since, Branch: branch})
+	return err
+}
+
+// QueueBranchSync queues a job that pulls the commits of the branches a watchlist entry tracks besides the default one,
+// when it tracks any.
+func (m *MonitorService) QueueBranchSync(ctx context.Context, rData domain.RepoData) error {
+	if len(rData.Branches) == 0 {
+		return nil
+	}
+	payload := domain.BranchSyncPayload{Branches: rData.Branches}
+	if _, err := m.jobService.Enqueue(ctx, domain.JobSyncBranches, rData.Owner, rData.RepoName, payload); err != nil {
+		return fmt.Errorf("could not queue branch sync for %s: %w", rData.FullName(), err)
+	}
 	return nil
 }
 
-func (m *MonitorService) MonitorRepositoryCommits(ctx context.Context, repositoryName string, startAt ...time.Time) error {
-	// Retrieve the last saved commit for the repository
-	lastCommit, err := m.commitService.LastCommit(ctx, repositoryName)
+// defaultBranch returns the name of the default branch of a repository as stored with its metadata,
+// or an empty string when it is not known yet
+func (m *MonitorService) defaultBranch(ctx context.Context, owner, repositoryName string) string {
+	repository, err := m.repositoryService.GetRepository(ctx, owner, repositoryName)
+	if err != nil {
+		return ""
+	}
+	return repository.DefaultBranch
+}
 
-	if err != nil { // Handle DB error, except for no rows (no last commit case)
-		return fmt.Errorf("could not get last saved commit: %w", err)
+// lastDefaultBranchCommit returns the default branch of a repository along with its last saved commit, which commits
+// pulled from other branches do not count as. When the default branch is not known yet, the last saved commit of any
+// branch is returned.
+func (m *MonitorService) lastDefaultBranchCommit(ctx context.Context, owner, repositoryName string) (string, *domain.Commit, error) {
+	branch := m.defaultBranch(ctx, owner, repositoryName)
+	if branch == "" {
+		lastCommit, err := m.commitService.LastCommit(ctx, owner, repositoryName)
+		return "", lastCommit, err
+	}
+
+	lastCommit, err := m.commitService.LastBranchCommit(ctx, owner, repositoryName, branch)
+	if err != nil || lastCommit != nil {
+		return branch, lastCommit, err
+	}
+
+	// Commits saved before branches were recorded were all pulled from the default branch
+	assigned, err := m.commitService.AssignUnbranchedCommits(ctx, owner, repositoryName, branch)
+	if err != nil || assigned == 0 {
+		return branch, nil, err
 	}
+	logger.LogInfo(fmt.Sprintf("Recorded %d saved commits of %s/%s on branch %s", assigned, owner, repositoryName, branch))
+	lastCommit, err = m.commitService.LastBranchCommit(ctx, owner, repositoryName, branch)
+	return branch, lastCommit, err
+}
 
-	// Get the repository owner and name
-	r, err := m.repositoryService.GetRepository(ctx, repositoryName)
+// AddRepositoryCommitsToMonitor starts a backfill of the commit history of a repository, starting from startAt when set.
+// The backfill stops at endAt when it is set, otherwise at the time it is started, and is pulled in time slices
+// by a background job. A startPage greater than one resumes an interrupted pull of the first slice from that page.
+// If an earlier backfill of the repository has not completed, it is resumed from its checkpoint instead.
+// It returns the job running the backfill so its progress can be followed.
+func (m *MonitorService) AddRepositoryCommitsToMonitor(ctx context.Context, rData domain.RepoData, startAt, endAt time.Time, startPage int) (*domain.Job, error) {
+	backfill, err := m.backfills.GetBackfill(ctx, rData.Owner, rData.RepoName)
 	if err != nil {
-		return fmt.Errorf("could not get repository owner and name: %w", err)
+		return nil, err
+	}
+	if backfill != nil && backfill.Status != domain.BackfillCompleted {
+		logger.LogInfo(fmt.Sprintf("Resuming backfill of %s/%s from %s", rData.Owner, rData.RepoName, backfill.Cursor.Format(time.RFC3339)))
+		return m.enqueueBackfill(ctx, backfill)
 	}
-	fmt.Println(r.CreatedAt)
-	// Handle case when there is no last commit
 
-	// No last commit found, use a default date or repository creation date
-	// Fetch commits since the repository creation date
-	var since time.Time
-	since = r.CreatedAt
+	// Start from the provided 'startAt' time, or work it out when it is zero
+	since := startAt
+	if since.IsZero() {
+		if since, err = m.defaultBackfillStart(ctx, rData); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fix the end of the range so progress can be measured against it
+	until := endAt
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+	if until.Before(since) {
+		return nil, fmt.Errorf("end date %s is before start date %s", until.Format(time.RFC3339), since.Format(time.RFC3339))
+	}
+
+	if backfill == nil {
+		backfill = &domain.Backfill{Owner: rData.Owner, Repository: rData.RepoName}
+	}
+	backfill.RangeStart = since
+	backfill.RangeEnd = until
+	backfill.Cursor = since
+	backfill.SliceDays = m.backfillSliceDays
+	backfill.StartPage = startPage
+	backfill.CommitsStored = 0
+	backfill.Status = domain.BackfillRunning
+	backfill.FinishedAt = nil
+	if err := m.backfills.SaveBackfill(ctx, backfill); err != nil {
+		return nil, err
+	}
+
+	return m.enqueueBackfill(ctx, backfill)
+}
+
+// defaultBackfillStart returns where a backfill starts when no start date is given: just after the
+// last saved commit of the default branch, or at the repository creation date when no commits are saved yet
+func (m *MonitorService) defaultBackfillStart(ctx context.Context, rData domain.RepoData) (time.Time, error) {
+	// Retrieve the last saved commit of the default branch
+	_, lastCommit, err := m.lastDefaultBranchCommit(ctx, rData.Owner, rData.RepoName)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not get last saved commit: %w", err)
+	}
 	if lastCommit != nil {
-		// Convert last commit date to time.Time
-		since = lastCommit.CommitDate
+		// Only pull commits newer than the last saved one
+		return nextSyncStart(lastCommit), nil
+	}
+
+	// Fetch repository info to get creation date if no last commit exists
+	repo, err := m.repositoryService.GetRepository(ctx, rData.Owner, rData.RepoName)
+	if err != nil {
+		// Attempt to sync repository info if fetching fails
+		if _, err := m.SyncRepositoryInfo(ctx, rData); err != nil {
+			return time.Time{}, err
+		}
+		// Try fetching repository info again
+		repo, err = m.repositoryService.GetRepository(ctx, rData.Owner, rData.RepoName)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not get repository info: %w", err)
+		}
+	}
+
+	// Use repository creation date if no last commit date is available
+	return repo.CreatedAt, nil
+}
+
+// GetBackfill returns the backfill of a repository along with its progress
+func (m *MonitorService) GetBackfill(ctx context.Context, owner, repositoryName string) (*domain.BackfillProgress, error) {
+	backfill, err := m.backfills.GetBackfill(ctx, owner, repositoryName)
+	if err != nil || backfill == nil {
+		return nil, err
+	}
+	return &domain.BackfillProgress{Backfill: *backfill, Percent: backfill.Progress()}, nil
+}
 
+// enqueueBackfill queues the job that runs a backfill and records it on the backfill
+func (m *MonitorService) enqueueBackfill(ctx context.Context, backfill *domain.Backfill) (*domain.Job, error) {
+	job, err := m.jobService.Enqueue(ctx, domain.JobBackfillCommits, backfill.Owner, backfill.Repository, nil)
+	if err != nil {
+		return nil, err
+	}
+	backfill.JobID = job.ID
+	if err := m.backfills.SaveBackfill(ctx, backfill); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// runBackfill runs a backfill_commits job. It walks the backfill range oldest first, one slice at a time,
+// and checkpoints the cursor once each slice is stored so a retried or restarted job carries on from there.
+func (m *MonitorService) runBackfill(ctx context.Context, job *domain.Job) error {
+	backfill, err := m.backfills.GetBackfill(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return err
+	}
+	if backfill == nil {
+		return fmt.Errorf("no backfill found for %s/%s", job.Owner, job.Repository)
 	}
-	// Asynchronously save commits from the last commit date (or repository creation date) to now
-	go func() {
-		_, err := m.commitService.SaveCommits(ctx, r.Owner, r.Name, since)
+
+	// The backfill pulls the default branch, which the commits are recorded on once it is known
+	branch := m.defaultBranch(ctx, job.Owner, job.Repository)
+
+	slice := time.Duration(backfill.SliceDays) * 24 * time.Hour
+	for backfill.Cursor.Before(backfill.RangeEnd) {
+		end := backfill.Cursor.Add(slice)
+		if end.After(backfill.RangeEnd) {
+			end = backfill.RangeEnd
+		}
+
+		// Slices bound the size of each pull, so the MAX_PAGES cap does not apply and every slice is walked in full
+		opts := domain.CommitFetchOptions{Since: backfill.Cursor, Until: end, StartPage: backfill.StartPage, MaxPages: -1, Branch: branch}
+		saved, err := m.commitService.SaveCommits(ctx, job.Owner, job.Repository, opts)
 		if err != nil {
-			log.Printf("error saving commits: %v", err)
+			return fmt.Errorf("backfill slice %s to %s failed after saving %d commits: %w", opts.Since.Format(time.RFC3339), end.Format(time.RFC3339), saved, err)
 		}
-	}()
 
+		backfill.Cursor = end
+		backfill.StartPage = 0
+		backfill.CommitsStored += saved
+		if err := m.backfills.SaveBackfill(ctx, backfill); err != nil {
+			return err
+		}
+		logger.LogInfo(fmt.Sprintf("Backfill of %s/%s at %.1f%%, %d commits stored", job.Owner, job.Repository, backfill.Progress(), backfill.CommitsStored))
+	}
+
+	now := time.Now()
+	backfill.Status = domain.BackfillCompleted
+	backfill.FinishedAt = &now
+	if err := m.backfills.SaveBackfill(ctx, backfill); err != nil {
+		return err
+	}
+	m.queueCommitFollowUps(ctx, job.Owner, job.Repository)
 	return nil
 }
 
-func (m *MonitorService) AddRepositoryCommitsToMonitor(ctx context.Context, rData domain.RepoData, startAt time.Time) error {
-	// Retrieve the last saved commit for the repository
-	lastCommit, err := m.commitService.LastCommit(ctx, rData.RepoName)
+// runCommitSync runs a sync_commits job, saving the window of commits described by its payload
+func (m *MonitorService) runCommitSync(ctx context.Context, job *domain.Job) error {
+	var opts domain.CommitFetchOptions
+	if err := json.Unmarshal([]byte(job.Payload), &opts); err != nil {
+		return fmt.Errorf("invalid sync_commits payload: %w", err)
+	}
+	// Jobs queued before branches were recorded pull the default branch
+	if opts.Branch == "" {
+		opts.Branch = m.defaultBranch(ctx, job.Owner, job.Repository)
+	}
+
+	saved, err := m.commitService.SaveCommits(ctx, job.Owner, job.Repository, opts)
 	if err != nil {
-		return fmt.Errorf("could not get last saved commit: %w", err)
+		return fmt.Errorf("saved %d commits before failing: %w", saved, err)
 	}
+	logger.LogInfo(fmt.Sprintf("Saved %d commits for %s/%s", saved, job.Owner, job.Repository))
+	m.queueCommitFollowUps(ctx, job.Owner, job.Repository)
+	return nil
+}
 
-	// Initialize the 'since' time variable
-	var since time.Time
-	if lastCommit != nil {
-		// Use the last commit date if it exists
-		since = lastCommit.CommitDate
-	} else {
-		// Fetch repository info to get creation date if no last commit exists
-		repo, err := m.repositoryService.GetRepository(ctx, rData.RepoName)
+// runBranchSync runs a sync_branches job, pulling every branch of the repository matching the payload's names or
+// glob patterns other than the default one, which the regular syncs pull. Each branch is pulled from its last saved
+// commit; a branch without saved commits is pulled back to where the repository's backfill started, or in full when
+// it has none, within the configured page cap. A retry pulls every branch again from its last saved commit.
+func (m *MonitorService) runBranchSync(ctx context.Context, job *domain.Job) error {
+	var payload domain.BranchSyncPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return fmt.Errorf("invalid sync_branches payload: %w", err)
+	}
+
+	names, err := m.githubService.FetchBranches(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return err
+	}
+	defaultBranch := m.defaultBranch(ctx, job.Owner, job.Repository)
+	backfill, err := m.backfills.GetBackfill(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return err
+	}
+
+	saved := 0
+	for _, branch := range matchBranches(payload.Branches, names) {
+		if branch == defaultBranch {
+			continue
+		}
+		opts := domain.CommitFetchOptions{Branch: branch}
+		lastCommit, err := m.commitService.LastBranchCommit(ctx, job.Owner, job.Repository, branch)
 		if err != nil {
-			// Attempt to sync repository info if fetching fails
-			if _, err := m.SyncRepositoryInfo(ctx, rData); err != nil {
-				return err
-			}
-			// Try fetching repository info again
-			repo, err = m.repositoryService.GetRepository(ctx, rData.RepoName)
-			if err != nil {
-				return fmt.Errorf("could not get repository info: %w", err)
+			return err
+		}
+		if lastCommit != nil {
+			opts.Since = nextSyncStart(lastCommit)
+		} else if backfill != nil {
+			opts.Since = backfill.RangeStart
+		}
+
+		count, err := m.commitService.SaveCommits(ctx, job.Owner, job.Repository, opts)
+		saved += count
+		if err != nil {
+			return fmt.Errorf("sync of branch %s failed after saving %d commits: %w", branch, saved, err)
+		}
+	}
+	logger.LogInfo(fmt.Sprintf("Saved %d commits from the tracked branches of %s/%s", saved, job.Owner, job.Repository))
+	m.queueCommitFollowUps(ctx, job.Owner, job.Repository)
+	return nil
+}
+
+// matchBranches returns the branch names that equal or match one of the glob patterns, in the order of names.
+// Patterns follow path.Match, so * does not match a slash: release/* matches release/1.0 but not release/1.0/hotfix.
+func matchBranches(patterns, names []string) []string {
+	var matched []string
+	for _, name := range names {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				matched = append(matched, name)
+				break
 			}
 		}
+	}
+	return matched
+}
+
+// queueCommitFollowUps queues the jobs that build on newly saved commits of a repository
+func (m *MonitorService) queueCommitFollowUps(ctx context.Context, owner, repositoryName string) {
+	m.queueIdentityResolution(ctx, owner, repositoryName)
+	m.queueEnrichment(ctx, owner, repositoryName)
+	m.queuePullRequestSync(ctx, owner, repositoryName)
+	m.queueReleaseSync(ctx, owner, repositoryName)
+}
+
+// queueEnrichment queues a job that enriches the commits of a repository saved since the last enrichment, when enabled.
+// Failing to queue it is only logged: the commits are saved, and the next sync queues enrichment again.
+func (m *MonitorService) queueEnrichment(ctx context.Context, owner, repositoryName string) {
+	if !m.enrichCommits {
+		return
+	}
+	if _, err := m.jobService.Enqueue(ctx, domain.JobEnrichCommits, owner, repositoryName, nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue enrichment for %s/%s: %w", owner, repositoryName, err))
+	}
+}
 
-		// Use repository creation date if no last commit date is available
-		since = repo.CreatedAt
+// queuePullRequestSync queues a job that syncs the pull requests of a repository and links the commits they merged, when enabled.
+// Failing to queue it is only logged: the next sync queues it again.
+func (m *MonitorService) queuePullRequestSync(ctx context.Context, owner, repositoryName string) {
+	if !m.syncPullRequests {
+		return
 	}
+	if _, err := m.jobService.Enqueue(ctx, domain.JobSyncPullRequests, owner, repositoryName, nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue pull request sync for %s/%s: %w", owner, repositoryName, err))
+	}
+}
 
-	// Override the 'since' time with the provided 'startAt' time if it's not zero
-	if !startAt.IsZero() {
-		since = startAt
+// queueReleaseSync queues a job that syncs the releases of a repository and maps its commits to them, when enabled.
+// Failing to queue it is only logged: the next sync queues it again.
+func (m *MonitorService) queueReleaseSync(ctx context.Context, owner, repositoryName string) {
+	if !m.syncReleases {
+		return
 	}
+	if _, err := m.jobService.Enqueue(ctx, domain.JobSyncReleases, owner, repositoryName, nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue release sync for %s/%s: %w", owner, repositoryName, err))
+	}
+}
 
-	// Asynchronously save commits from the 'since' date to now
-	go func() {
-		if _, err := m.commitService.SaveCommits(ctx, rData.Owner, rData.RepoName, since); err != nil {
-			log.Printf("error saving commits: %v", err)
-		}
-	}()
+// queueIdentityResolution queues a job that attributes the newly saved commits of a repository to author identities.
+// Failing to queue it is only logged: until the next sync queues it again, the commits are grouped by login or email.
+func (m *MonitorService) queueIdentityResolution(ctx context.Context, owner, repositoryName string) {
+	if _, err := m.jobService.Enqueue(ctx, domain.JobResolveIdentities, owner, repositoryName, nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue identity resolution for %s/%s: %w", owner, repositoryName, err))
+	}
+}
+
+// runEnrichment runs an enrich_commits job, recording the stats and files of every commit not enriched yet.
+// Commits enriched before a failure keep their data, so a retry carries on with the rest.
+func (m *MonitorService) runEnrichment(ctx context.Context, job *domain.Job) error {
+	enriched, err := m.commitService.EnrichCommits(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return fmt.Errorf("enriched %d commits before failing: %w", enriched, err)
+	}
+	logger.LogInfo(fmt.Sprintf("Enriched %d commits for %s/%s", enriched, job.Owner, job.Repository))
+	return nil
+}
+
+// runMessageParsing runs a parse_commit_messages job, parsing the messages of every commit stored unparsed.
+// Commits parsed before a failure keep their parts, so a retry carries on with the rest.
+func (m *MonitorService) runMessageParsing(ctx context.Context, job *domain.Job) error {
+	parsed, err := m.commitService.ParseCommitMessages(ctx)
+	if err != nil {
+		return fmt.Errorf("parsed %d commit messages before failing: %w", parsed, err)
+	}
+	if parsed > 0 {
+		logger.LogInfo(fmt.Sprintf("Parsed the messages of %d stored commits", parsed))
+	}
+	return nil
+}
 
+// runParentsBackfill runs a backfill_parents job, pulling the default branch again over the commits of the repository
+// stored before their parents were. The commits saved again lose their identity, and can now be mapped to releases,
+// so the jobs that build on saved commits are queued again.
+func (m *MonitorService) runParentsBackfill(ctx context.Context, job *domain.Job) error {
+	branch := m.defaultBranch(ctx, job.Owner, job.Repository)
+	missing, err := m.commitService.BackfillParents(ctx, job.Owner, job.Repository, branch)
+	if err != nil {
+		return err
+	}
+	if missing.Count > 0 {
+		logger.LogWarning(fmt.Sprintf("%d commits of %s/%s are no longer on the default branch, their parents stay unknown", missing.Count, job.Owner, job.Repository))
+	}
+	m.queueCommitFollowUps(ctx, job.Owner, job.Repository)
 	return nil
 }
+
+// nextSyncStart returns the start of the window for an incremental sync after lastCommit.
+// GitHub's since filter is inclusive and commit dates have second precision, so the window opens
+// at the last saved commit: fetching it again is harmless because saves are idempotent,
+// whereas starting any later could miss other commits made in the same second.
+func nextSyncStart(lastCommit *domain.Commit) time.Time {
+	return lastCommit.CommitDate
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+)
+
+// pullRequestSaveBatch is the number of pull requests whose reviews are fetched before they are saved together
+const pullRequestSaveBatch = 50
+
+// PullRequestService syncs the pull requests of monitored repositories and links them to the commits they merged
+type PullRequestService struct {
+	pullRequests  ports.PostgresPullRequest
+	githubService ports.GithubImpl
+}
+
+// NewPullRequestService creates a PullRequestService and registers the job that syncs pull requests
+func NewPullRequestService(pullRequests ports.PostgresPullRequest, githubService ports.GithubImpl, jobService *JobService) *PullRequestService {
+	s := &PullRequestService{pullRequests: pullRequests, githubService: githubService}
+	jobService.RegisterHandler(domain.JobSyncPullRequests, s.runPullRequestSync)
+	return s
+}
+
+// SyncPullRequests saves the pull requests of a repository updated since the last sync, with their review counts,
+// then links the commits they merged. The first sync fetches every pull request.
+// It returns the number of pull requests saved.
+func (s *PullRequestService) SyncPullRequests(ctx context.Context, owner, repositoryName string) (int, error) {
+	// Pull requests updated in the same second as the latest one are fetched again, which is harmless as saves are idempotent
+	since, err := s.pullRequests.GetLatestPullRequestUpdate(ctx, owner, repositoryName)
+	if err != nil {
+		return 0, err
+	}
+
+	// The pull requests arrive least recently updated first and are saved in that order, so whatever is stored
+	// before a failure is a prefix of them and the next sync resumes right after it
+	saved := 0
+	err = s.githubService.FetchPullRequests(ctx, owner, repositoryName, since, func(pulls []domain.PullRequest) error {
+		for start := 0; start < len(pulls); start += pullRequestSaveBatch {
+			batch := pulls[start:min(start+pullRequestSaveBatch, len(pulls))]
+			for i := range batch {
+				stats, err := s.githubService.FetchPullReviewStats(ctx, owner, repositoryName, batch[i].Number)
+				if err != nil {
+					return err
+				}
+				batch[i].Reviews = stats.Reviews
+				batch[i].Approvals = stats.Approvals
+				batch[i].FirstReviewAt = stats.FirstReviewAt
+			}
+			if err := s.pullRequests.SavePullRequests(ctx, batch); err != nil {
+				return err
+			}
+			saved += len(batch)
+		}
+		return nil
+	})
+	if errors.Is(err, domain.ErrNotModified) {
+		return 0, nil
+	}
+	if err != nil {
+		return saved, err
+	}
+
+	// Commits saved since the last sync may be merge commits of pull requests stored earlier, so linking always runs
+	if _, err := s.pullRequests.LinkMergeCommits(ctx, owner, repositoryName); err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
+// GetPullRequests returns a page of the pull requests of a repository matching the filter, newest first,
+// along with the total number of pull requests matching it
+func (s *PullRequestService) GetPullRequests(ctx context.Context, owner, repositoryName string, filter domain.PullRequestFilter, page, limit int) ([]domain.PullRequest, int64, error) {
+	return s.pullRequests.ListPullRequests(ctx, owner, repositoryName, filter, page, limit)
+}
+
+// runPullRequestSync runs a sync_pull_requests job.
+// Pull requests saved before a failure are kept, and a retry resumes from the most recently updated of them.
+func (s *PullRequestService) runPullRequestSync(ctx context.Context, job *domain.Job) error {
+	saved, err := s.SyncPullRequests(ctx, job.Owner, job.Repository)
+	if err != nil {
+		return fmt.Errorf("saved %d pull requests before failing: %w", saved, err)
+	}
+	logger.LogInfo(fmt.Sprintf("Saved %d pull requests for %s/%s", saved, job.Owner, job.Repository))
+	return nil
+}
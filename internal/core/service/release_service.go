@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github-service/internal/core/domain"
+	"github-service/internal/ports"
+	"github-service/pkg/logger"
+)
+
+// ReleaseService syncs the releases and tags of monitored repositories and maps every stored commit
+// to the first release that contains it
+type ReleaseService struct {
+	releases      ports.PostgresRelease
+	commits       ports.PostgresCommit
+	githubService ports.GithubImpl
+	jobService    *JobService
+}
+
+// NewReleaseService creates a ReleaseService and registers the job that syncs releases
+func NewReleaseService(releases ports.PostgresRelease, commits ports.PostgresCommit, githubService ports.GithubImpl, jobService *JobService) *ReleaseService {
+	s := &ReleaseService{releases: releases, commits: commits, githubService: githubService, jobService: jobService}
+	jobService.RegisterHandler(domain.JobSyncReleases, s.runReleaseSync)
+	return s
+}
+
+// SyncReleases saves the tags and releases of a repository, then maps its commits to releases.
+// Releases are matched to their commit through their tag. It returns the number of releases saved.
+func (s *ReleaseService) SyncReleases(ctx context.Context, owner, repositoryName string) (int, error) {
+	tags, err := s.githubService.FetchTags(ctx, owner, repositoryName)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.releases.SaveTags(ctx, tags); err != nil {
+		return 0, err
+	}
+
+	releases, err := s.githubService.FetchReleases(ctx, owner, repositoryName)
+	if err != nil {
+		return 0, err
+	}
+	tagCommits, err := s.releases.GetTagCommits(ctx, owner, repositoryName)
+	if err != nil {
+		return 0, err
+	}
+	for i := range releases {
+		releases[i].CommitSHA = tagCommits[releases[i].TagName]
+	}
+	if err := s.releases.SaveReleases(ctx, releases); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.MapCommitReleases(ctx, owner, repositoryName); err != nil {
+		return len(releases), err
+	}
+	return len(releases), nil
+}
+
+// MapCommitReleases records on every stored commit of a repository the first release that contains it.
+// Releases are walked in the order they were published, each claiming the commits reachable from its tag through
+// the parents of stored commits that no earlier release reached. It returns the number of commits whose release changed.
+// When a walk reaches commits stored before their parents were, the commits behind them cannot be told apart, so nothing
+// is recorded: a job pulling those commits again is queued, which maps them once done, and domain.ErrHistoryIncomplete
+// is returned.
+func (s *ReleaseService) MapCommitReleases(ctx context.Context, owner, repositoryName string) (int, error) {
+	releases, err := s.orderedReleases(ctx, owner, repositoryName)
+	if err != nil {
+		return 0, err
+	}
+
+	parents := make(map[string][]string)
+	unknown := make(map[string]bool)
+	recorded := make(map[string]string)
+	err = s.releases.EachCommitNode(ctx, owner, repositoryName, func(node domain.CommitNode) error {
+		parents[node.Hash] = node.Parents
+		if node.UnknownParents {
+			unknown[node.Hash] = true
+		}
+		if node.ReleaseTag != "" {
+			recorded[node.Hash] = node.ReleaseTag
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Walk history depth first from each release's commit; commits that are not stored, like the unknown commit
+	// of a release whose tag was not found, end the walk
+	mapped := make(map[string]string, len(parents))
+	unwalked := 0
+	for _, release := range releases {
+		stack := []string{release.CommitSHA}
+		for len(stack) > 0 {
+			hash := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if _, seen := mapped[hash]; seen {
+				continue
+			}
+			commitParents, stored := parents[hash]
+			if !stored {
+				continue
+			}
+			mapped[hash] = release.TagName
+			if unknown[hash] {
+				unwalked++
+			}
+			stack = append(stack, commitParents...)
+		}
+	}
+	if unwalked > 0 {
+		queueParentsBackfill(ctx, s.jobService, owner, repositoryName)
+		return 0, fmt.Errorf("%w: %d commits of %s/%s reached from releases", domain.ErrHistoryIncomplete, unwalked, owner, repositoryName)
+	}
+
+	// Only record the commits whose release changed, grouped by their new release
+	changed := make(map[string][]string)
+	for hash := range parents {
+		if mapped[hash] != recorded[hash] {
+			changed[mapped[hash]] = append(changed[mapped[hash]], hash)
+		}
+	}
+	count := 0
+	for tag, hashes := range changed {
+		if err := s.releases.SetCommitReleases(ctx, owner, repositoryName, tag, hashes); err != nil {
+			return count, err
+		}
+		count += len(hashes)
+	}
+	return count, nil
+}
+
+// orderedReleases returns the releases of a repository in the order they were published, oldest first
+func (s *ReleaseService) orderedReleases(ctx context.Context, owner, repositoryName string) ([]domain.Release, error) {
+	releases, err := s.releases.GetReleases(ctx, owner, repositoryName)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if !releases[i].ReleasedAt().Equal(releases[j].ReleasedAt()) {
+			return releases[i].ReleasedAt().Before(releases[j].ReleasedAt())
+		}
+		return releases[i].TagName < releases[j].TagName
+	})
+	return releases, nil
+}
+
+// GetReleases returns a page of the releases of a repository, most recent first, along with the total number of releases
+func (s *ReleaseService) GetReleases(ctx context.Context, owner, repositoryName string, page, limit int) ([]domain.Release, int, error) {
+	releases, err := s.orderedReleases(ctx, owner, repositoryName)
+	if err != nil {
+		return nil, 0, err
+	}
+	slices.Reverse(releases)
+	start := min((page-1)*limit, len(releases))
+	end := min(start+limit, len(releases))
+	return releases[start:end], len(releases), nil
+}
+
+// GetReleaseCommits returns a page of the commits first shipped in the release with the given tag, that is those
+// between its tag and the previous release's, newest first, along with the release and the one before it.
+// The filter further narrows the commits. It returns domain.ErrReleaseNotFound if there is no such release.
+func (s *ReleaseService) GetReleaseCommits(ctx context.Context, owner, repositoryName, tag string, filter domain.CommitFilter, page, limit int) (domain.ReleaseCommitsResponse, error) {
+	response := domain.ReleaseCommitsResponse{CurrentPage: page, Commits: []domain.Commit{}}
+	releases, err := s.orderedReleases(ctx, owner, repositoryName)
+	if err != nil {
+		return response, err
+	}
+	found := false
+	for i, release := range releases {
+		if release.TagName == tag {
+			response.Release, found = release, true
+			if i > 0 {
+				response.Previous = &releases[i-1]
+			}
+			break
+		}
+	}
+	if !found {
+		return response, domain.ErrReleaseNotFound
+	}
+
+	filter.Release = tag
+	total, err := s.commits.GetTotalCommits(ctx, owner, repositoryName, filter)
+	if err != nil {
+		return response, err
+	}
+	response.TotalPages = int((total + int64(limit) - 1) / int64(limit))
+	if total == 0 {
+		return response, nil
+	}
+	if response.Commits, err = s.commits.GetCommits(ctx, owner, repositoryName, filter, page, limit); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// runReleaseSync runs a sync_releases job
+func (s *ReleaseService) runReleaseSync(ctx context.Context, job *domain.Job) error {
+	saved, err := s.SyncReleases(ctx, job.Owner, job.Repository)
+	if errors.Is(err, domain.ErrHistoryIncomplete) {
+		// The backfill of parents queues this job again once it is done
+		logger.LogWarning(fmt.Sprintf("Saved %d releases for %s/%s without mapping its commits: %v", saved, job.Owner, job.Repository, err))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("release sync failed after saving %d releases: %w", saved, err)
+	}
+	logger.LogInfo(fmt.Sprintf("Saved %d releases for %s/%s", saved, job.Owner, job.Repository))
+	return nil
+}
+
+// queueParentsBackfill queues a job that pulls the commits of a repository stored before their parents were again.
+// Failing to queue it is only logged: the next walk that reaches those commits queues it again.
+func queueParentsBackfill(ctx context.Context, jobService *JobService, owner, repositoryName string) {
+	if _, err := jobService.Enqueue(ctx, domain.JobBackfillParents, owner, repositoryName, nil); err != nil {
+		logger.LogError(fmt.Errorf("could not queue parents backfill for %s/%s: %w", owner, repositoryName, err))
+	}
+}
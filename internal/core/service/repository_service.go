@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github-service/config"
 	"github-service/pkg/logger"
+	"sort"
+	"time"
 
 	"github-service/internal/core/domain"
 	"github-service/internal/ports"
@@ -12,10 +14,12 @@ import (
 
 type RepositoryServiceImpl interface {
 	FetchAndSaveRepository(ctx context.Context, rData domain.RepoData) (*domain.Repository, error)
-	GetRepository(ctx context.Context, repositoryName string) (domain.Repository, error)
+	GetRepository(ctx context.Context, owner, repositoryName string) (domain.Repository, error)
 	UpdateInsert(ctx context.Context, d *domain.Repository) (bool, error)
-	GetTopNCommitAuthors(ctx context.Context, repositoryName string, n, page, limit int) (domain.TopAuthorsCount, error)
+	GetTopNCommitAuthors(ctx context.Context, owner, repositoryName string, since, until time.Time, n, page, limit int) (domain.TopAuthorsCount, error)
+	GetBusFactor(ctx context.Context, owner, repositoryName string, since, until time.Time, threshold float64, metric domain.BusFactorMetric) (domain.BusFactorReport, error)
 	DeleteARepository(ctx context.Context, owner, repositoryName string) (bool, error)
+	WatchRepository(rData domain.RepoData) error
 }
 
 // RepositoryService provides operations for managing repository and cfg injection
@@ -34,7 +38,7 @@ func NewRepositoryService(postgresRepo ports.PostgresRepository, commitService C
 
 // RepositoryService fetches the repository data from GitHub and saves it to the database
 func (rs *RepositoryService) FetchAndSaveRepository(ctx context.Context, rData domain.RepoData) (*domain.Repository, error) {
-	if err := rs.badgerService.UpdateRepoArray("repos", rData); err != nil {
+	if err := rs.WatchRepository(rData); err != nil {
 		logger.LogError(err)
 	}
 	// Fetch the repository data from GitHub
@@ -58,34 +62,135 @@ func (rs *RepositoryService) UpdateInsert(ctx context.Context, d *domain.Reposit
 	if err := rs.postgresRepo.SaveRepository(ctx, d); err != nil {
 		return false, err
 	}
-	logger.LogInfo(fmt.Sprintf("Saved repository,%s", d.Name))
+	logger.LogInfo(fmt.Sprintf("Saved repository,%s/%s", d.Owner, d.Name))
 	return true, nil
 }
 
-// GetRepository gets a saved repository by the repository name
-func (rs *RepositoryService) GetRepository(ctx context.Context, repositoryName string) (domain.Repository, error) {
-	data, err := rs.postgresRepo.GetRepositoryByName(ctx, repositoryName)
+// GetRepository gets a saved repository by the repository owner and name
+func (rs *RepositoryService) GetRepository(ctx context.Context, owner, repositoryName string) (domain.Repository, error) {
+	data, err := rs.postgresRepo.GetRepositoryByName(ctx, owner, repositoryName)
 
 	return data, err
 }
 
-func (rs *RepositoryService) GetTopNCommitAuthors(ctx context.Context, repoName string, n, page, limit int) (domain.TopAuthorsCount, error) {
-	// Calculate how many authors to fetch on this page
-	if page*limit > n {
-		// Adjust limit to fetch only the remaining authors needed to reach `n`
-		limit = n - (page-1)*limit
+// GetTopNCommitAuthors returns a page of the n authors with the most commits between since and until
+func (rs *RepositoryService) GetTopNCommitAuthors(ctx context.Context, owner, repoName string, since, until time.Time, n, page, limit int) (domain.TopAuthorsCount, error) {
+	// Pages past the n-th author are empty
+	offset := (page - 1) * limit
+	if offset >= n {
+		return domain.TopAuthorsCount{}, nil
+	}
+	// Fetch the page with the requested limit so its offset is unchanged, then trim it to the n-th author
+	authors, err := rs.postgresRepo.GetTopNCommitAuthors(ctx, owner, repoName, since, until, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(authors) > n-offset {
+		authors = authors[:n-offset]
 	}
-	// Fetch the authors using pagination
-	authors, err := rs.postgresRepo.GetTopNCommitAuthors(ctx, repoName, page, limit)
 	return authors, err
 }
+
+// busFactorPageSize is the number of authors GetBusFactor loads at a time
+const busFactorPageSize = 1000
+
+// GetBusFactor returns the smallest number of authors who account for threshold percent of the commits made between
+// since and until, and the same per top-level directory from the changed lines of enriched commits.
+// An empty metric measures the repository in changed lines when every commit in the window is enriched,
+// and in commits otherwise; lines are only ever counted for enriched commits.
+func (rs *RepositoryService) GetBusFactor(ctx context.Context, owner, repoName string, since, until time.Time, threshold float64, metric domain.BusFactorMetric) (domain.BusFactorReport, error) {
+	// Load every author, largest contributor first
+	var authors domain.TopAuthorsCount
+	for page := 1; ; page++ {
+		batch, err := rs.postgresRepo.GetTopNCommitAuthors(ctx, owner, repoName, since, until, page, busFactorPageSize)
+		if err != nil {
+			return domain.BusFactorReport{}, err
+		}
+		authors = append(authors, batch...)
+		if len(batch) < busFactorPageSize {
+			break
+		}
+	}
+
+	if metric == "" {
+		metric = domain.BusFactorCommits
+		if len(authors) > 0 {
+			metric = domain.BusFactorLines
+		}
+		for _, author := range authors {
+			if author.EnrichedCommits < author.Count {
+				metric = domain.BusFactorCommits
+				break
+			}
+		}
+	}
+
+	shares := make([]domain.AuthorShare, len(authors))
+	for i, author := range authors {
+		shares[i] = domain.AuthorShare{Identity: author.Identity, Author: author.Author, Value: author.Count}
+		if metric == domain.BusFactorLines {
+			shares[i].Value = author.LinesAdded + author.LinesRemoved
+		}
+	}
+	report := domain.BusFactorReport{Threshold: threshold, Repository: domain.ComputeBusFactor(shares, metric, threshold)}
+
+	// Per directory, by changed lines, or by file changes where only binary files were touched
+	contributions, err := rs.postgresRepo.GetDirectoryContributions(ctx, owner, repoName, since, until)
+	if err != nil {
+		return domain.BusFactorReport{}, err
+	}
+	byDirectory := make(map[string][]domain.DirectoryContribution)
+	for _, contribution := range contributions {
+		byDirectory[contribution.Directory] = append(byDirectory[contribution.Directory], contribution)
+	}
+	for directory, contributions := range byDirectory {
+		lines := 0
+		for _, contribution := range contributions {
+			lines += contribution.Lines
+		}
+
+		directoryMetric := domain.BusFactorLines
+		shares := make([]domain.AuthorShare, len(contributions))
+		for i, contribution := range contributions {
+			shares[i] = domain.AuthorShare{Identity: contribution.Identity, Author: contribution.Author, Value: contribution.Lines}
+			if lines == 0 {
+				directoryMetric = domain.BusFactorFiles
+				shares[i].Value = contribution.Files
+			}
+		}
+		factor := domain.ComputeBusFactor(shares, directoryMetric, threshold)
+		factor.Directory = directory
+		report.Directories = append(report.Directories, factor)
+	}
+
+	// Most concentrated directories first
+	sort.Slice(report.Directories, func(i, j int) bool {
+		a, b := report.Directories[i], report.Directories[j]
+		if a.BusFactor != b.BusFactor {
+			return a.BusFactor < b.BusFactor
+		}
+		return a.Directory < b.Directory
+	})
+	return report, nil
+}
+
+// WatchRepository adds a repository to the watchlist; the scheduler starts polling it right away
+func (rs *RepositoryService) WatchRepository(rData domain.RepoData) error {
+	return rs.badgerService.UpdateRepoArray(watchlistKey, rData)
+}
+
+// DeleteARepository removes a repository from the watchlist, which stops its polling,
+// and deletes its metadata and commits
 func (rs *RepositoryService) DeleteARepository(ctx context.Context, owner, repositoryName string) (bool, error) {
+	if err := rs.badgerService.RemoveFromRepoArray(watchlistKey, domain.RepoData{Owner: owner, RepoName: repositoryName}); err != nil {
+		return false, err
+	}
 
 	ok, err := rs.postgresRepo.DeleteRepository(ctx, owner, repositoryName)
 	if !ok {
 		return false, err
 	}
-	ok, err = rs.commitService.DeleteCommits(ctx, repositoryName)
+	ok, err = rs.commitService.DeleteCommits(ctx, owner, repositoryName)
 	if !ok {
 		return false, err
 	}
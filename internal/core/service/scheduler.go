@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github-service/config"
 	"github-service/internal/core/domain"
+	"slices"
+	"sync"
 
 	"github-service/internal/ports"
 	"github-service/pkg/logger"
@@ -13,50 +16,113 @@ import (
 	"github.com/go-co-op/gocron"
 )
 
+// watchlistKey is the Badger key holding the array of repositories to monitor
+const watchlistKey = "repos"
+
 type Scheduler struct {
 	monitorService *MonitorService
+	issueService   *IssueService
 	cfg            *config.Config
 	badgerImpl     ports.BadgerImpl
-	schedulers     map[string]*gocron.Scheduler // Map to track schedulers by repo ID
+	mu             sync.Mutex                   // Guards schedulers and entries
+	schedulers     map[string]*gocron.Scheduler // Map to track schedulers by repo owner/name
+	entries        map[string]domain.RepoData   // The watchlist entry each scheduler polls, by repo owner/name
 }
 
-func NewScheduler(monitorService *MonitorService, cfg *config.Config, badgerImpl ports.BadgerImpl) *Scheduler {
+func NewScheduler(monitorService *MonitorService, issueService *IssueService, cfg *config.Config, badgerImpl ports.BadgerImpl) *Scheduler {
 	return &Scheduler{
 		monitorService: monitorService,
+		issueService:   issueService,
 		cfg:            cfg,
 		schedulers:     make(map[string]*gocron.Scheduler),
+		entries:        make(map[string]domain.RepoData),
 		badgerImpl:     badgerImpl,
 	}
 }
 
-func (s *Scheduler) ScheduleMonitoring() {
-	repoDataArray, _ := s.badgerImpl.GetRepoArray("repos")
+// ScheduleMonitoring schedules a monitoring job for every repository on the watchlist, then keeps
+// the jobs in line with the watchlist as repositories are added or removed, until ctx is cancelled.
+func (s *Scheduler) ScheduleMonitoring(ctx context.Context) {
+	repoDataArray, err := s.badgerImpl.GetRepoArray(watchlistKey)
+	if err != nil {
+		logger.LogError(fmt.Errorf("could not read watchlist: %w", err))
+	}
+	s.reconcile(repoDataArray)
+
+	err = s.badgerImpl.WatchRepoArray(ctx, watchlistKey, s.reconcile)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.LogError(fmt.Errorf("stopped watching watchlist: %w", err))
+	}
+}
+
+// reconcile starts a job for every watched repository that has none, restarts the jobs of repositories
+// whose tracked branches changed, and stops the jobs of repositories that are no longer watched.
+// Stopping a scheduler waits for its running job, so the stopped ones are only stopped once the lock is released.
+func (s *Scheduler) reconcile(repoDataArray []domain.RepoData) {
+	// Deferred before taking the lock, so it runs after the lock is released
+	var stopped []*gocron.Scheduler
+	defer func() {
+		for _, scheduler := range stopped {
+			scheduler.Stop()
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watched := make(map[string]bool, len(repoDataArray))
 	for _, repo := range repoDataArray {
-		repoKey := repo.RepoName // Use RepoName as the key for the schedulers map
-		logger.LogInfo(fmt.Sprintf("Monitoring scheduled for repository: %s", repoKey))
+		repoKey := repo.FullName() // Use owner/name as the key for the schedulers map
+		watched[repoKey] = true
 
+		if scheduler, exists := s.schedulers[repoKey]; exists && !slices.Equal(s.entries[repoKey].Branches, repo.Branches) {
+			stopped = append(stopped, s.schedulerRemove(scheduler, repoKey))
+		}
 		if _, exists := s.schedulers[repoKey]; !exists {
+			logger.LogInfo(fmt.Sprintf("Monitoring scheduled for repository: %s", repoKey))
 			scheduler := gocron.NewScheduler(time.UTC)
-			s.schedulerJob(scheduler, repo)
+			if err := s.schedulerJob(scheduler, repo); err != nil {
+				logger.LogError(fmt.Errorf("could not schedule monitoring for %s: %w", repoKey, err))
+				continue
+			}
 			s.schedulerStart(scheduler, repo)
 		}
 	}
+
+	for repoKey, scheduler := range s.schedulers {
+		if !watched[repoKey] {
+			stopped = append(stopped, s.schedulerRemove(scheduler, repoKey))
+		}
+	}
 }
 
-func (s *Scheduler) schedulerJob(scheduler *gocron.Scheduler, r domain.RepoData) {
-	scheduler.Every(s.cfg.POLL_INTERVAL).Do(func() {
+func (s *Scheduler) schedulerJob(scheduler *gocron.Scheduler, r domain.RepoData) error {
+	_, err := scheduler.Every(time.Duration(s.cfg.POLL_INTERVAL) * time.Second).Do(func() {
 		s.monitorRepository(r)
 	})
+	return err
 }
 
+// schedulerStart registers and starts a repository's scheduler; callers must hold s.mu
 func (s *Scheduler) schedulerStart(scheduler *gocron.Scheduler, r domain.RepoData) {
-	s.schedulers[r.RepoName] = scheduler
+	s.schedulers[r.FullName()] = scheduler
+	s.entries[r.FullName()] = r
 	scheduler.StartAsync()
 }
 
+// schedulerRemove forgets a repository's scheduler and returns it for the caller to stop; callers must hold s.mu
+func (s *Scheduler) schedulerRemove(scheduler *gocron.Scheduler, repoKey string) *gocron.Scheduler {
+	delete(s.schedulers, repoKey)
+	delete(s.entries, repoKey)
+	logger.LogInfo(fmt.Sprintf("Monitoring stopped for repository: %s", repoKey))
+	return scheduler
+}
+
 func (s *Scheduler) monitorRepository(r domain.RepoData) {
 	ctx := context.Background()
+	// Issues are synced by a background job, which runs while the repository and its commits are synced
+	s.issueService.QueueIssueSync(ctx, r)
 	if err := s.monitorService.MonitorRepository(ctx, r); err != nil {
-		logger.LogError(fmt.Errorf("monitoring failed for repository name %s: %w", r.RepoName, err))
+		logger.LogError(fmt.Errorf("monitoring failed for repository %s: %w", r.FullName(), err))
 	}
 }
@@ -5,29 +5,86 @@ import (
 	"github-service/config"
 	"github-service/internal/adapters/github"
 	"github-service/internal/core/domain"
+	"time"
 
 	"github-service/internal/ports"
 
 	"log"
 )
 
-func SetupService(ctx context.Context, cfg config.Config, rData domain.RepoData, commitRepo ports.PostgresCommit, repositoryRepo ports.PostgresRepository, bs ports.BadgerImpl) (*CommitService, *RepositoryService, *MonitorService) {
+// Services bundles the core services the web handlers are built on
+type Services struct {
+	Commits      *CommitService
+	Repositories *RepositoryService
+	Monitor      *MonitorService
+	Jobs         *JobService
+	Identities   *IdentityService
+	PullRequests *PullRequestService
+	Issues       *IssueService
+	Releases     *ReleaseService
+	Changelogs   *ChangelogService
+}
+
+func SetupService(ctx context.Context, cfg config.Config, rData domain.RepoData, storage ports.Storage) *Services {
 	ghClient := github.NewGithubClient(&cfg, ctx)
 	ghService := NewGithubService(&cfg, ctx, ghClient)
 
 	// Initialize service instances
-	commitService := NewCommitService(commitRepo, &cfg, ghService)
-	repositoryService := NewRepositoryService(repositoryRepo, *commitService, &cfg, bs, ghService)
+	commitService := NewCommitService(storage.Commits, storage.Backfills, &cfg, ghService)
+	repositoryService := NewRepositoryService(storage.Repositories, *commitService, &cfg, storage.Badger, ghService)
+
+	// Initialize the background job runner; default to 4 workers and 3 attempts per job
+	workers := cfg.SYNC_WORKERS
+	if workers <= 0 {
+		workers = 4
+	}
+	maxAttempts := cfg.JOB_MAX_ATTEMPTS
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	jobService := NewJobService(storage.Jobs, workers, maxAttempts, time.Minute)
 
 	// Initialize the commit monitor service
-	monitorService := NewMonitorService(commitService, repositoryService, 5, 2, ghService)
+	monitorService := NewMonitorService(commitService, repositoryService, 5, 2, ghService, jobService, storage.Backfills, &cfg)
+
+	// Initialize the identity service, which resolves the authors of saved commits
+	identityService := NewIdentityService(storage.Identities, jobService)
+
+	// Initialize the pull request service, whose sync jobs are queued after commit syncs
+	pullRequestService := NewPullRequestService(storage.PullRequests, ghService, jobService)
+
+	// Initialize the issue service, whose sync jobs the scheduler queues alongside repository monitoring
+	issueService := NewIssueService(storage.Issues, ghService, jobService, &cfg)
+
+	// Initialize the release service, whose sync jobs are queued after commit syncs
+	releaseService := NewReleaseService(storage.Releases, storage.Commits, ghService, jobService)
+
+	// Initialize the changelog service, which only reads what the syncs stored, queuing a pull of what it is missing
+	changelogService := NewChangelogService(storage.Commits, storage.Releases, jobService)
+
+	jobService.Start(ctx)
+
+	// Parse the messages of commits stored before they were parsed on ingestion
+	if _, err := jobService.Enqueue(ctx, domain.JobParseCommitMessages, "", "", nil); err != nil {
+		log.Printf("Failed to queue commit message parsing: %v", err)
+	}
 
 	// Seed the database with initial data starting from the defined date
 	if err := monitorService.MonitorRepository(ctx, rData); err != nil {
 		log.Printf("Failed to add initial repository: %v", err)
 	}
 
-	go NewScheduler(monitorService, &cfg, bs).ScheduleMonitoring()
+	go NewScheduler(monitorService, issueService, &cfg, storage.Badger).ScheduleMonitoring(ctx)
 
-	return commitService, repositoryService, monitorService
+	return &Services{
+		Commits:      commitService,
+		Repositories: repositoryService,
+		Monitor:      monitorService,
+		Jobs:         jobService,
+		Identities:   identityService,
+		PullRequests: pullRequestService,
+		Issues:       issueService,
+		Releases:     releaseService,
+		Changelogs:   changelogService,
+	}
 }
@@ -1,6 +1,9 @@
 package ports
 
-import "github-service/internal/core/domain"
+import (
+	"context"
+	"github-service/internal/core/domain"
+)
 
 // BadgerImpl defines the interface for interacting with the Badger key-value store
 type BadgerImpl interface {
@@ -12,8 +15,17 @@ type BadgerImpl interface {
 	// Returns an empty slice and error if the key does not exist or retrieval fails
 	GetRepoArray(repoKey string) ([]domain.RepoData, error)
 
-	// UpdateRepoArray updates a specific RepoData within the array stored under repoKey
-	// It fetches the array, modifies the target RepoData, and saves it back to the Badger store
+	// UpdateRepoArray updates a specific RepoData within the array stored under repoKey, matching on owner and repository name
+	// It fetches the array, modifies the target RepoData (or appends it), and saves it back to the Badger store in one transaction,
+	// so concurrent updates and removals are never lost
 	// Returns an error if the update operation fails
 	UpdateRepoArray(repoKey string, updatedRepo domain.RepoData) error
+
+	// RemoveFromRepoArray removes the RepoData with the same owner and repository name from the array stored under repoKey
+	// Returns an error if the removal fails; removing a repository that is not in the array is not an error
+	RemoveFromRepoArray(repoKey string, removedRepo domain.RepoData) error
+
+	// WatchRepoArray calls onChange with the new array whenever the array stored under repoKey is saved
+	// It blocks until ctx is cancelled and returns an error if the subscription fails
+	WatchRepoArray(ctx context.Context, repoKey string, onChange func([]domain.RepoData)) error
 }
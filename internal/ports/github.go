@@ -11,7 +11,55 @@ type GithubImpl interface {
 	// Returns a Repository domain object and an error if the request fails
 	FetchRepository(ctx context.Context, owner, repoName string) (*domain.Repository, error)
 
-	// FetchCommit fetches a list of commits for the specified owner and repo starting from the 'since' time
-	// Returns a slice of Commit domain objects and an error if the request fails
-	FetchCommit(ctx context.Context, owner, repo string, since time.Time) ([]domain.Commit, error)
+	// ForgetRepository drops what is remembered about the last FetchRepository of the given owner and repoName,
+	// so the next one returns the repository in full rather than domain.ErrNotModified
+	ForgetRepository(owner, repoName string)
+
+	// FetchCommit fetches every page of commits for the specified owner and repo matching opts
+	// Each page is passed to handle as soon as it is fetched; an error from handle stops the fetch
+	// Returns an error if a request fails
+	FetchCommit(ctx context.Context, owner, repo string, opts domain.CommitFetchOptions, handle func(page int, commits []domain.Commit) error) error
+
+	// ForgetCommits drops what is remembered about the previous FetchCommit calls for the specified owner and repo,
+	// so the next ones return every matching commit rather than domain.ErrNotModified
+	ForgetCommits(owner, repo string)
+
+	// FetchCommitDetail fetches a single commit with its change stats and the files it changed
+	// Returns domain.ErrCommitUnavailable if GitHub does not serve the commit, or an error if the request fails
+	FetchCommitDetail(ctx context.Context, owner, repo, sha string) (*domain.CommitDetail, error)
+
+	// FetchPullRequests fetches the pull requests of the specified owner and repo updated since the given time; a zero since fetches them all
+	// They are passed to handle at once, least recently updated first; an error from handle makes the next fetch start over
+	// Returns domain.ErrNotModified if nothing changed since the last fetch, or an error if a request fails
+	FetchPullRequests(ctx context.Context, owner, repo string, since time.Time, handle func(pulls []domain.PullRequest) error) error
+
+	// FetchPullReviewStats fetches the reviews of a pull request and summarises them
+	// Returns an error if the request fails
+	FetchPullReviewStats(ctx context.Context, owner, repo string, number int) (domain.PullReviewStats, error)
+
+	// FetchIssues fetches the issues of the specified owner and repo updated since the given time, leaving pull requests out;
+	// a zero since fetches them all. They are passed to handle at once, least recently updated first; an error from handle
+	// makes the next fetch start over
+	// Returns domain.ErrNotModified if nothing changed since the last fetch, or an error if a request fails
+	FetchIssues(ctx context.Context, owner, repo string, since time.Time, handle func(issues []domain.Issue) error) error
+
+	// FetchIssueTransitions fetches the closes and reopens of the issues of the specified owner and repo made since the given time.
+	// They are passed to handle at once, oldest first; an error from handle makes the next fetch start over
+	// Returns domain.ErrNotModified if nothing changed since the last fetch, or an error if a request fails
+	FetchIssueTransitions(ctx context.Context, owner, repo string, since time.Time, handle func(transitions []domain.IssueTransition) error) error
+
+	// FetchReleases fetches every published release of the specified owner and repo; drafts are left out
+	// Returns an error if a request fails
+	FetchReleases(ctx context.Context, owner, repo string) ([]domain.Release, error)
+
+	// FetchTags fetches every tag of the specified owner and repo with the commit it points to
+	// Returns an error if a request fails
+	FetchTags(ctx context.Context, owner, repo string) ([]domain.Tag, error)
+
+	// FetchBranches fetches the names of every branch of the specified owner and repo
+	// Returns an error if a request fails
+	FetchBranches(ctx context.Context, owner, repo string) ([]string, error)
+
+	// RateLimit returns the GitHub API quota currently available, as reported by the most recent response
+	RateLimit() domain.RateLimit
 }
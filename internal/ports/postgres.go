@@ -3,48 +3,303 @@ package ports
 import (
 	"context"
 	"github-service/internal/core/domain"
+	"time"
 )
 
 // PostgresCommit defines the interface for commit data operations in a PostgreSQL database.
 type PostgresCommit interface {
-	// SaveCommit saves a commit to the database.
+	// SaveCommit saves a commit to the database, updating it if a commit with the same owner, repository and hash exists.
 	// It returns an error if the save operation fails.
 	SaveCommit(ctx context.Context, commit *domain.Commit) error
 
-	// GetCommits retrieves a list of commits based on the repository URL, page number, and limit.
-	// The page and limit parameters control pagination.
+	// SaveCommits saves a batch of commits in chunked inserts, updating any that already exist.
+	// It returns an error if the save operation fails.
+	SaveCommits(ctx context.Context, commits []domain.Commit) error
+
+	// GetCommits retrieves a list of commits based on the repository owner and name, filter, page number, and limit.
+	// The page and limit parameters control pagination, and the filter narrows down and orders the commits.
 	// It returns a slice of commits and an error if the query fails.
-	GetCommits(ctx context.Context, repositoryURL string, page, limit int) ([]domain.Commit, error)
+	GetCommits(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, page, limit int) ([]domain.Commit, error)
+
+	// GetCommitsByCursor retrieves up to limit commits matching the filter after the cursor (before it for a backward cursor),
+	// starting at the beginning of the listing when the cursor is nil. Keyset pagination keeps pages stable while commits arrive.
+	// It returns the commits in listing order, whether more lie beyond them, and an error if the query fails.
+	GetCommitsByCursor(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, cursor *domain.CommitCursor, limit int) ([]domain.Commit, bool, error)
 
-	// GetTotalCommits retrieves the total number of commits for the specified repository name.
+	// GetTotalCommits retrieves the total number of commits for the specified repository owner and name that match the filter.
 	// It returns the total count of commits and an error if the query fails.
-	GetTotalCommits(ctx context.Context, repositoryName string) (int64, error)
+	GetTotalCommits(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter) (int64, error)
 
-	// DeleteAllCommits deletes all commits for the given repository name.
+	// DeleteAllCommits deletes all commits for the given repository owner and name.
 	// It returns a boolean indicating success and an error if the delete operation fails.
-	DeleteAllCommits(ctx context.Context, repositoryName string) (bool, error)
+	DeleteAllCommits(ctx context.Context, owner, repositoryName string) (bool, error)
 
 	// GetLastCommitByRepositoryName retrieves the most recent commit for the specified repository based on the commit date.
 	// It returns the latest commit and an error if the query fails or if no commits are found.
-	GetLastCommitByRepositoryName(ctx context.Context, repoName string) (*domain.Commit, error)
+	GetLastCommitByRepositoryName(ctx context.Context, owner, repoName string) (*domain.Commit, error)
+
+	// GetLastBranchCommit retrieves the most recent commit of the specified repository recorded on a branch, based on the commit date.
+	// It returns nil if no commit of the branch is stored, and an error if the query fails.
+	GetLastBranchCommit(ctx context.Context, owner, repoName, branch string) (*domain.Commit, error)
+
+	// SaveCommitBranches records that the stored commits with the given hashes are on a branch of the repository.
+	// It returns an error if the save operation fails.
+	SaveCommitBranches(ctx context.Context, owner, repositoryName, branch string, hashes []string) error
+
+	// AssignUnbranchedCommits records every commit of the repository that is not on any branch yet as being on the given one.
+	// It returns the number of commits recorded and an error if the insert fails.
+	AssignUnbranchedCommits(ctx context.Context, owner, repositoryName, branch string) (int64, error)
+
+	// GetCommit retrieves a commit by its repository owner, repository name and hash.
+	// It returns nil if the commit is not stored, and an error if the query fails.
+	GetCommit(ctx context.Context, owner, repositoryName, hash string) (*domain.Commit, error)
+
+	// GetCommitFiles retrieves the files changed by a commit, as recorded by enrichment.
+	// It returns an error if the query fails.
+	GetCommitFiles(ctx context.Context, owner, repositoryName, hash string) ([]domain.CommitFile, error)
+
+	// GetUnenrichedCommits retrieves up to limit commits of a repository that have not been enriched yet, newest first.
+	// It returns an error if the query fails.
+	GetUnenrichedCommits(ctx context.Context, owner, repositoryName string, limit int) ([]domain.Commit, error)
+
+	// SaveCommitDetail records the change stats of a stored commit and replaces the files it changed.
+	// It returns an error if the commit is not stored or the save fails.
+	SaveCommitDetail(ctx context.Context, detail *domain.CommitDetail) error
+
+	// GetCommitActivity counts the commits of a repository made between since and until per bucket of the interval,
+	// and per author identity when byAuthor is set, oldest bucket first. Buckets without commits are left out.
+	// It returns an error if the query fails.
+	GetCommitActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time, byAuthor bool) ([]domain.ActivityCount, error)
+
+	// EachAuthorDate calls fn with the author date of every commit of a repository that matches the filter.
+	// It returns the first error fn returns, or an error if the query fails.
+	EachAuthorDate(ctx context.Context, owner, repositoryName string, filter domain.CommitFilter, fn func(date time.Time) error) error
+
+	// GetUnparsedCommits retrieves up to limit commits of any repository whose message has not been parsed,
+	// ordered by owner, repository and hash, starting after the commit after when it is not nil.
+	// It returns an error if the query fails.
+	GetUnparsedCommits(ctx context.Context, after *domain.Commit, limit int) ([]domain.Commit, error)
+
+	// SaveCommitMessageParts records the parsed Conventional Commits parts and trailers of stored commits.
+	// It returns an error if the update fails.
+	SaveCommitMessageParts(ctx context.Context, commits []domain.Commit) error
+
+	// GetMissingParents counts the commits of a repository stored before their parents were, along with the commit dates
+	// of the oldest and newest of them.
+	// It returns an error if the query fails.
+	GetMissingParents(ctx context.Context, owner, repositoryName string) (domain.MissingParents, error)
+
+	// GetCommitTypeActivity counts the commits of a repository made between since and until per bucket of the interval
+	// and Conventional Commits type, oldest bucket first, along with the number of breaking changes.
+	// It returns an error if the query fails.
+	GetCommitTypeActivity(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) ([]domain.CommitTypeCount, error)
 }
 
 // PostgresRepository defines the interface for repository data operations in a PostgreSQL database.
 type PostgresRepository interface {
-	// SaveRepository saves a repository to the database, creating a new entry if it doesn't exist or updating an existing one.
+	// SaveRepository saves a repository to the database, creating a new entry if no repository with the same owner and name exists or updating the existing one.
 	// It returns an error if the save or update operation fails.
 	SaveRepository(ctx context.Context, repo *domain.Repository) error
 
 	// GetTopNCommitAuthors retrieves the top N commit authors for the specified repository, with pagination support.
-	// It groups authors by name, counts their commits, and orders the results by the count in descending order.
-	// It returns a slice of top authors with their commit counts and an error if the query fails.
-	GetTopNCommitAuthors(ctx context.Context, repository string, page, limit int) (domain.TopAuthorsCount, error)
+	// It groups commits made between since and until by resolved author identity, counts them,
+	// and orders the results by the count in descending order. A zero since or until leaves that end of the window open.
+	// It returns a slice of top authors with their commit, date and line stats and an error if the query fails.
+	GetTopNCommitAuthors(ctx context.Context, owner, repository string, since, until time.Time, page, limit int) (domain.TopAuthorsCount, error)
+
+	// GetDirectoryContributions retrieves the lines and files each author identity changed under each top-level directory,
+	// from the changed files of the commits made between since and until. A zero since or until leaves that end open.
+	// It returns an error if the query fails.
+	GetDirectoryContributions(ctx context.Context, owner, repository string, since, until time.Time) ([]domain.DirectoryContribution, error)
 
-	// GetRepositoryByName retrieves a repository based on its name.
+	// GetRepositoryByName retrieves a repository based on its owner and name.
 	// It returns the repository model and an error if the query fails or if the repository is not found.
-	GetRepositoryByName(ctx context.Context, repository string) (domain.Repository, error)
+	GetRepositoryByName(ctx context.Context, owner, repository string) (domain.Repository, error)
 
 	// DeleteRepository deletes a repository with the given name and owner.
 	// It returns a boolean indicating whether the deletion was successful and an error if the delete operation fails.
 	DeleteRepository(ctx context.Context, owner, repositoryName string) (bool, error)
 }
+
+// PostgresJob defines the interface for persisting background jobs in a PostgreSQL database.
+type PostgresJob interface {
+	// CreateJob stores a new job and fills in its ID.
+	// It returns an error if the insert fails.
+	CreateJob(ctx context.Context, job *domain.Job) error
+
+	// UpdateJob saves the current state of an existing job.
+	// It returns an error if the update fails.
+	UpdateJob(ctx context.Context, job *domain.Job) error
+
+	// GetJob retrieves a job by its ID.
+	// It returns an error if the query fails or if the job is not found.
+	GetJob(ctx context.Context, id uint) (domain.Job, error)
+
+	// ListJobs retrieves jobs newest first, only those in the given status when it is not empty, with pagination support.
+	// It returns the page of jobs, the total number of matching jobs and an error if the query fails.
+	ListJobs(ctx context.Context, status domain.JobStatus, page, limit int) ([]domain.Job, int64, error)
+
+	// FindActiveJob retrieves the queued or running job of the given type for a repository.
+	// It returns nil if there is none, and an error if the query fails.
+	FindActiveJob(ctx context.Context, jobType, owner, repositoryName string) (*domain.Job, error)
+
+	// ClaimDueJobs marks up to limit queued jobs that are due at now as running and returns them.
+	// It returns an error if the jobs cannot be claimed.
+	ClaimDueJobs(ctx context.Context, now time.Time, limit int) ([]domain.Job, error)
+
+	// RequeueRunningJobs puts jobs that were interrupted while running back in the queue.
+	// It returns the number of requeued jobs and an error if the update fails.
+	RequeueRunningJobs(ctx context.Context) (int64, error)
+}
+
+// PostgresBackfill defines the interface for persisting historical backfill checkpoints in a PostgreSQL database.
+type PostgresBackfill interface {
+	// SaveBackfill saves the current state of a backfill, creating it if it is new.
+	// It returns an error if the save fails.
+	SaveBackfill(ctx context.Context, backfill *domain.Backfill) error
+
+	// GetBackfill retrieves the backfill of the specified repository.
+	// It returns nil if there is none, and an error if the query fails.
+	GetBackfill(ctx context.Context, owner, repositoryName string) (*domain.Backfill, error)
+
+	// DeleteBackfill deletes the backfill of the specified repository, if any.
+	// It returns an error if the delete operation fails.
+	DeleteBackfill(ctx context.Context, owner, repositoryName string) error
+}
+
+// PostgresIdentity defines the interface for managing author identities and attributing commits to them in a PostgreSQL database.
+type PostgresIdentity interface {
+	// CreateIdentity stores a new identity along with its aliases.
+	// It returns an error if the insert fails, e.g. because another identity has the same key.
+	CreateIdentity(ctx context.Context, identity *domain.Identity) error
+
+	// UpdateIdentity saves the name, email, login and key of an existing identity.
+	// It returns an error if the update fails.
+	UpdateIdentity(ctx context.Context, identity *domain.Identity) error
+
+	// DeleteIdentity deletes an identity and its aliases.
+	// It returns false if there is no such identity, and an error if the delete operation fails.
+	DeleteIdentity(ctx context.Context, id uint) (bool, error)
+
+	// GetIdentity retrieves an identity with its aliases by ID.
+	// It returns nil if there is none, and an error if the query fails.
+	GetIdentity(ctx context.Context, id uint) (*domain.Identity, error)
+
+	// FindIdentityByEmail retrieves the identity with the given email, case-insensitively, with its aliases.
+	// It returns nil if there is none, and an error if the query fails.
+	FindIdentityByEmail(ctx context.Context, email string) (*domain.Identity, error)
+
+	// ListIdentities retrieves identities with their aliases, with pagination support.
+	// It returns the page of identities, the total number of identities and an error if the query fails.
+	ListIdentities(ctx context.Context, page, limit int) ([]domain.Identity, int64, error)
+
+	// GetAllIdentities retrieves every identity with its aliases.
+	// It returns an error if the query fails.
+	GetAllIdentities(ctx context.Context) ([]domain.Identity, error)
+
+	// GetIdentitiesByKeys retrieves the identities with the given keys.
+	// It returns an error if the query fails.
+	GetIdentitiesByKeys(ctx context.Context, keys []string) ([]domain.Identity, error)
+
+	// AddAlias stores a new alias of an identity.
+	// It returns an error if the insert fails.
+	AddAlias(ctx context.Context, alias *domain.IdentityAlias) error
+
+	// DeleteAlias deletes an alias of an identity.
+	// It returns false if the identity has no such alias, and an error if the delete operation fails.
+	DeleteAlias(ctx context.Context, identityID, aliasID uint) (bool, error)
+
+	// GetAuthorTuples retrieves the distinct author fields and resolved identities of the commits of a repository,
+	// or of every repository when owner is empty, optionally only of commits not attributed to an identity yet.
+	// It returns an error if the query fails.
+	GetAuthorTuples(ctx context.Context, owner, repositoryName string, unresolvedOnly bool) ([]domain.AuthorTuple, error)
+
+	// GetEmailLogins retrieves the GitHub login each lowercased email has committed with.
+	// It returns an error if the query fails.
+	GetEmailLogins(ctx context.Context) (map[string]string, error)
+
+	// SetIdentity attributes the commits matching tuple, in a repository or every repository when owner is empty, to identity.
+	// It returns the number of commits updated and an error if the update fails.
+	SetIdentity(ctx context.Context, owner, repositoryName string, tuple domain.AuthorTuple, identity string) (int64, error)
+}
+
+// PostgresPullRequest defines the interface for pull request data operations in a PostgreSQL database.
+type PostgresPullRequest interface {
+	// SavePullRequests saves a batch of pull requests, updating any with the same owner, repository and number.
+	// It returns an error if the save operation fails.
+	SavePullRequests(ctx context.Context, pulls []domain.PullRequest) error
+
+	// ListPullRequests retrieves the pull requests of a repository matching the filter, newest first, with pagination support.
+	// It returns the page of pull requests, the total number matching the filter and an error if the query fails.
+	ListPullRequests(ctx context.Context, owner, repositoryName string, filter domain.PullRequestFilter, page, limit int) ([]domain.PullRequest, int64, error)
+
+	// GetLatestPullRequestUpdate retrieves when the most recently updated pull request of a repository was last updated.
+	// It returns the zero time if there is none, and an error if the query fails.
+	GetLatestPullRequestUpdate(ctx context.Context, owner, repositoryName string) (time.Time, error)
+
+	// LinkMergeCommits records on the commits of a repository the number of the pull request that merged them.
+	// It returns the number of commits linked and an error if the update fails.
+	LinkMergeCommits(ctx context.Context, owner, repositoryName string) (int64, error)
+}
+
+// PostgresIssue defines the interface for issue data operations in a PostgreSQL database.
+type PostgresIssue interface {
+	// SaveIssues saves a batch of issues, updating any with the same owner, repository and number.
+	// It returns an error if the save operation fails.
+	SaveIssues(ctx context.Context, issues []domain.Issue) error
+
+	// SaveIssueTransitions saves a batch of issue transitions, skipping any already stored.
+	// It returns an error if the save operation fails.
+	SaveIssueTransitions(ctx context.Context, transitions []domain.IssueTransition) error
+
+	// ListIssues retrieves the issues of a repository matching the filter, newest first, with pagination support.
+	// It returns the page of issues, the total number matching the filter and an error if the query fails.
+	ListIssues(ctx context.Context, owner, repositoryName string, filter domain.IssueFilter, page, limit int) ([]domain.Issue, int64, error)
+
+	// GetIssue retrieves an issue of a repository by number along with its recorded transitions.
+	// It returns nil if there is none, and an error if the query fails.
+	GetIssue(ctx context.Context, owner, repositoryName string, number int) (*domain.Issue, error)
+
+	// GetLatestIssueUpdate retrieves when the most recently updated issue of a repository was last updated.
+	// It returns the zero time if there is none, and an error if the query fails.
+	GetLatestIssueUpdate(ctx context.Context, owner, repositoryName string) (time.Time, error)
+
+	// GetLatestIssueTransition retrieves when the most recent recorded issue transition of a repository was made.
+	// It returns the zero time if there is none, and an error if the query fails.
+	GetLatestIssueTransition(ctx context.Context, owner, repositoryName string) (time.Time, error)
+
+	// GetIssueRates counts the issues of a repository opened and closed between since and until per bucket of the interval.
+	// Empty buckets are left out. It returns an error if the query fails.
+	GetIssueRates(ctx context.Context, owner, repositoryName string, interval domain.ActivityInterval, since, until time.Time) ([]domain.IssueRateCount, error)
+
+	// CountIssuesBefore counts the issues of a repository opened before the given time and those last closed before it.
+	// It returns an error if the query fails.
+	CountIssuesBefore(ctx context.Context, owner, repositoryName string, before time.Time) (opened, closed int64, err error)
+}
+
+// PostgresRelease defines the interface for release and tag data operations in a PostgreSQL database.
+type PostgresRelease interface {
+	// SaveTags saves a batch of tags, updating the commit of any with the same owner, repository and name.
+	// It returns an error if the save operation fails.
+	SaveTags(ctx context.Context, tags []domain.Tag) error
+
+	// SaveReleases saves a batch of releases, updating any with the same owner, repository and tag.
+	// It returns an error if the save operation fails.
+	SaveReleases(ctx context.Context, releases []domain.Release) error
+
+	// GetTagCommits retrieves the commit each stored tag of a repository points to, keyed by tag name.
+	// It returns an error if the query fails.
+	GetTagCommits(ctx context.Context, owner, repositoryName string) (map[string]string, error)
+
+	// GetReleases retrieves every release of a repository.
+	// It returns an error if the query fails.
+	GetReleases(ctx context.Context, owner, repositoryName string) ([]domain.Release, error)
+
+	// EachCommitNode calls fn with the hash, parents and release of every stored commit of a repository.
+	// It returns the first error from fn, or an error if the query fails.
+	EachCommitNode(ctx context.Context, owner, repositoryName string, fn func(node domain.CommitNode) error) error
+
+	// SetCommitReleases records tag as the first release containing each of the given commits of a repository.
+	// It returns an error if the update fails.
+	SetCommitReleases(ctx context.Context, owner, repositoryName, tag string, hashes []string) error
+}
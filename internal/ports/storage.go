@@ -0,0 +1,14 @@
+package ports
+
+// Storage bundles the storage ports the services are built on
+type Storage struct {
+	Commits      PostgresCommit
+	Repositories PostgresRepository
+	Jobs         PostgresJob
+	Backfills    PostgresBackfill
+	Identities   PostgresIdentity
+	PullRequests PostgresPullRequest
+	Issues       PostgresIssue
+	Releases     PostgresRelease
+	Badger       BadgerImpl
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/pagination"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangelogHandler handles HTTP requests for the changelogs of monitored repositories
+type ChangelogHandler struct {
+	changelogService *service.ChangelogService
+}
+
+// NewChangelogHandler creates a new instance of ChangelogHandler with the given service
+func NewChangelogHandler(changelogService *service.ChangelogService) *ChangelogHandler {
+	return &ChangelogHandler{changelogService: changelogService}
+}
+
+// GetChangelog retrieves the changes made to a repository between the from and to refs, each a tag, commit SHA or date.
+// The format query parameter picks JSON, the default, or Markdown.
+func (h *ChangelogHandler) GetChangelog(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	format := domain.ChangelogFormat(c.DefaultQuery("format", string(domain.ChangelogJSON)))
+	if format != domain.ChangelogJSON && format != domain.ChangelogMarkdown {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid format, use json or markdown")
+		return
+	}
+
+	changelog, err := h.changelogService.GetChangelog(c, owner, repo, c.Query("from"), c.Query("to"))
+	if errors.Is(err, domain.ErrRefNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrHistoryIncomplete) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"statusCode": http.StatusServiceUnavailable, "message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to build changelog"})
+		return
+	}
+
+	if format == domain.ChangelogMarkdown {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(changelog.Markdown()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": changelog})
+}
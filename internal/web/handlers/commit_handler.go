@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"github-service/internal/core/domain"
 	"github-service/internal/core/service"
 	"github-service/pkg/pagination"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,8 +26,11 @@ func NewCommitHandler(commitService *service.CommitService, repositoryService *s
 	}
 }
 
-// GetCommits retrieves commits for a given repository and returns them as a paginated response
+// GetCommits retrieves commits for a given repository and returns them as a paginated response.
+// The author, email, identity, since, until, message_contains, release and branch query parameters filter the commits and sort orders them.
+// Pages are numbered by the page parameter, or follow the cursors handed out when a cursor parameter is given.
 func (h *CommitHandler) GetCommits(c *gin.Context) {
+	owner := c.Param("owner")
 	repo := c.Param("repo")
 
 	// Parse pagination parameters from the query string
@@ -35,15 +40,28 @@ func (h *CommitHandler) GetCommits(c *gin.Context) {
 		return
 	}
 
+	// Parse the filters from the query string
+	filter, err := parseCommitFilter(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Retrieve the repository details
-	url, err := h.repositoryService.GetRepository(c, repo)
+	url, err := h.repositoryService.GetRepository(c, owner, repo)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
 		return
 	}
 
+	// A cursor parameter, even an empty one that starts at the beginning, switches to cursor pagination
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.getCommitsByCursor(c, url.Owner, url.Name, filter, cursor, limit)
+		return
+	}
+
 	// Retrieve the total number of commits for the repository
-	totalCommits, err := h.commitService.GetCommitCount(c, url.Name)
+	totalCommits, err := h.commitService.GetCommitCount(c, url.Owner, url.Name, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve total commits"})
 		return
@@ -57,7 +75,7 @@ func (h *CommitHandler) GetCommits(c *gin.Context) {
 	}
 
 	// Retrieve the commits for the requested page and limit
-	commits, err := h.commitService.GetPaginatedCommits(c, url.Name, page, limit)
+	commits, err := h.commitService.GetPaginatedCommits(c, url.Owner, url.Name, filter, page, limit)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": "No commits found"})
 		return
@@ -74,8 +92,90 @@ func (h *CommitHandler) GetCommits(c *gin.Context) {
 	})
 }
 
-// GetTopNCommitAuthors retrieves the top N commit authors and returns them as JSON
+// getCommitsByCursor responds with the page of commits that starts at cursor, with links to the pages around it
+func (h *CommitHandler) getCommitsByCursor(c *gin.Context, owner, repo string, filter domain.CommitFilter, cursor string, limit int) {
+	response, err := h.commitService.GetCommitsByCursor(c, owner, repo, filter, cursor, limit)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve commits"})
+		return
+	}
+
+	pagination.SetCursorLinks(c, response.NextCursor, response.PrevCursor)
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": response})
+}
+
+// parseCommitFilter reads the commit filters from the query string
+func parseCommitFilter(c *gin.Context) (domain.CommitFilter, error) {
+	filter := domain.CommitFilter{
+		Author:          c.Query("author"),
+		Email:           c.Query("email"),
+		Identity:        c.Query("identity"),
+		MessageContains: c.Query("message_contains"),
+		Release:         c.Query("release"),
+		Branch:          c.Query("branch"),
+		Sort:            domain.CommitSort(c.Query("sort")),
+	}
+
+	switch filter.Sort {
+	case "", domain.CommitSortDate, domain.CommitSortDateDesc:
+	default:
+		return domain.CommitFilter{}, errors.New("Invalid sort, use date or -date")
+	}
+
+	var err error
+	if filter.Since, filter.Until, err = parseTimeRange(c); err != nil {
+		return domain.CommitFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseTimeRange reads the optional RFC3339 since and until query parameters
+func parseTimeRange(c *gin.Context) (since, until time.Time, err error) {
+	if value := c.Query("since"); value != "" {
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid since date")
+		}
+	}
+	if value := c.Query("until"); value != "" {
+		if until, err = time.Parse(time.RFC3339, value); err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid until date")
+		}
+	}
+	if !since.IsZero() && !until.IsZero() && until.Before(since) {
+		return time.Time{}, time.Time{}, errors.New("Until date must not be before since date")
+	}
+	return since, until, nil
+}
+
+// GetCommit retrieves a single commit with its change stats and the files it changed
+func (h *CommitHandler) GetCommit(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	sha := c.Param("sha")
+
+	// Retrieve the commit, enriching it first if it has not been yet
+	commit, err := h.commitService.GetCommit(c, owner, repo, sha)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve commit"})
+		return
+	}
+	if commit == nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": "Commit not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": commit})
+}
+
+// GetTopNCommitAuthors retrieves the top N commit authors, optionally of commits made between the since and until
+// query parameters, and returns them as JSON
 func (h *CommitHandler) GetTopNCommitAuthors(c *gin.Context) {
+	owner := c.Param("owner")
 	repo := c.Param("repo")
 
 	// Parse the "n" parameter to determine the number of top authors
@@ -91,8 +191,15 @@ func (h *CommitHandler) GetTopNCommitAuthors(c *gin.Context) {
 		return
 	}
 
+	// Parse the optional window to rank commits in
+	since, until, err := parseTimeRange(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Retrieve the top N commit authors from the repository service
-	authors, err := h.repositoryService.GetTopNCommitAuthors(c, repo, n, page, limit)
+	authors, err := h.repositoryService.GetTopNCommitAuthors(c, owner, repo, since, until, n, page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Server error"})
 		return
@@ -106,10 +213,140 @@ func (h *CommitHandler) GetTopNCommitAuthors(c *gin.Context) {
 	c.JSON(http.StatusOK, authors)
 }
 
+// GetCommitActivity returns the number of commits per day, week or month, chosen by the interval query parameter,
+// between the optional since and until dates. With by_author=true the counts are also broken down by author.
+func (h *CommitHandler) GetCommitActivity(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	interval := domain.ActivityInterval(c.DefaultQuery("interval", string(domain.ActivityWeek)))
+	if !interval.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid interval; use day, week or month"})
+		return
+	}
+
+	since, until, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	byAuthor, err := strconv.ParseBool(c.DefaultQuery("by_author", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid by_author value"})
+		return
+	}
+
+	activity, err := h.commitService.GetCommitActivity(c, owner, repo, interval, since, until, byAuthor)
+	if errors.Is(err, domain.ErrTooManyBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve commit activity"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": activity})
+}
+
+// GetPunchcard returns a 7x24 matrix of commit counts by weekday and hour in the timezone named by the tz query parameter,
+// UTC by default. The commit filter query parameters, such as author or identity, narrow the commits counted.
+func (h *CommitHandler) GetPunchcard(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	location, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid timezone"})
+		return
+	}
+
+	filter, err := parseCommitFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	punchcard, err := h.commitService.GetPunchcard(c, owner, repo, filter, location)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve punch card"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": punchcard})
+}
+
+// GetBusFactor returns the smallest number of authors who account for the threshold percentage, 50 by default,
+// of a repository's commits or changed lines, chosen by the metric query parameter, and of each top-level directory.
+func (h *CommitHandler) GetBusFactor(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "50"), 64)
+	if err != nil || threshold <= 0 || threshold > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid threshold; use a percentage above 0 and up to 100"})
+		return
+	}
+
+	metric := domain.BusFactorMetric(c.Query("metric"))
+	switch metric {
+	case "", domain.BusFactorCommits, domain.BusFactorLines:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid metric; use commits or lines"})
+		return
+	}
+
+	since, until, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	report, err := h.repositoryService.GetBusFactor(c, owner, repo, since, until, threshold, metric)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to compute bus factor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": report})
+}
+
+// GetCommitTypeActivity returns the number of commits of each Conventional Commits type per day, week or month,
+// chosen by the interval query parameter, between the optional since and until dates
+func (h *CommitHandler) GetCommitTypeActivity(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	interval := domain.ActivityInterval(c.DefaultQuery("interval", string(domain.ActivityWeek)))
+	if !interval.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid interval; use day, week or month"})
+		return
+	}
+
+	since, until, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	activity, err := h.commitService.GetCommitTypeActivity(c, owner, repo, interval, since, until)
+	if errors.Is(err, domain.ErrTooManyBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve commit types"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": activity})
+}
+
 // ResetCollection removes all commits for a specific repository and returns a success message
 func (h *CommitHandler) ResetCollection(c *gin.Context) {
 	owner := c.Param("owner")
-	repoName := c.Query("repo")
+	repoName := c.Param("repo")
 
 	// Validate input
 	if owner == "" || repoName == "" {
@@ -118,7 +355,7 @@ func (h *CommitHandler) ResetCollection(c *gin.Context) {
 	}
 
 	// Remove all commits for the specified repository
-	ok, err := h.commitService.DeleteCommits(c, repoName)
+	ok, err := h.commitService.DeleteCommits(c, owner, repoName)
 	if !ok {
 		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": err.Error()})
 		return
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityHandler handles HTTP requests related to author identities and their aliases
+type IdentityHandler struct {
+	identityService *service.IdentityService
+}
+
+// NewIdentityHandler creates a new instance of IdentityHandler with the given service
+func NewIdentityHandler(identityService *service.IdentityService) *IdentityHandler {
+	return &IdentityHandler{identityService: identityService}
+}
+
+// ListIdentities retrieves identities with their aliases as a paginated response
+func (h *IdentityHandler) ListIdentities(c *gin.Context) {
+	// Parse pagination parameters from the query string
+	page, limit, err := pagination.ParsePaginationParams(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters")
+		return
+	}
+
+	identities, total, err := h.identityService.ListIdentities(c, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve identities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"data": domain.IdentitiesResponse{
+			CurrentPage: page,
+			TotalPages:  int((total + int64(limit) - 1) / int64(limit)),
+			Identities:  identities,
+		},
+	})
+}
+
+// CreateIdentity creates an identity, optionally with aliases, from the JSON body
+func (h *IdentityHandler) CreateIdentity(c *gin.Context) {
+	var identity domain.Identity
+	if err := c.ShouldBindJSON(&identity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid identity"})
+		return
+	}
+
+	if err := h.identityService.CreateIdentity(c, &identity); err != nil {
+		respondWithIdentityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"statusCode": http.StatusCreated, "data": identity})
+}
+
+// GetIdentity retrieves a single identity with its aliases
+func (h *IdentityHandler) GetIdentity(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid identity id")
+	if !ok {
+		return
+	}
+
+	identity, err := h.identityService.GetIdentity(c, id)
+	if err != nil {
+		respondWithIdentityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": identity})
+}
+
+// UpdateIdentity replaces the name, email and login of an identity from the JSON body
+func (h *IdentityHandler) UpdateIdentity(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid identity id")
+	if !ok {
+		return
+	}
+
+	var update domain.Identity
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid identity"})
+		return
+	}
+
+	identity, err := h.identityService.UpdateIdentity(c, id, update)
+	if err != nil {
+		respondWithIdentityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": identity})
+}
+
+// DeleteIdentity deletes an identity and its aliases
+func (h *IdentityHandler) DeleteIdentity(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid identity id")
+	if !ok {
+		return
+	}
+
+	if err := h.identityService.DeleteIdentity(c, id); err != nil {
+		respondWithIdentityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Identity deleted successfully"})
+}
+
+// AddAlias adds an alias from the JSON body to an identity
+func (h *IdentityHandler) AddAlias(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid identity id")
+	if !ok {
+		return
+	}
+
+	var alias domain.IdentityAlias
+	if err := c.ShouldBindJSON(&alias); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid alias"})
+		return
+	}
+
+	created, err := h.identityService.AddAlias(c, id, alias)
+	if err != nil {
+		respondWithIdentityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"statusCode": http.StatusCreated, "data": created})
+}
+
+// DeleteAlias removes an alias from an identity
+func (h *IdentityHandler) DeleteAlias(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid identity id")
+	if !ok {
+		return
+	}
+	aliasID, ok := parseIDParam(c, "aliasId", "Invalid alias id")
+	if !ok {
+		return
+	}
+
+	if err := h.identityService.DeleteAlias(c, id, aliasID); err != nil {
+		respondWithIdentityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Alias deleted successfully"})
+}
+
+// ImportMailmap merges a .mailmap file sent as the request body into the identities
+func (h *IdentityHandler) ImportMailmap(c *gin.Context) {
+	imported, err := h.identityService.ImportMailmap(c, c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": gin.H{"imported": imported}})
+}
+
+// parseIDParam parses a numeric path parameter, responding with a bad request and message when it is not one
+func parseIDParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": message})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondWithIdentityError maps identity service errors to HTTP responses
+func respondWithIdentityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrIdentityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+	case errors.Is(err, domain.ErrInvalidIdentity):
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": err.Error()})
+	}
+}
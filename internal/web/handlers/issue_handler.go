@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/pagination"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IssueHandler handles HTTP requests related to the issues of monitored repositories
+type IssueHandler struct {
+	issueService *service.IssueService
+}
+
+// NewIssueHandler creates a new instance of IssueHandler with the given service
+func NewIssueHandler(issueService *service.IssueService) *IssueHandler {
+	return &IssueHandler{issueService: issueService}
+}
+
+// ListIssues retrieves the issues of a repository, newest first, as a paginated response.
+// The state, label, assignee and author query parameters and a since/until window on the opening time narrow them down.
+func (h *IssueHandler) ListIssues(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	// Parse pagination parameters from the query string
+	page, limit, err := pagination.ParsePaginationParams(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters")
+		return
+	}
+
+	filter := domain.IssueFilter{
+		State:    domain.IssueState(c.Query("state")),
+		Label:    c.Query("label"),
+		Assignee: c.Query("assignee"),
+		Author:   c.Query("author"),
+	}
+	if filter.State != "" && filter.State != domain.IssueOpen && filter.State != domain.IssueClosed {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid state, expected open or closed"})
+		return
+	}
+	if filter.Since, filter.Until, err = parseTimeRange(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	issues, total, err := h.issueService.GetIssues(c, owner, repo, filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve issues"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"data": domain.IssuesResponse{
+			CurrentPage: page,
+			TotalPages:  int((total + int64(limit) - 1) / int64(limit)),
+			Issues:      issues,
+		},
+	})
+}
+
+// GetIssue retrieves a single issue with its history of openings, closes and reopens
+func (h *IssueHandler) GetIssue(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	number, ok := parseIDParam(c, "number", "Invalid issue number")
+	if !ok {
+		return
+	}
+
+	issue, err := h.issueService.GetIssue(c, owner, repo, int(number))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve issue"})
+		return
+	}
+	if issue == nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": "Issue not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": issue})
+}
+
+// GetIssueRates returns the number of issues opened and closed per week, or per day or month as chosen by the interval
+// query parameter, between the optional since and until dates, with the backlog and average time to close of each bucket
+func (h *IssueHandler) GetIssueRates(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	interval := domain.ActivityInterval(c.DefaultQuery("interval", string(domain.ActivityWeek)))
+	if !interval.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid interval; use day, week or month"})
+		return
+	}
+
+	since, until, err := parseTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	rates, err := h.issueService.GetIssueRates(c, owner, repo, interval, since, until)
+	if errors.Is(err, domain.ErrTooManyBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve issue rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": rates})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler handles HTTP requests related to background sync jobs
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler creates a new instance of JobHandler with the given service
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// ListJobs retrieves jobs, newest first, optionally filtered by status, as a paginated response
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	status := domain.JobStatus(c.Query("status"))
+	switch status {
+	case "", domain.JobQueued, domain.JobRunning, domain.JobSucceeded, domain.JobFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid job status"})
+		return
+	}
+
+	// Parse pagination parameters from the query string
+	page, limit, err := pagination.ParsePaginationParams(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters")
+		return
+	}
+
+	jobs, total, err := h.jobService.ListJobs(c, status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"data": domain.JobsResponse{
+			CurrentPage: page,
+			TotalPages:  int((total + int64(limit) - 1) / int64(limit)),
+			Jobs:        jobs,
+		},
+	})
+}
+
+// GetJob retrieves a single job by its ID
+func (h *JobHandler) GetJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid job id"})
+		return
+	}
+
+	job, err := h.jobService.GetJob(c, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": job})
+}
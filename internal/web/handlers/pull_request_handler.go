@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/pagination"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PullRequestHandler handles HTTP requests related to the pull requests of monitored repositories
+type PullRequestHandler struct {
+	pullRequestService *service.PullRequestService
+}
+
+// NewPullRequestHandler creates a new instance of PullRequestHandler with the given service
+func NewPullRequestHandler(pullRequestService *service.PullRequestService) *PullRequestHandler {
+	return &PullRequestHandler{pullRequestService: pullRequestService}
+}
+
+// ListPullRequests retrieves the pull requests of a repository, newest first, as a paginated response.
+// The state, author and base query parameters and a since/until window on the creation time narrow them down.
+func (h *PullRequestHandler) ListPullRequests(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	// Parse pagination parameters from the query string
+	page, limit, err := pagination.ParsePaginationParams(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters")
+		return
+	}
+
+	filter := domain.PullRequestFilter{
+		State:  domain.PullRequestState(c.Query("state")),
+		Author: c.Query("author"),
+		Base:   c.Query("base"),
+	}
+	switch filter.State {
+	case "", domain.PullRequestOpen, domain.PullRequestClosed, domain.PullRequestMerged:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid state, expected open, closed or merged"})
+		return
+	}
+	if filter.Since, filter.Until, err = parseTimeRange(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	pulls, total, err := h.pullRequestService.GetPullRequests(c, owner, repo, filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve pull requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"data": domain.PullRequestsResponse{
+			CurrentPage:  page,
+			TotalPages:   int((total + int64(limit) - 1) / int64(limit)),
+			PullRequests: pulls,
+		},
+	})
+}
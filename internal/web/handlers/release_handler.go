@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/pagination"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReleaseHandler handles HTTP requests related to the releases of monitored repositories
+type ReleaseHandler struct {
+	releaseService *service.ReleaseService
+}
+
+// NewReleaseHandler creates a new instance of ReleaseHandler with the given service
+func NewReleaseHandler(releaseService *service.ReleaseService) *ReleaseHandler {
+	return &ReleaseHandler{releaseService: releaseService}
+}
+
+// ListReleases retrieves the releases of a repository, most recent first, as a paginated response
+func (h *ReleaseHandler) ListReleases(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	// Parse pagination parameters from the query string
+	page, limit, err := pagination.ParsePaginationParams(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters")
+		return
+	}
+
+	releases, total, err := h.releaseService.GetReleases(c, owner, repo, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve releases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"statusCode": http.StatusOK,
+		"data": domain.ReleasesResponse{
+			CurrentPage: page,
+			TotalPages:  (total + limit - 1) / limit,
+			Releases:    releases,
+		},
+	})
+}
+
+// GetReleaseCommits retrieves the commits first shipped in a release, those between its tag and the previous release's,
+// as a paginated response. The commit filter query parameters narrow them down.
+func (h *ReleaseHandler) GetReleaseCommits(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	tag := c.Param("tag")
+
+	// Parse pagination parameters from the query string
+	page, limit, err := pagination.ParsePaginationParams(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, "Invalid pagination parameters")
+		return
+	}
+
+	filter, err := parseCommitFilter(c)
+	if err != nil {
+		pagination.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.releaseService.GetReleaseCommits(c, owner, repo, tag, filter, page, limit)
+	if errors.Is(err, domain.ErrReleaseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": "Release not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve release commits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": response})
+}
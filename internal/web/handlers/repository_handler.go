@@ -4,6 +4,9 @@ import (
 	"github-service/internal/core/domain"
 	"github-service/internal/core/service"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,11 +28,12 @@ func NewRepositoryHandler(repositoryService *service.RepositoryService, monitorS
 
 // FetchRepositoryData retrieves data for a given repository
 func (h *RepositoryHandler) FetchRepositoryData(c *gin.Context) {
-	// Get the repository name from the request parameters
+	// Get the repository owner and name from the request parameters
+	owner := c.Param("owner")
 	repo := c.Param("repo")
 
 	// Call the RepositoryService to fetch the repository data
-	repoData, err := h.repositoryService.GetRepository(c, repo)
+	repoData, err := h.repositoryService.GetRepository(c, owner, repo)
 	if err != nil {
 		// Return 404 Not Found if the repository is not found
 		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": err.Error()})
@@ -43,8 +47,10 @@ func (h *RepositoryHandler) FetchRepositoryData(c *gin.Context) {
 // AddRepositoryToMonitor adds a repository to the commit monitor
 func (h *RepositoryHandler) AddRepositoryToMonitor(c *gin.Context) {
 	owner := c.Param("owner")
-	repoName := c.Query("repo")
+	repoName := c.Param("repo")
 	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+	startPageStr := c.DefaultQuery("start_page", "1")
 
 	// Parse the start date from the query string
 	startDate, err := time.Parse(time.RFC3339, startDateStr)
@@ -54,27 +60,71 @@ func (h *RepositoryHandler) AddRepositoryToMonitor(c *gin.Context) {
 		return
 	}
 
+	// Parse the optional end date that closes the window
+	var endDate time.Time
+	if endDateStr != "" {
+		endDate, err = time.Parse(time.RFC3339, endDateStr)
+		if err != nil || endDate.Before(startDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid end date"})
+			return
+		}
+	}
+
+	// Parse the page to resume from, if any
+	startPage, err := strconv.Atoi(startPageStr)
+	if err != nil || startPage < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid start page"})
+		return
+	}
+
+	// Parse the comma-separated branches to track besides the default one
+	var branches []string
+	for _, pattern := range strings.Split(c.Query("branches"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"statusCode": http.StatusBadRequest, "message": "Invalid branch pattern " + pattern})
+			return
+		}
+		branches = append(branches, pattern)
+	}
+
 	// Create RepoData object
 	repoData := domain.RepoData{
 		Owner:    owner,
 		RepoName: repoName,
+		Branches: branches,
 	}
 
 	// Add the repository to the monitor
-	if err := h.monitorService.AddRepositoryCommitsToMonitor(c, repoData, startDate); err != nil {
+	job, err := h.monitorService.AddRepositoryCommitsToMonitor(c, repoData, startDate, endDate, startPage)
+	if err != nil {
 		// Return 500 Internal Server Error if there is an issue adding the repository
 		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to add repository"})
 		return
 	}
 
-	// Return success message
-	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Repository added successfully"})
+	// Add the repository to the watchlist so it keeps being polled
+	if err := h.repositoryService.WatchRepository(repoData); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to add repository to watchlist"})
+		return
+	}
+
+	// Start pulling the tracked branches right away rather than on the next push
+	if err := h.monitorService.QueueBranchSync(c, repoData); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to queue branch sync"})
+		return
+	}
+
+	// Return success message along with the sync job to follow at /jobs/:id
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Repository added successfully", "job_id": job.ID})
 }
 
 // DeleteRepository removes a repository from the commit monitor
 func (h *RepositoryHandler) DeleteRepository(c *gin.Context) {
 	owner := c.Param("owner")
-	repoName := c.Query("repo")
+	repoName := c.Param("repo")
 
 	// Validate input parameters
 	if owner == "" || repoName == "" {
@@ -94,3 +144,26 @@ func (h *RepositoryHandler) DeleteRepository(c *gin.Context) {
 	// Return success message
 	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "message": "Repository removed successfully"})
 }
+
+// GetRateLimit returns the GitHub API quota currently available to the service
+func (h *RepositoryHandler) GetRateLimit(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": h.monitorService.RateLimit()})
+}
+
+// GetBackfill returns how far the historical backfill of a repository has got
+func (h *RepositoryHandler) GetBackfill(c *gin.Context) {
+	owner := c.Param("owner")
+	repoName := c.Param("repo")
+
+	backfill, err := h.monitorService.GetBackfill(c, owner, repoName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"statusCode": http.StatusInternalServerError, "message": "Failed to retrieve backfill"})
+		return
+	}
+	if backfill == nil {
+		c.JSON(http.StatusNotFound, gin.H{"statusCode": http.StatusNotFound, "message": "No backfill found for repository"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statusCode": http.StatusOK, "data": backfill})
+}
@@ -7,35 +7,156 @@ import (
 )
 
 // SetupAPIRoutes sets up the API routes for the application.
-func SetupAPIRoutes(r *gin.Engine, commitHandler *handlers.CommitHandler, repositoryHandler *handlers.RepositoryHandler) {
+func SetupAPIRoutes(r *gin.Engine, commitHandler *handlers.CommitHandler, repositoryHandler *handlers.RepositoryHandler, jobHandler *handlers.JobHandler, identityHandler *handlers.IdentityHandler, pullRequestHandler *handlers.PullRequestHandler, issueHandler *handlers.IssueHandler, releaseHandler *handlers.ReleaseHandler, changelogHandler *handlers.ChangelogHandler) {
+
+	// Repositories are identified by owner and name, so forks sharing a name are kept apart
 
 	// Route to fetch repository data
-	// GET /repositories/:repo/fetch
+	// GET /repositories/:owner/:repo/fetch
 	// Retrieves detailed information about a specific repository.
-	r.GET("/repositories/:repo/fetch", repositoryHandler.FetchRepositoryData)
+	r.GET("/repositories/:owner/:repo/fetch", repositoryHandler.FetchRepositoryData)
 
 	// Route to get the top N commit authors
-	// GET /repositories/:repo/top-authors/:n
+	// GET /repositories/:owner/:repo/top-authors/:n
 	// Retrieves the top N commit authors for a specific repository.
-	r.GET("/repositories/:repo/top-authors/:n", commitHandler.GetTopNCommitAuthors)
+	r.GET("/repositories/:owner/:repo/top-authors/:n", commitHandler.GetTopNCommitAuthors)
 
 	// Route to retrieve commits for a repository
-	// GET /repositories/:repo/commits
+	// GET /repositories/:owner/:repo/commits
 	// Retrieves a list of commits for a specific repository.
-	r.GET("/repositories/:repo/commits", commitHandler.GetCommits)
+	r.GET("/repositories/:owner/:repo/commits", commitHandler.GetCommits)
+
+	// Route to retrieve a single commit
+	// GET /repositories/:owner/:repo/commits/:sha
+	// Retrieves a commit with its additions, deletions and changed files.
+	r.GET("/repositories/:owner/:repo/commits/:sha", commitHandler.GetCommit)
+
+	// Route to chart commit activity
+	// GET /repositories/:owner/:repo/stats/activity
+	// Returns commit counts per day, week or month, with empty buckets zero-filled, optionally broken down by author.
+	r.GET("/repositories/:owner/:repo/stats/activity", commitHandler.GetCommitActivity)
+
+	// Route to chart when commits are made
+	// GET /repositories/:owner/:repo/stats/punchcard
+	// Returns commit counts by weekday and hour of day in the requested timezone, optionally for one author.
+	r.GET("/repositories/:owner/:repo/stats/punchcard", commitHandler.GetPunchcard)
+
+	// Route to report knowledge concentration
+	// GET /repositories/:owner/:repo/stats/bus-factor
+	// Returns the smallest number of authors covering a share of the commits or changed lines, overall and per top-level directory.
+	r.GET("/repositories/:owner/:repo/stats/bus-factor", commitHandler.GetBusFactor)
+
+	// Route to chart the mix of commit types
+	// GET /repositories/:owner/:repo/stats/commit-types
+	// Returns Conventional Commits type counts (feat, fix, chore...) and breaking changes per day, week or month.
+	r.GET("/repositories/:owner/:repo/stats/commit-types", commitHandler.GetCommitTypeActivity)
+
+	// Route to list the pull requests of a repository
+	// GET /repositories/:owner/:repo/pulls
+	// Retrieves the synced pull requests of a repository with their review counts, filtered by state, author, base branch or creation time.
+	r.GET("/repositories/:owner/:repo/pulls", pullRequestHandler.ListPullRequests)
+
+	// Route to list the issues of a repository
+	// GET /repositories/:owner/:repo/issues
+	// Retrieves the synced issues of a repository, filtered by state, label, assignee, author or opening time.
+	r.GET("/repositories/:owner/:repo/issues", issueHandler.ListIssues)
+
+	// Route to retrieve a single issue
+	// GET /repositories/:owner/:repo/issues/:number
+	// Retrieves an issue with the history of its openings, closes and reopens.
+	r.GET("/repositories/:owner/:repo/issues/:number", issueHandler.GetIssue)
+
+	// Route to chart how fast issues are opened and closed
+	// GET /repositories/:owner/:repo/stats/issues
+	// Returns the issues opened and closed per week, the open backlog and the average time to close.
+	r.GET("/repositories/:owner/:repo/stats/issues", issueHandler.GetIssueRates)
+
+	// Route to list the releases of a repository
+	// GET /repositories/:owner/:repo/releases
+	// Retrieves the synced releases of a repository, most recent first.
+	r.GET("/repositories/:owner/:repo/releases", releaseHandler.ListReleases)
+
+	// Route to list the commits of a release
+	// GET /repositories/:owner/:repo/releases/:tag/commits
+	// Retrieves the commits first shipped in a release, between its tag and the previous release's.
+	r.GET("/repositories/:owner/:repo/releases/:tag/commits", releaseHandler.GetReleaseCommits)
+
+	// Route to build the changelog of a repository
+	// GET /repositories/:owner/:repo/changelog
+	// Groups the stored commits between two refs into breaking changes, features, fixes and other changes, as JSON or Markdown.
+	r.GET("/repositories/:owner/:repo/changelog", changelogHandler.GetChangelog)
 
 	// Route to reset commits for a repository
-	// GET /repositories/:repo/reset
+	// GET /repositories/:owner/:repo/reset
 	// Resets or clears commit data for a specific repository.
-	r.GET("/repositories/reset/:owner", commitHandler.ResetCollection) // Renamed handler to reflect reset operation
+	r.GET("/repositories/:owner/:repo/reset", commitHandler.ResetCollection) // Renamed handler to reflect reset operation
 
 	// Route to add a repository to the monitoring service
-	// GET /repositories/monitor/:owner
+	// GET /repositories/:owner/:repo/monitor
 	// Adds a repository to the monitoring service, including commit pulling history.
-	r.GET("/repositories/monitor/:owner", repositoryHandler.AddRepositoryToMonitor)
+	r.GET("/repositories/:owner/:repo/monitor", repositoryHandler.AddRepositoryToMonitor)
 
 	// Route to remove a repository from the monitoring service
-	// DELETE /repositories/monitor/:owner
+	// DELETE /repositories/:owner/:repo/monitor
 	// Removes a repository from the monitoring service.
-	r.DELETE("/repositories/monitor/:owner", repositoryHandler.DeleteRepository)
+	r.DELETE("/repositories/:owner/:repo/monitor", repositoryHandler.DeleteRepository)
+
+	// Route to follow the historical backfill of a repository
+	// GET /repositories/:owner/:repo/backfill
+	// Returns the backfill checkpoint, the percentage of its range covered and the number of commits stored.
+	r.GET("/repositories/:owner/:repo/backfill", repositoryHandler.GetBackfill)
+
+	// Route to inspect the GitHub API quota
+	// GET /rate-limit
+	// Returns the remaining GitHub quota, when it resets, and any back-off GitHub has requested.
+	r.GET("/rate-limit", repositoryHandler.GetRateLimit)
+
+	// Route to list background sync jobs
+	// GET /jobs
+	// Lists jobs newest first, optionally filtered by status (queued, running, succeeded, failed).
+	r.GET("/jobs", jobHandler.ListJobs)
+
+	// Route to inspect a background sync job
+	// GET /jobs/:id
+	// Returns the job's status, attempt count and last error.
+	r.GET("/jobs/:id", jobHandler.GetJob)
+
+	// Route to list author identities
+	// GET /identities
+	// Lists the identities commits are attributed to, with their aliases.
+	r.GET("/identities", identityHandler.ListIdentities)
+
+	// Route to create an author identity
+	// POST /identities
+	// Creates an identity from a name, email and login, optionally with aliases.
+	r.POST("/identities", identityHandler.CreateIdentity)
+
+	// Route to import a .mailmap file
+	// POST /identities/mailmap
+	// Merges the entries of the .mailmap file sent as the body into the identities and their aliases.
+	r.POST("/identities/mailmap", identityHandler.ImportMailmap)
+
+	// Route to inspect an author identity
+	// GET /identities/:id
+	// Returns the identity with its aliases.
+	r.GET("/identities/:id", identityHandler.GetIdentity)
+
+	// Route to update an author identity
+	// PUT /identities/:id
+	// Replaces the identity's name, email and login.
+	r.PUT("/identities/:id", identityHandler.UpdateIdentity)
+
+	// Route to delete an author identity
+	// DELETE /identities/:id
+	// Deletes the identity and its aliases; its commits fall back to being grouped by login or email.
+	r.DELETE("/identities/:id", identityHandler.DeleteIdentity)
+
+	// Route to add an alias to an author identity
+	// POST /identities/:id/aliases
+	// Attributes the commits made with a name, email, or name and email pair to the identity.
+	r.POST("/identities/:id/aliases", identityHandler.AddAlias)
+
+	// Route to remove an alias from an author identity
+	// DELETE /identities/:id/aliases/:aliasId
+	r.DELETE("/identities/:id/aliases/:aliasId", identityHandler.DeleteAlias)
 }
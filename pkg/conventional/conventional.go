@@ -0,0 +1,110 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Person is a name and email pair from a trailer such as Co-authored-by: Jane Doe <jane@example.com>
+type Person struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Message is a commit message split into its Conventional Commits parts and trailers.
+// Type is empty when the header does not follow the convention; Subject is then the whole first line.
+type Message struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Subject     string
+	CoAuthors   []Person
+	SignedOffBy []Person
+}
+
+// header matches a Conventional Commits header: type(scope)!: subject
+var header = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: *(.+)$`)
+
+// trailer matches a git trailer line: Key: value
+var trailer = regexp.MustCompile(`^([A-Za-z0-9-]+): *(.*)$`)
+
+// person matches a name followed by an email in angle brackets
+var person = regexp.MustCompile(`^(.*?)\s*<([^<>]*)>$`)
+
+// Parse splits a commit message into its Conventional Commits parts (https://www.conventionalcommits.org)
+// and reads the Co-authored-by and Signed-off-by trailers from its last paragraph.
+// Commits are breaking when the header carries a ! or a BREAKING CHANGE footer is present.
+func Parse(message string) Message {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+
+	var parsed Message
+	first := strings.TrimSpace(lines[0])
+	if match := header.FindStringSubmatch(first); match != nil {
+		parsed.Type = strings.ToLower(match[1])
+		parsed.Scope = strings.TrimSpace(match[2])
+		parsed.Breaking = match[3] == "!"
+		parsed.Subject = strings.TrimSpace(match[4])
+	} else {
+		parsed.Subject = first
+	}
+	if len(lines) == 1 {
+		return parsed
+	}
+
+	// Trailers are the last paragraph, when every line in it is a trailer or continues one
+	start := len(lines)
+	for start > 1 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	paragraph := lines[start:]
+	isTrailers := start > 1
+	for _, line := range paragraph {
+		if !trailer.MatchString(line) && !isContinuation(line) && !isBreakingFooter(line) {
+			isTrailers = false
+			break
+		}
+	}
+
+	for _, line := range lines[1:] {
+		if isBreakingFooter(line) {
+			parsed.Breaking = true
+		}
+	}
+	if !isTrailers {
+		return parsed
+	}
+
+	for _, line := range paragraph {
+		match := trailer.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		switch strings.ToLower(match[1]) {
+		case "co-authored-by":
+			parsed.CoAuthors = append(parsed.CoAuthors, parsePerson(match[2]))
+		case "signed-off-by":
+			parsed.SignedOffBy = append(parsed.SignedOffBy, parsePerson(match[2]))
+		}
+	}
+	return parsed
+}
+
+// isBreakingFooter reports whether a line is a BREAKING CHANGE footer
+func isBreakingFooter(line string) bool {
+	return strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:")
+}
+
+// isContinuation reports whether a line continues the value of the trailer before it
+func isContinuation(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// parsePerson splits a trailer value into a name and an email
+func parsePerson(value string) Person {
+	value = strings.TrimSpace(value)
+	if match := person.FindStringSubmatch(value); match != nil {
+		return Person{Name: strings.TrimSpace(match[1]), Email: strings.TrimSpace(match[2])}
+	}
+	return Person{Name: value}
+}
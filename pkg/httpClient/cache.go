@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxCacheEntries bounds how many URLs the validator cache remembers
+const maxCacheEntries = 10000
+
+// ErrNotModified is returned when a conditional request is answered with 304 Not Modified.
+// GitHub does not count these responses against the rate limit.
+var ErrNotModified = errors.New("resource not modified")
+
+// validators are the ETag and Last-Modified values returned for a URL
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// validatorCache remembers the validators of previous GET responses so the next
+// request for the same URL can be sent as a conditional request.
+type validatorCache struct {
+	mu      sync.Mutex
+	entries map[string]validators
+}
+
+// newValidatorCache creates an empty validatorCache
+func newValidatorCache() *validatorCache {
+	return &validatorCache{entries: make(map[string]validators)}
+}
+
+// apply adds If-None-Match and If-Modified-Since headers to a GET request for a URL seen before
+func (vc *validatorCache) apply(req *http.Request) {
+	if req.Method != http.MethodGet {
+		return
+	}
+
+	vc.mu.Lock()
+	entry, ok := vc.entries[req.URL.String()]
+	vc.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+}
+
+// store remembers the validators from a successful response to url
+func (vc *validatorCache) store(url string, header http.Header) {
+	entry := validators{etag: header.Get("ETag"), lastModified: header.Get("Last-Modified")}
+	if entry.etag == "" && entry.lastModified == "" {
+		return
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	if _, exists := vc.entries[url]; !exists && len(vc.entries) >= maxCacheEntries {
+		// Make room by evicting an arbitrary entry; it only costs one unconditional request
+		for key := range vc.entries {
+			delete(vc.entries, key)
+			break
+		}
+	}
+	vc.entries[url] = entry
+}
+
+// forget drops the validators for url so the next request for it is unconditional
+func (vc *validatorCache) forget(url string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	delete(vc.entries, url)
+}
+
+// forgetPrefix drops the validators for every url starting with prefix
+func (vc *validatorCache) forgetPrefix(prefix string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	for url := range vc.entries {
+		if strings.HasPrefix(url, prefix) {
+			delete(vc.entries, url)
+		}
+	}
+}
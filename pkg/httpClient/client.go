@@ -14,42 +14,45 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// RateLimiter struct to handle rate limiting
-type RateLimiter struct {
-	ticker *time.Ticker
-}
-
-// NewRateLimiter creates a new RateLimiter
-func NewRateLimiter(interval time.Duration) *RateLimiter {
-	return &RateLimiter{
-		ticker: time.NewTicker(interval),
-	}
-}
-
-// Wait ensures the rate limit is respected
-func (rl *RateLimiter) Wait() {
-	<-rl.ticker.C
-}
+// maxRateLimitRetries is how many times a rate limited request is retried before giving up
+const maxRateLimitRetries = 3
 
 // Client struct that wraps the native http.Client and allows middleware to be used
 type Client struct {
 	httpClient  HTTPClient
 	rateLimiter *RateLimiter
+	tokens      *TokenPool
+	cache       *validatorCache
 }
 
-// NewClient initializes a new Client with a given http.Client or the default one
-func NewClient(httpClient HTTPClient, rateLimitInterval time.Duration) *Client {
+// NewClient initializes a new Client with a given http.Client or the default one.
+// Requests are authenticated with tokens from the pool; a nil or empty pool sends them anonymously.
+func NewClient(httpClient HTTPClient, tokens *TokenPool) *Client {
 	if httpClient == nil {
 		httpClient = &http.Client{}
 	}
 	return &Client{
 		httpClient:  httpClient,
-		rateLimiter: NewRateLimiter(rateLimitInterval),
+		rateLimiter: NewRateLimiter(),
+		tokens:      tokens,
+		cache:       newValidatorCache(),
 	}
 }
 
+// Quota returns the rate limit state last reported by the API, and whether one has been reported yet.
+// With a token pool it is the combined quota of every token in the pool.
+func (c *Client) Quota() (Quota, bool) {
+	return c.rateLimiter.Quota()
+}
+
+// BlockedUntil returns when the back-off requested by the API ends, or the zero time if there is none
+func (c *Client) BlockedUntil() time.Time {
+	return c.rateLimiter.BlockedUntil()
+}
+
 // CreateRequest abstracts the logic for creating an HTTP request with context support.
-func (c *Client) CreateRequest(ctx context.Context, methodType, url string, body []byte) (*http.Request, error) {
+// A non-empty token is sent as a bearer Authorization header.
+func (c *Client) CreateRequest(ctx context.Context, methodType, url string, body []byte, token string) (*http.Request, error) {
 	var req *http.Request
 	var err error
 
@@ -64,16 +67,37 @@ func (c *Client) CreateRequest(ctx context.Context, methodType, url string, body
 		return nil, err
 	}
 
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
 	return req, nil
 }
 
+// Response holds the status, headers and body of a completed API call
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// StatusError is returned when the API answers with an unsuccessful status
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch external data: %s", e.Status)
+}
+
 // HandleResponse abstracts the logic for handling the HTTP response.
-func (c *Client) HandleResponse(resp *http.Response) ([]byte, error) {
+func (c *Client) HandleResponse(resp *http.Response) (*Response, error) {
 	defer resp.Body.Close()
 
 	// Check if the status code is OK
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch external data: %s", resp.Status)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	// Read the response body
@@ -82,26 +106,123 @@ func (c *Client) HandleResponse(resp *http.Response) ([]byte, error) {
 		return nil, err
 	}
 
-	return result, nil
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: result}, nil
 }
 
-// ApiCall performs the HTTP request using the native http.Client Do method and returns the response, with context support.
+// ApiCall performs the HTTP request using the native http.Client Do method and returns the response body, with context support.
 func (c *Client) ApiCall(ctx context.Context, methodType, url string, body []byte) ([]byte, error) {
-	// Wait for the rate limiter before making the request
-	c.rateLimiter.Wait()
-
-	// Create the request using the abstracted function
-	req, err := c.CreateRequest(ctx, methodType, url, body)
+	resp, err := c.ApiCallWithResponse(ctx, methodType, url, body)
 	if err != nil {
 		return nil, err
 	}
+	return resp.Body, nil
+}
+
+// ApiCallWithResponse performs the HTTP request like ApiCall but also returns the response headers,
+// which callers need to follow pagination links.
+// Requests are paced by the quota the API reports, and rate limited responses are retried after backing off.
+// GET requests for a URL fetched before are sent with its ETag/Last-Modified validators,
+// and a 304 Not Modified answer is returned as ErrNotModified.
+func (c *Client) ApiCallWithResponse(ctx context.Context, methodType, url string, body []byte) (*Response, error) {
+	for attempt := 1; ; attempt++ {
+		// Wait for the rate limiter before making the request
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Pick the token with the most quota left, waiting for a reset if every token is exhausted
+		token, wait := c.tokens.Acquire()
+		if wait > 0 {
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		// Create the request using the abstracted function
+		req, err := c.CreateRequest(ctx, methodType, url, body, token)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.apply(req)
+
+		// Perform the HTTP request
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		// Record the quota the API reported
+		c.observe(token, resp.Header)
+
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if !isRateLimited(resp, respBody) {
+				return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+			}
+
+			// Hold every request back for as long as the API asked, then try again
+			delay := retryDelay(resp.Header, attempt)
+			c.rateLimiter.BlockUntil(time.Now().Add(delay))
+			if attempt >= maxRateLimitRetries {
+				return nil, fmt.Errorf("rate limited after %d attempts: %s", attempt, resp.Status)
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			return nil, ErrNotModified
+		}
+
+		// Handle the response using the abstracted function
+		result, err := c.HandleResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.store(req.URL.String(), resp.Header)
+		return result, nil
+	}
+}
 
-	// Perform the HTTP request
-	resp, err := c.httpClient.Do(req)
+// Forget drops the cached validators for url, so the next request for it is sent unconditionally.
+// Callers use it when they could not act on a response and need the full body again next time.
+func (c *Client) Forget(url string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return
 	}
+	c.cache.forget(req.URL.String())
+}
+
+// ForgetPrefix drops the cached validators for every URL starting with prefix,
+// for callers that can no longer act on anything fetched below it.
+func (c *Client) ForgetPrefix(prefix string) {
+	c.cache.forgetPrefix(prefix)
+}
+
+// observe updates the token pool and rate limiter with the quota reported in the response headers.
+// With several tokens the limiter paces against the pool's combined quota rather than the single token's.
+func (c *Client) observe(token string, header http.Header) {
+	quota, ok := ParseQuota(header)
+	if !ok {
+		return
+	}
+	c.tokens.Update(token, quota)
+	if c.tokens.Size() > 1 {
+		quota = c.tokens.Quota()
+	}
+	c.rateLimiter.Observe(quota)
+}
 
-	// Handle the response using the abstracted function
-	return c.HandleResponse(resp)
+// sleepContext pauses for d, returning early with the context error if ctx is cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
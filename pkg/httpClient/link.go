@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ParseLinkHeader parses an RFC 5988 Link header into a map of rel to URL.
+// A header such as `<https://api.github.com/...&page=2>; rel="next"` yields {"next": "https://api.github.com/...&page=2"}.
+func ParseLinkHeader(header string) map[string]string {
+	links := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		sections := strings.Split(strings.TrimSpace(part), ";")
+		if len(sections) < 2 {
+			continue
+		}
+
+		// The URL is wrapped in angle brackets
+		url := strings.TrimSpace(sections[0])
+		if !strings.HasPrefix(url, "<") || !strings.HasSuffix(url, ">") {
+			continue
+		}
+		url = url[1 : len(url)-1]
+
+		// Look for the rel parameter among the remaining sections
+		for _, param := range sections[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(key) != "rel" {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				links[rel] = url
+			}
+		}
+	}
+	return links
+}
+
+// NextPageURL returns the rel="next" URL from the response headers, or an empty string on the last page.
+func NextPageURL(header http.Header) string {
+	return ParseLinkHeader(header.Get("Link"))["next"]
+}
@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github-service/pkg/utils"
+)
+
+// secondaryLimitBackoff is the base wait after a secondary rate limit response that carries no Retry-After header
+const secondaryLimitBackoff = time.Minute
+
+// Quota is the rate limit state the API reported in its X-RateLimit-* headers
+type Quota struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// ParseQuota reads the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+// It reports false when the response carries no rate limit information.
+func ParseQuota(header http.Header) (Quota, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return Quota{}, false
+	}
+	resetUnix, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return Quota{}, false
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+
+	return Quota{Limit: limit, Remaining: remaining, Reset: time.Unix(resetUnix, 0)}, true
+}
+
+// RateLimiter paces requests so the remaining quota lasts until it resets,
+// and holds every request back while the API has asked the client to back off.
+type RateLimiter struct {
+	mu           sync.Mutex
+	quota        Quota
+	known        bool
+	next         time.Time // Earliest time the next request may go out
+	blockedUntil time.Time // Set by Retry-After and secondary rate limit responses
+}
+
+// NewRateLimiter creates a new RateLimiter.
+// Until the first response reports a quota, requests are not delayed.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{}
+}
+
+// Wait blocks until the next request may be sent, or until ctx is cancelled.
+// Requests are spread evenly over the time left until the quota resets; once the quota is
+// exhausted, or while a back-off is in effect, Wait holds requests until it is lifted.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	rl.mu.Lock()
+	now := time.Now()
+	at := now
+	if rl.next.After(at) {
+		at = rl.next
+	}
+	if rl.blockedUntil.After(at) {
+		at = rl.blockedUntil
+	}
+
+	if rl.known && rl.quota.Reset.After(now) {
+		if rl.quota.Remaining <= 0 {
+			// Nothing left in this window, wait for the reset
+			if rl.quota.Reset.After(at) {
+				at = rl.quota.Reset
+			}
+		} else {
+			// Reserve the slot after this one so concurrent callers queue up behind it
+			interval := rl.quota.Reset.Sub(now) / time.Duration(rl.quota.Remaining)
+			rl.next = at.Add(interval)
+		}
+	}
+	rl.mu.Unlock()
+
+	if wait := at.Sub(now); wait > 0 {
+		return sleepContext(ctx, wait)
+	}
+	return nil
+}
+
+// Observe records the quota reported by the latest response
+func (rl *RateLimiter) Observe(quota Quota) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.quota = quota
+	rl.known = true
+}
+
+// BlockUntil holds every request back until t
+func (rl *RateLimiter) BlockUntil(t time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if t.After(rl.blockedUntil) {
+		rl.blockedUntil = t
+	}
+}
+
+// Quota returns the last quota reported by the API, and whether one has been reported yet
+func (rl *RateLimiter) Quota() (Quota, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.quota, rl.known
+}
+
+// BlockedUntil returns the time a back-off in effect ends, or the zero time if there is none
+func (rl *RateLimiter) BlockedUntil() time.Time {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.blockedUntil.Before(time.Now()) {
+		return time.Time{}
+	}
+	return rl.blockedUntil
+}
+
+// isRateLimited reports whether resp is a primary or secondary rate limit rejection.
+// GitHub answers these with 429, or with 403 and either a Retry-After header,
+// an exhausted quota or a body mentioning the rate limit.
+func isRateLimited(resp *http.Response, body []byte) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" ||
+			resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			strings.Contains(strings.ToLower(string(body)), "rate limit")
+	}
+	return false
+}
+
+// retryDelay works out how long to back off after a rate limited response.
+// Retry-After wins, then the quota reset time, then an exponential back-off from one minute.
+func retryDelay(header http.Header, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if quota, ok := ParseQuota(header); ok && quota.Remaining == 0 {
+		if wait := time.Until(quota.Reset); wait > 0 {
+			return wait
+		}
+	}
+	return utils.ExponentialBackoff(attempt, secondaryLimitBackoff)
+}
@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTokenQuota is the hourly quota assumed for a token before the API has reported its real one
+const defaultTokenQuota = 5000
+
+// TokenPool rotates between several API tokens, always handing out the one with the most remaining quota.
+// Tokens that have run out are parked until their quota resets.
+type TokenPool struct {
+	mu     sync.Mutex
+	tokens []*tokenState
+}
+
+// tokenState tracks the quota last reported for a single token
+type tokenState struct {
+	value     string
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// NewTokenPool creates a TokenPool from the given tokens, skipping blanks and duplicates.
+func NewTokenPool(tokens []string) *TokenPool {
+	pool := &TokenPool{}
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+		pool.tokens = append(pool.tokens, &tokenState{value: token, limit: defaultTokenQuota, remaining: defaultTokenQuota})
+	}
+	return pool
+}
+
+// Size returns the number of tokens in the pool
+func (p *TokenPool) Size() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.tokens)
+}
+
+// Acquire returns the token with the most remaining quota.
+// When every token is parked it returns the one that resets first, along with how long to wait for that reset.
+// An empty pool returns an empty token, meaning requests go out unauthenticated.
+func (p *TokenPool) Acquire() (string, time.Duration) {
+	if p.Size() == 0 {
+		return "", 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var best, earliest *tokenState
+	for _, t := range p.tokens {
+		// A parked token becomes usable again once its reset time has passed
+		if t.remaining <= 0 && !now.Before(t.reset) {
+			t.remaining = t.limit
+		}
+
+		if t.remaining > 0 {
+			if best == nil || t.remaining > best.remaining {
+				best = t
+			}
+			continue
+		}
+
+		if earliest == nil || t.reset.Before(earliest.reset) {
+			earliest = t
+		}
+	}
+
+	if best != nil {
+		// Count the request against the token until the API reports the real figure
+		best.remaining--
+		return best.value, 0
+	}
+	return earliest.value, earliest.reset.Sub(now)
+}
+
+// Update records the quota the API reported for token.
+func (p *TokenPool) Update(token string, quota Quota) {
+	if p.Size() == 0 || token == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.tokens {
+		if t.value == token {
+			if quota.Limit > 0 {
+				t.limit = quota.Limit
+			}
+			t.remaining = quota.Remaining
+			t.reset = quota.Reset
+			return
+		}
+	}
+}
+
+// Quota returns the combined quota of the pool: the summed limits and remaining requests of
+// every token, resetting when the first token resets.
+func (p *TokenPool) Quota() Quota {
+	var quota Quota
+	if p.Size() == 0 {
+		return quota
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, t := range p.tokens {
+		quota.Limit += t.limit
+		if now.Before(t.reset) {
+			quota.Remaining += max(t.remaining, 0)
+			if quota.Reset.IsZero() || t.reset.Before(quota.Reset) {
+				quota.Reset = t.reset
+			}
+		} else {
+			// The token's window has already reset
+			quota.Remaining += t.limit
+		}
+	}
+	return quota
+}
@@ -0,0 +1,77 @@
+package mailmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Entry is one mapping of a .mailmap file: commits by CommitEmail, and CommitName when it is set,
+// belong to the person with ProperName and ProperEmail. Either proper field may be empty.
+type Entry struct {
+	ProperName  string
+	ProperEmail string
+	CommitName  string
+	CommitEmail string
+}
+
+// Parse reads a .mailmap file in any of the forms git accepts:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// Blank lines and comments starting with # are skipped.
+func Parse(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		entry, err := parseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseLine splits a line into up to two (name, <email>) pairs
+func parseLine(text string) (Entry, error) {
+	var names, emails []string
+	for len(emails) < 2 {
+		open := strings.Index(text, "<")
+		if open < 0 {
+			break
+		}
+		close := strings.Index(text[open:], ">")
+		if close < 0 {
+			return Entry{}, fmt.Errorf("unterminated email in %q", text)
+		}
+		names = append(names, strings.TrimSpace(text[:open]))
+		emails = append(emails, strings.TrimSpace(text[open+1:open+close]))
+		text = text[open+close+1:]
+	}
+	if strings.TrimSpace(text) != "" || len(emails) == 0 {
+		return Entry{}, fmt.Errorf("malformed entry")
+	}
+
+	// With a single email, it is the email found in commits
+	if len(emails) == 1 {
+		return Entry{ProperName: names[0], CommitEmail: emails[0]}, nil
+	}
+	return Entry{ProperName: names[0], ProperEmail: emails[0], CommitName: names[1], CommitEmail: emails[1]}, nil
+}
@@ -1,7 +1,9 @@
 package pagination
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,3 +27,23 @@ func ParsePaginationParams(c *gin.Context) (page int, limit int, err error) {
 func RespondWithError(c *gin.Context, statusCode int, message string) {
 	c.JSON(statusCode, gin.H{"statusCode": statusCode, "message": message})
 }
+
+// SetCursorLinks sets an RFC 5988 Link header pointing at the next and previous pages of a cursor-paginated listing.
+// The links repeat the request with its cursor replaced; an empty cursor leaves that link out.
+func SetCursorLinks(c *gin.Context, nextCursor, prevCursor string) {
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{{"next", nextCursor}, {"prev", prevCursor}} {
+		if link.cursor == "" {
+			continue
+		}
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("cursor", link.cursor)
+		query.Del("page")
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), link.rel))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCommitActivity(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	commitService := service.NewCommitService(commitRepo, nil, &config.Config{}, nil)
+
+	day := func(month time.Month, d, hour int) time.Time {
+		return time.Date(2024, month, d, hour, 0, 0, 0, time.UTC)
+	}
+	newYork := time.FixedZone("EST", -5*60*60)
+	testCommits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Author: "Alice", AuthorLogin: "alice", CommitDate: day(time.January, 1, 9)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Author: "Bob", Email: "bob@example.com", CommitDate: day(time.January, 2, 9)},
+		// Sunday evening in New York is Monday in UTC, so it falls in the second week
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Author: "Alice", AuthorLogin: "alice", CommitDate: time.Date(2024, time.January, 7, 23, 0, 0, 0, newYork)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", Author: "Alice", AuthorLogin: "alice", CommitDate: day(time.February, 5, 9)},
+		// Another repository must not be counted
+		{Owner: "octocat", Repository: "Spoon-Knife", Hash: "commit5", Author: "Alice", AuthorLogin: "alice", CommitDate: day(time.January, 3, 9)},
+	}
+	for _, commit := range testCommits {
+		assert.NoError(t, commitRepo.SaveCommit(ctx, &commit))
+	}
+
+	// Weekly, with the weeks without commits zero-filled
+	activity, err := commitService.GetCommitActivity(ctx, "octocat", "Hello-World", domain.ActivityWeek, day(time.January, 3, 0), day(time.February, 11, 0), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, activity.Total) // The window starts after the first two commits
+	assert.Equal(t, []domain.ActivityPoint{
+		{Start: day(time.January, 1, 0), Count: 0},
+		{Start: day(time.January, 8, 0), Count: 1},
+		{Start: day(time.January, 15, 0), Count: 0},
+		{Start: day(time.January, 22, 0), Count: 0},
+		{Start: day(time.January, 29, 0), Count: 0},
+		{Start: day(time.February, 5, 0), Count: 1},
+	}, activity.Buckets)
+	assert.Empty(t, activity.Authors)
+
+	// Monthly by author, starting from the first commit
+	activity, err = commitService.GetCommitActivity(ctx, "octocat", "Hello-World", domain.ActivityMonth, time.Time{}, day(time.March, 1, 0), true)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, activity.Total)
+	assert.Equal(t, []domain.ActivityPoint{
+		{Start: day(time.January, 1, 0), Count: 3},
+		{Start: day(time.February, 1, 0), Count: 1},
+		{Start: day(time.March, 1, 0), Count: 0},
+	}, activity.Buckets)
+	if assert.Len(t, activity.Authors, 2) {
+		assert.Equal(t, "alice", activity.Authors[0].Identity)
+		assert.Equal(t, 3, activity.Authors[0].Total)
+		assert.Equal(t, []int{2, 1, 0}, []int{activity.Authors[0].Buckets[0].Count, activity.Authors[0].Buckets[1].Count, activity.Authors[0].Buckets[2].Count})
+		assert.Equal(t, "bob@example.com", activity.Authors[1].Identity)
+		assert.Equal(t, "Bob", activity.Authors[1].Author)
+		assert.Equal(t, 1, activity.Authors[1].Total)
+	}
+
+	// Daily over two decades is more than a response may hold
+	_, err = commitService.GetCommitActivity(ctx, "octocat", "Hello-World", domain.ActivityDay, day(time.January, 1, 0).AddDate(-20, 0, 0), day(time.January, 1, 0), false)
+	assert.ErrorIs(t, err, domain.ErrTooManyBuckets)
+}
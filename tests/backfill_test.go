@@ -0,0 +1,247 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/config"
+	githubclient "github-service/internal/adapters/github"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeGithub serves one commit per day of the requested window and fails the first request for a given window
+type fakeGithub struct {
+	mu      sync.Mutex
+	failing time.Time
+	failed  bool
+}
+
+func (f *fakeGithub) FetchRepository(ctx context.Context, owner, repoName string) (*domain.Repository, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeGithub) ForgetRepository(owner, repoName string) {}
+
+func (f *fakeGithub) FetchCommit(ctx context.Context, owner, repo string, opts domain.CommitFetchOptions, handle func(page int, commits []domain.Commit) error) error {
+	f.mu.Lock()
+	if opts.Since.Equal(f.failing) && !f.failed {
+		f.failed = true
+		f.mu.Unlock()
+		return errors.New("connection reset")
+	}
+	f.mu.Unlock()
+
+	var commits []domain.Commit
+	for day := opts.Since; day.Before(opts.Until); day = day.Add(24 * time.Hour) {
+		commits = append(commits, domain.Commit{
+			Owner:      owner,
+			Repository: repo,
+			Hash:       fmt.Sprintf("commit-%s", day.Format("2006-01-02")),
+			CommitDate: day,
+		})
+	}
+	return handle(1, commits)
+}
+
+func (f *fakeGithub) ForgetCommits(owner, repo string) {}
+
+func (f *fakeGithub) FetchCommitDetail(ctx context.Context, owner, repo, sha string) (*domain.CommitDetail, error) {
+	return &domain.CommitDetail{
+		Commit: domain.Commit{Owner: owner, Repository: repo, Hash: sha, Additions: 12, Deletions: 3, Changes: 15},
+		Files: []domain.CommitFile{
+			{Owner: owner, Repository: repo, CommitHash: sha, Filename: "main.go", Status: "modified", Additions: 10, Deletions: 3, Changes: 13},
+			{Owner: owner, Repository: repo, CommitHash: sha, Filename: "README.md", Status: "added", Additions: 2, Changes: 2},
+		},
+	}, nil
+}
+
+func (f *fakeGithub) FetchPullRequests(ctx context.Context, owner, repo string, since time.Time, handle func(pulls []domain.PullRequest) error) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeGithub) FetchPullReviewStats(ctx context.Context, owner, repo string, number int) (domain.PullReviewStats, error) {
+	return domain.PullReviewStats{}, errors.New("not implemented")
+}
+
+func (f *fakeGithub) FetchIssues(ctx context.Context, owner, repo string, since time.Time, handle func(issues []domain.Issue) error) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeGithub) FetchIssueTransitions(ctx context.Context, owner, repo string, since time.Time, handle func(transitions []domain.IssueTransition) error) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeGithub) FetchReleases(ctx context.Context, owner, repo string) ([]domain.Release, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeGithub) FetchTags(ctx context.Context, owner, repo string) ([]domain.Tag, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeGithub) FetchBranches(ctx context.Context, owner, repo string) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeGithub) RateLimit() domain.RateLimit {
+	return domain.RateLimit{}
+}
+
+func TestBackfillResumesFromCheckpoint(t *testing.T) {
+	// Setup in-memory SQLite database shared by the workers
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &postgresdb.Repository{}, &domain.Job{}, &domain.Backfill{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	repositoryRepo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Thirty days in ten day slices; the second slice fails on its first attempt
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(30 * 24 * time.Hour)
+	github := &fakeGithub{failing: start.Add(10 * 24 * time.Hour)}
+
+	cfg := &config.Config{BACKFILL_SLICE_DAYS: 10}
+	commitService := service.NewCommitService(commitRepo, backfillRepo, cfg, github)
+	jobService := service.NewJobService(jobRepo, 1, 3, 10*time.Millisecond)
+	repositoryService := service.NewRepositoryService(repositoryRepo, *commitService, cfg, nil, github)
+	monitorService := service.NewMonitorService(commitService, repositoryService, 1, time.Millisecond, github, jobService, backfillRepo, cfg)
+	jobService.Start(ctx)
+
+	job, err := monitorService.AddRepositoryCommitsToMonitor(ctx, domain.RepoData{Owner: "octocat", RepoName: "Hello-World"}, start, end, 1)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		stored, err := jobService.GetJob(ctx, job.ID)
+		return err == nil && stored.Status == domain.JobSucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stored, err := jobService.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stored.Attempts)
+
+	// The retry picked up at the failed slice, so every day was stored exactly once
+	backfill, err := monitorService.GetBackfill(ctx, "octocat", "Hello-World")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BackfillCompleted, backfill.Status)
+	assert.Equal(t, end, backfill.Cursor.UTC())
+	assert.Equal(t, 30, backfill.CommitsStored)
+	assert.Equal(t, float64(100), backfill.Percent)
+
+	total, err := commitService.GetCommitCount(ctx, "octocat", "Hello-World", domain.CommitFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), total)
+}
+
+func TestBackfillAfterResetRefetchesCommits(t *testing.T) {
+	// GitHub serves the same two commits under an ETag and answers 304 to requests that send it back
+	var notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[
+			{"sha": "b2", "commit": {"message": "Second", "author": {"name": "Mona", "date": "2024-01-05T10:00:00Z"}, "committer": {"name": "Mona", "date": "2024-01-05T10:00:00Z"}}},
+			{"sha": "a1", "commit": {"message": "First", "author": {"name": "Mona", "date": "2024-01-02T10:00:00Z"}, "committer": {"name": "Mona", "date": "2024-01-02T10:00:00Z"}}}
+		]`)
+	}))
+	defer server.Close()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	err = db.AutoMigrate(&postgresdb.Commit{}, &postgresdb.Repository{}, &domain.CommitFile{}, &domain.CommitBranch{}, &domain.Job{}, &domain.Backfill{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	repositoryRepo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.Config{BASE_URL: server.URL, PER_PAGE: "100", BACKFILL_SLICE_DAYS: 10}
+	github := service.NewGithubService(cfg, ctx, githubclient.NewGithubClient(cfg, ctx))
+	commitService := service.NewCommitService(commitRepo, backfillRepo, cfg, github)
+	jobService := service.NewJobService(jobRepo, 1, 1, 10*time.Millisecond)
+	repositoryService := service.NewRepositoryService(repositoryRepo, *commitService, cfg, nil, github)
+	monitorService := service.NewMonitorService(commitService, repositoryService, 1, time.Millisecond, github, jobService, backfillRepo, cfg)
+	jobService.Start(ctx)
+
+	rData := domain.RepoData{Owner: "octocat", RepoName: "hello-world"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * 24 * time.Hour)
+	backfillOnce := func() {
+		job, err := monitorService.AddRepositoryCommitsToMonitor(ctx, rData, start, end, 1)
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			stored, err := jobService.GetJob(ctx, job.ID)
+			return err == nil && stored.Status == domain.JobSucceeded
+		}, 5*time.Second, 10*time.Millisecond)
+
+		backfill, err := monitorService.GetBackfill(ctx, "octocat", "hello-world")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, backfill.CommitsStored)
+		total, err := commitService.GetCommitCount(ctx, "octocat", "hello-world", domain.CommitFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+	}
+	backfillOnce()
+
+	// Resetting the repository forgets the listings, so backfilling the same range again is not answered with 304
+	ok, err := commitService.DeleteCommits(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	backfillOnce()
+	assert.Equal(t, int32(0), notModified.Load())
+}
+
+func TestBackfillProgress(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	backfill := domain.Backfill{
+		RangeStart: start,
+		RangeEnd:   start.Add(40 * 24 * time.Hour),
+		Cursor:     start.Add(10 * 24 * time.Hour),
+		Status:     domain.BackfillRunning,
+	}
+	assert.Equal(t, float64(25), backfill.Progress())
+
+	backfill.Status = domain.BackfillCompleted
+	assert.Equal(t, float64(100), backfill.Progress())
+}
@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeBranchesGithub serves the history of each branch newest first, as GitHub does, and remembers where each pull started
+type fakeBranchesGithub struct {
+	fakeGithub
+	mu       sync.Mutex
+	branches map[string][]domain.Commit
+	since    map[string]time.Time
+}
+
+func (f *fakeBranchesGithub) FetchBranches(ctx context.Context, owner, repo string) ([]string, error) {
+	return []string{"feature/x", "main", "release/1.0", "release/1.0/hotfix", "release/2.0"}, nil
+}
+
+func (f *fakeBranchesGithub) FetchCommit(ctx context.Context, owner, repo string, opts domain.CommitFetchOptions, handle func(page int, commits []domain.Commit) error) error {
+	f.mu.Lock()
+	f.since[opts.Branch] = opts.Since
+	var commits []domain.Commit
+	for _, commit := range f.branches[opts.Branch] {
+		if !commit.CommitDate.Before(opts.Since) {
+			commits = append(commits, commit)
+		}
+	}
+	f.mu.Unlock()
+	return handle(1, commits)
+}
+
+func (f *fakeBranchesGithub) pulledSince(branch string) (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	since, ok := f.since[branch]
+	return since, ok
+}
+
+func TestBranchTracking(t *testing.T) {
+	// Setup in-memory SQLite database shared by the workers
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &postgresdb.Repository{}, &domain.CommitBranch{}, &domain.Job{}, &domain.Backfill{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	repositoryRepo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	day := 24 * time.Hour
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(hash string, days int) domain.Commit {
+		return domain.Commit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: "Change " + hash, Author: "Mona",
+			CommitDate: base.Add(time.Duration(days) * day)}
+	}
+	c1, c2, c3 := commit("c1", 1), commit("c2", 2), commit("c3", 5)
+	r1, r2 := commit("r1", 3), commit("r2", 6)
+	github := &fakeBranchesGithub{
+		branches: map[string][]domain.Commit{
+			"main":               {c3, c2, c1},
+			"release/1.0":        {r2, r1, c1},
+			"release/1.0/hotfix": {commit("h1", 4), r1, c1},
+			"feature/x":          {commit("f1", 4), c1},
+		},
+		since: make(map[string]time.Time),
+	}
+
+	// Commits saved before branches were recorded, and the repository's default branch
+	assert.NoError(t, commitRepo.SaveCommits(ctx, []domain.Commit{c1, c2}))
+	assert.NoError(t, repositoryRepo.SaveRepository(ctx, &domain.Repository{Owner: "octocat", Name: "hello-world", DefaultBranch: "main"}))
+
+	cfg := &config.Config{}
+	commitService := service.NewCommitService(commitRepo, backfillRepo, cfg, github)
+	repositoryService := service.NewRepositoryService(repositoryRepo, *commitService, cfg, nil, github)
+	jobService := service.NewJobService(jobRepo, 1, 1, 10*time.Millisecond)
+	monitorService := service.NewMonitorService(commitService, repositoryService, 1, time.Millisecond, github, jobService, backfillRepo, cfg)
+	jobService.Start(ctx)
+
+	branchCount := func(branch string) int64 {
+		count, err := commitService.GetCommitCount(ctx, "octocat", "hello-world", domain.CommitFilter{Branch: branch})
+		assert.NoError(t, err)
+		return count
+	}
+
+	// The saved commits are recorded on the default branch, which is pulled from its last commit
+	rData := domain.RepoData{Owner: "octocat", RepoName: "hello-world", Branches: []string{"main", "release/*"}}
+	assert.NoError(t, monitorService.MonitorRepositoryCommits(ctx, rData))
+	assert.Eventually(t, func() bool { return branchCount("main") == 3 }, 5*time.Second, 10*time.Millisecond)
+	since, _ := github.pulledSince("main")
+	assert.True(t, since.Equal(c2.CommitDate))
+
+	// Tracked branches other than the default one are pulled in full the first time; the glob does not cross slashes
+	assert.NoError(t, monitorService.QueueBranchSync(ctx, rData))
+	assert.Eventually(t, func() bool { return branchCount("release/1.0") == 3 }, 5*time.Second, 10*time.Millisecond)
+	since, _ = github.pulledSince("release/1.0")
+	assert.True(t, since.IsZero())
+	_, pulled := github.pulledSince("release/1.0/hotfix")
+	assert.False(t, pulled)
+	_, pulled = github.pulledSince("feature/x")
+	assert.False(t, pulled)
+
+	commits, err := commitService.GetPaginatedCommits(ctx, "octocat", "hello-world", domain.CommitFilter{Branch: "release/1.0"}, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, commits, 3) {
+		assert.Equal(t, []string{"r2", "r1", "c1"}, []string{commits[0].Hash, commits[1].Hash, commits[2].Hash})
+	}
+	total, err := commitService.GetCommitCount(ctx, "octocat", "hello-world", domain.CommitFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	assert.Equal(t, int64(3), branchCount("main"))
+
+	// The next pulls resume from the last commit of each branch, so newer commits on a release branch
+	// do not hide the default branch's
+	assert.NoError(t, monitorService.QueueBranchSync(ctx, rData))
+	assert.Eventually(t, func() bool {
+		since, _ := github.pulledSince("release/1.0")
+		return since.Equal(r2.CommitDate)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, monitorService.MonitorRepositoryCommits(ctx, rData))
+	assert.Eventually(t, func() bool {
+		since, _ := github.pulledSince("main")
+		return since.Equal(c3.CommitDate)
+	}, 5*time.Second, 10*time.Millisecond)
+}
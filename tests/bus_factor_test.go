@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestBusFactor(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.CommitFile{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	repo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	repositoryService := service.NewRepositoryService(repo, service.CommitService{}, &config.Config{}, nil, nil)
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	enrichedAt := time.Now()
+	commit := func(hash, login string, lines int) domain.Commit {
+		return domain.Commit{Owner: "octocat", Repository: "Hello-World", Hash: hash, Author: login, AuthorLogin: login, CommitDate: date, Additions: lines, EnrichedAt: &enrichedAt}
+	}
+	testCommits := []domain.Commit{commit("commit1", "alice", 4), commit("commit2", "alice", 3), commit("commit3", "alice", 3), commit("commit4", "bob", 100), commit("commit5", "carol", 5)}
+	for _, c := range testCommits {
+		assert.NoError(t, db.Create(&c).Error)
+	}
+	file := func(hash, filename string, lines int) domain.CommitFile {
+		return domain.CommitFile{Owner: "octocat", Repository: "Hello-World", CommitHash: hash, Filename: filename, Additions: lines}
+	}
+	testFiles := []domain.CommitFile{file("commit1", "src/a.go", 4), file("commit2", "src/a.go", 3), file("commit3", "src/a.go", 3), file("commit4", "docs/guide.md", 100), file("commit5", "src/b.go", 5), file("commit5", "logo.png", 0)}
+	for _, f := range testFiles {
+		assert.NoError(t, db.Create(&f).Error)
+	}
+
+	// Every commit is enriched, so lines are measured: Bob wrote most of them
+	report, err := repositoryService.GetBusFactor(ctx, "octocat", "Hello-World", time.Time{}, time.Time{}, 50, "")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BusFactorLines, report.Repository.Metric)
+	assert.Equal(t, 115, report.Repository.Total)
+	assert.Equal(t, 3, report.Repository.Authors)
+	assert.Equal(t, 1, report.Repository.BusFactor)
+	assert.Equal(t, "bob", report.Repository.KeyAuthors[0].Identity)
+
+	// By commits, Alice alone has 60% and it takes Bob as well to reach 80%
+	report, err = repositoryService.GetBusFactor(ctx, "octocat", "Hello-World", time.Time{}, time.Time{}, 80, domain.BusFactorCommits)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Repository.Total)
+	assert.Equal(t, 2, report.Repository.BusFactor)
+	assert.Equal(t, []string{"alice", "bob"}, []string{report.Repository.KeyAuthors[0].Identity, report.Repository.KeyAuthors[1].Identity})
+	assert.InDelta(t, 60, report.Repository.KeyAuthors[0].Share, 0.001)
+
+	// Per top-level directory, from the changed files
+	if assert.Len(t, report.Directories, 3) {
+		assert.Equal(t, ".", report.Directories[0].Directory)
+		assert.Equal(t, domain.BusFactorFiles, report.Directories[0].Metric)
+		assert.Equal(t, "docs", report.Directories[1].Directory)
+		assert.Equal(t, "src", report.Directories[2].Directory)
+		assert.Equal(t, 2, report.Directories[2].BusFactor) // Alice has 10 of 15 lines, under 80%
+	}
+
+	// A commit without stats switches the automatic metric to commits
+	unenriched := domain.Commit{Owner: "octocat", Repository: "Hello-World", Hash: "commit6", Author: "dave", AuthorLogin: "dave", CommitDate: date}
+	assert.NoError(t, db.Create(&unenriched).Error)
+	report, err = repositoryService.GetBusFactor(ctx, "octocat", "Hello-World", time.Time{}, time.Time{}, 50, "")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BusFactorCommits, report.Repository.Metric)
+	assert.Equal(t, 6, report.Repository.Total)
+}
@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestChangelog(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Tag{}, &domain.Job{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	releaseRepo, err := postgresdb.NewReleaseRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	// c1 <- c2 (v1.0.0) <- c3 <- m4 (v1.1.0) <- c5, with b1 branching off c2 and merged in m4
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	commit := func(hash string, days int, commitType, subject string, parents ...string) domain.Commit {
+		return domain.Commit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: subject, Author: "Mona Lisa",
+			CommitDate: base.Add(time.Duration(days) * 24 * time.Hour), Parents: parents, CommitType: commitType, Subject: subject}
+	}
+	commits := []domain.Commit{
+		commit("c1", 0, "chore", "set up the project"),
+		commit("c2", 0, "feat", "add the punch card (#4)", "c1"),
+		commit("b1", 1, "fix", "handle empty repositories", "c2"),
+		commit("c3", 2, "feat", "drop the v1 routes", "c2"),
+		commit("m4", 3, "", "Merge branch 'fix-empty'", "c3", "b1"),
+		commit("c5", 4, "docs", "fix a typo", "m4"),
+	}
+	commits[1].Scope = "api"
+	commits[2].AuthorLogin = "hubot"
+	commits[3].Breaking, commits[3].PullNumber = true, 7
+	commits[5].CoAuthors = []domain.CommitPerson{{Name: "The Octocat", Email: "octocat@github.com"}}
+	assert.NoError(t, commitRepo.SaveCommits(ctx, commits))
+	assert.NoError(t, releaseRepo.SaveTags(ctx, []domain.Tag{
+		{Owner: "octocat", Repository: "hello-world", Name: "v1.0.0", CommitSHA: "c2"},
+		{Owner: "octocat", Repository: "hello-world", Name: "v1.1.0", CommitSHA: "m4"},
+		{Owner: "octocat", Repository: "hello-world", Name: "v0.9.0", CommitSHA: "unknown"},
+	}))
+
+	changelogService := service.NewChangelogService(commitRepo, releaseRepo, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	// Between two tags the changelog follows history, so the side branch counts and merges are left out
+	changelog, err := changelogService.GetChangelog(ctx, "octocat", "hello-world", "v1.0.0", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changelog.Total)
+	assert.Equal(t, "c2", changelog.From.SHA)
+	if assert.Len(t, changelog.BreakingChanges, 1) {
+		assert.Equal(t, "c3", changelog.BreakingChanges[0].SHA)
+		assert.Equal(t, 7, changelog.BreakingChanges[0].PullNumber)
+	}
+	if assert.Len(t, changelog.Fixes, 1) {
+		assert.Equal(t, []string{"@hubot"}, changelog.Fixes[0].Authors)
+	}
+	assert.Empty(t, changelog.Features)
+	assert.Equal(t, []string{"@hubot", "Mona Lisa"}, changelog.Contributors)
+
+	// Without an upper bound it runs to the latest commit, crediting co-authors
+	changelog, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "c3", "")
+	assert.NoError(t, err)
+	assert.Nil(t, changelog.To)
+	assert.Equal(t, 2, changelog.Total)
+	if assert.Len(t, changelog.Fixes, 1) {
+		assert.Equal(t, "b1", changelog.Fixes[0].SHA)
+	}
+	if assert.Len(t, changelog.Other, 1) {
+		assert.Equal(t, []string{"Mona Lisa", "The Octocat"}, changelog.Other[0].Authors)
+	}
+
+	// A date bound covers the whole day, and the pull request is taken from the subject when the commit is not linked
+	changelog, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "", "2024-03-01")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changelog.Total)
+	if assert.Len(t, changelog.Features, 1) {
+		assert.Equal(t, "add the punch card", changelog.Features[0].Subject)
+		assert.Equal(t, 4, changelog.Features[0].PullNumber)
+	}
+	markdown := changelog.Markdown()
+	assert.Contains(t, markdown, "## Changes from the first commit to 2024-03-01\n")
+	assert.Contains(t, markdown, "### Features\n\n- **api:** add the punch card (#4) (c2) by Mona Lisa\n")
+	assert.NotContains(t, markdown, "### Fixes")
+
+	_, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "v2.0.0", "")
+	assert.ErrorIs(t, err, domain.ErrRefNotFound)
+	_, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "v0.9.0", "")
+	assert.ErrorIs(t, err, domain.ErrRefNotFound)
+}
+
+func TestChangelogOfUpgradedCommits(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// c1 <- c2 were stored before parents were, c3 (v1.0.0) <- c4 (v1.1.0) after
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	legacy := func(hash string, days int) legacyCommit {
+		return legacyCommit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: "fix: change " + hash, Author: "Mona Lisa",
+			CommitDate: base.Add(time.Duration(days) * 24 * time.Hour)}
+	}
+	migrateLegacyCommits(t, db, legacy("c1", 0), legacy("c2", 1))
+	assert.NoError(t, db.AutoMigrate(&domain.CommitBranch{}, &domain.Tag{}, &domain.Job{}, &domain.Backfill{}))
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	releaseRepo, err := postgresdb.NewReleaseRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	commit := func(hash string, days int, parents ...string) domain.Commit {
+		return domain.Commit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: "fix: change " + hash, Author: "Mona Lisa",
+			CommitDate: base.Add(time.Duration(days) * 24 * time.Hour), Parents: append([]string{}, parents...), CommitType: "fix", Subject: "change " + hash}
+	}
+	assert.NoError(t, commitRepo.SaveCommits(ctx, []domain.Commit{commit("c3", 2, "c2"), commit("c4", 3, "c3")}))
+	assert.NoError(t, releaseRepo.SaveTags(ctx, []domain.Tag{
+		{Owner: "octocat", Repository: "hello-world", Name: "v1.0.0", CommitSHA: "c3"},
+		{Owner: "octocat", Repository: "hello-world", Name: "v1.1.0", CommitSHA: "c4"},
+	}))
+	changelogService := service.NewChangelogService(commitRepo, releaseRepo, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	// History between the tags runs through the upgraded commits, so no changelog is returned and they are pulled again
+	_, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "v1.0.0", "v1.1.0")
+	assert.ErrorIs(t, err, domain.ErrHistoryIncomplete)
+	job, err := jobRepo.FindActiveJob(ctx, domain.JobBackfillParents, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+
+	// Merges cannot be told apart among the upgraded commits a date range holds either
+	_, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "2024-03-01", "")
+	assert.ErrorIs(t, err, domain.ErrHistoryIncomplete)
+	changelog, err := changelogService.GetChangelog(ctx, "octocat", "hello-world", "2024-03-03", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changelog.Total)
+
+	// Once their parents are pulled again, the changelog follows history
+	github := &fakeHistoryGithub{commits: []domain.Commit{commit("c1", 0), commit("c2", 1, "c1")}}
+	commitService := service.NewCommitService(commitRepo, backfillRepo, &config.Config{}, github)
+	missing, err := commitService.BackfillParents(ctx, "octocat", "hello-world", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), missing.Count)
+
+	changelog, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "v1.0.0", "v1.1.0")
+	assert.NoError(t, err)
+	if assert.Len(t, changelog.Fixes, 1) {
+		assert.Equal(t, "c4", changelog.Fixes[0].SHA)
+	}
+	changelog, err = changelogService.GetChangelog(ctx, "octocat", "hello-world", "", "v1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, changelog.Total)
+}
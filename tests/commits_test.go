@@ -2,8 +2,10 @@ package repository_test
 
 import (
 	"context"
+	"github-service/config"
 	"github-service/internal/adapters/postgresdb"
 	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
 	"testing"
 	"time"
 
@@ -27,11 +29,16 @@ func TestSaveCommit(t *testing.T) {
 
 	// Create a test commit
 	testCommit := &domain.Commit{
-		URL:        "https://api.github.com/repos/octocat/Hello-World/commits/6dcb09b5b57875f334f61aebed695e2e4193db5e",
-		Message:    "Fix all the bugs",
-		Author:     "Monalisa Octocat",
-		CommitDate: time.Now(),
-		Repository: "Hello-World",
+		URL:            "https://api.github.com/repos/octocat/Hello-World/commits/6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		Message:        "Fix all the bugs",
+		Author:         "Monalisa Octocat",
+		AuthorLogin:    "octocat",
+		AuthorDate:     time.Now().Add(-time.Hour),
+		Committer:      "GitHub",
+		CommitterEmail: "noreply@github.com",
+		CommitDate:     time.Now(),
+		Parents:        []string{"553c2077f0edc3d5dc5d17262f6aa498e69d6f8e", "762941318ee16e59dabbacb1b4049eec22f0d303"},
+		Repository:     "Hello-World",
 	}
 
 	// Save the commit
@@ -52,6 +59,10 @@ func TestSaveCommit(t *testing.T) {
 	assert.Equal(t, testCommit.URL, savedCommit.URL)
 	assert.Equal(t, testCommit.Message, savedCommit.Message)
 	assert.Equal(t, testCommit.Author, savedCommit.Author)
+	assert.Equal(t, testCommit.AuthorLogin, savedCommit.AuthorLogin)
+	assert.Equal(t, testCommit.Committer, savedCommit.Committer)
+	assert.Equal(t, testCommit.Parents, savedCommit.Parents)
+	assert.WithinDuration(t, testCommit.AuthorDate, savedCommit.AuthorDate, time.Second, "Author dates should be within 1 second of each other")
 	assert.WithinDuration(t, testCommit.CommitDate, savedCommit.CommitDate, time.Second, "Commit dates should be within 1 second of each other")
 	assert.Equal(t, testCommit.Repository, savedCommit.Repository)
 }
@@ -72,6 +83,8 @@ func TestMultipleCommits(t *testing.T) {
 	// Create test commits
 	testCommits := []domain.Commit{
 		{
+			Owner:      "octocat",
+			Hash:       "commit1",
 			URL:        "https://api.github.com/repos/octocat/Hello-World/commits/commit1",
 			Message:    "First commit",
 			Author:     "Alice",
@@ -79,6 +92,8 @@ func TestMultipleCommits(t *testing.T) {
 			Repository: "Hello-World",
 		},
 		{
+			Owner:      "octocat",
+			Hash:       "commit2",
 			URL:        "https://api.github.com/repos/octocat/Hello-World/commits/commit2",
 			Message:    "Second commit",
 			Author:     "Bob",
@@ -86,6 +101,8 @@ func TestMultipleCommits(t *testing.T) {
 			Repository: "Hello-World",
 		},
 		{
+			Owner:      "octocat",
+			Hash:       "commit3",
 			URL:        "https://api.github.com/repos/octocat/Another-Repo/commits/commit3",
 			Message:    "Third commit",
 			Author:     "Charlie",
@@ -101,20 +118,21 @@ func TestMultipleCommits(t *testing.T) {
 	}
 
 	// Test GetTotalCommits
-	totalHelloWorld, err := repo.GetTotalCommits(ctx, "Hello-World")
+	totalHelloWorld, err := repo.GetTotalCommits(ctx, "octocat", "Hello-World", domain.CommitFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, int64(2), totalHelloWorld)
 
-	totalAnotherRepo, err := repo.GetTotalCommits(ctx, "Another-Repo")
+	totalAnotherRepo, err := repo.GetTotalCommits(ctx, "octocat", "Another-Repo", domain.CommitFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), totalAnotherRepo)
 
-	// Test GetCommits (pagination)
-	commitsHelloWorld, err := repo.GetCommits(ctx, "Hello-World", 1, 10)
+	// Test GetCommits (pagination), oldest first
+	oldestFirst := domain.CommitFilter{Sort: domain.CommitSortDate}
+	commitsHelloWorld, err := repo.GetCommits(ctx, "octocat", "Hello-World", oldestFirst, 1, 10)
 	assert.NoError(t, err)
 	assert.Len(t, commitsHelloWorld, 2)
 
-	commitsAnotherRepo, err := repo.GetCommits(ctx, "Another-Repo", 1, 10)
+	commitsAnotherRepo, err := repo.GetCommits(ctx, "octocat", "Another-Repo", oldestFirst, 1, 10)
 	assert.NoError(t, err)
 	assert.Len(t, commitsAnotherRepo, 1)
 
@@ -124,13 +142,188 @@ func TestMultipleCommits(t *testing.T) {
 	assert.Equal(t, testCommits[2].Message, commitsAnotherRepo[0].Message)
 
 	// Test pagination
-	commitsHelloWorldPage1, err := repo.GetCommits(ctx, "Hello-World", 1, 1)
+	commitsHelloWorldPage1, err := repo.GetCommits(ctx, "octocat", "Hello-World", oldestFirst, 1, 1)
 	assert.NoError(t, err)
 	assert.Len(t, commitsHelloWorldPage1, 1)
 	assert.Equal(t, testCommits[0].Message, commitsHelloWorldPage1[0].Message)
 
-	commitsHelloWorldPage2, err := repo.GetCommits(ctx, "Hello-World", 2, 1)
+	commitsHelloWorldPage2, err := repo.GetCommits(ctx, "octocat", "Hello-World", oldestFirst, 2, 1)
 	assert.NoError(t, err)
 	assert.Len(t, commitsHelloWorldPage2, 1)
 	assert.Equal(t, testCommits[1].Message, commitsHelloWorldPage2[0].Message)
 }
+
+func TestSaveCommitsIsIdempotent(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{})
+	assert.NoError(t, err)
+
+	// Create repository instance
+	repo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+
+	// Two overlapping pages of the same repository, as fetched by consecutive polls
+	firstPoll := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Message: "First commit", Author: "Alice", CommitDate: time.Now()},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Message: "Second commit", Author: "Bob", CommitDate: time.Now()},
+	}
+	secondPoll := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Message: "Second commit, reworded", Author: "Bob", CommitDate: time.Now()},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Message: "Third commit", Author: "Alice", CommitDate: time.Now()},
+		// Same hash in a fork is a different commit
+		{Owner: "alice", Repository: "Hello-World", Hash: "commit1", Message: "First commit", Author: "Alice", CommitDate: time.Now()},
+	}
+
+	assert.NoError(t, repo.SaveCommits(ctx, firstPoll))
+	assert.NoError(t, repo.SaveCommits(ctx, secondPoll))
+	assert.NoError(t, repo.SaveCommit(ctx, &firstPoll[0]))
+
+	// Each commit is stored once
+	var count int64
+	db.Model(&domain.Commit{}).Count(&count)
+	assert.Equal(t, int64(4), count, "Expected overlapping commits to be stored once")
+
+	// The overlapping commit was updated in place
+	var updated domain.Commit
+	err = db.Where("owner = ? AND repository = ? AND hash = ?", "octocat", "Hello-World", "commit2").First(&updated).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "Second commit, reworded", updated.Message)
+}
+
+func TestFilterCommits(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{})
+	assert.NoError(t, err)
+
+	// Create repository instance
+	repo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+
+	sprint := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	commits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Message: "Fix login bug", Author: "Alice", AuthorLogin: "alice", Email: "alice@example.com", CommitDate: sprint.Add(-48 * time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Message: "Add 100% coverage", Author: "Alice", AuthorLogin: "alice", Email: "alice@example.com", CommitDate: sprint.Add(24 * time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Message: "fix typo", Author: "Bob", Email: "bob@example.com", CommitDate: sprint.Add(48 * time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", Message: "Fix flaky test", Author: "Alice", AuthorLogin: "alice", Email: "alice@example.com", CommitDate: sprint.Add(72 * time.Hour)},
+	}
+	assert.NoError(t, repo.SaveCommits(ctx, commits))
+
+	testCases := []struct {
+		name           string
+		filter         domain.CommitFilter
+		expectedHashes []string
+	}{
+		{"newest first by default", domain.CommitFilter{}, []string{"commit4", "commit3", "commit2", "commit1"}},
+		{"oldest first", domain.CommitFilter{Sort: domain.CommitSortDate}, []string{"commit1", "commit2", "commit3", "commit4"}},
+		{"author name", domain.CommitFilter{Author: "alice"}, []string{"commit4", "commit2", "commit1"}},
+		{"author login", domain.CommitFilter{Author: "ALICE"}, []string{"commit4", "commit2", "commit1"}},
+		{"email", domain.CommitFilter{Email: "Bob@Example.com"}, []string{"commit3"}},
+		{"time range", domain.CommitFilter{Since: sprint, Until: sprint.Add(48 * time.Hour)}, []string{"commit3", "commit2"}},
+		{"message", domain.CommitFilter{MessageContains: "FIX"}, []string{"commit4", "commit3", "commit1"}},
+		{"wildcard in message", domain.CommitFilter{MessageContains: "100%"}, []string{"commit2"}},
+		{"author last sprint", domain.CommitFilter{Author: "Alice", Since: sprint, Until: sprint.Add(7 * 24 * time.Hour)}, []string{"commit4", "commit2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			found, err := repo.GetCommits(ctx, "octocat", "Hello-World", tc.filter, 1, 10)
+			assert.NoError(t, err)
+			hashes := make([]string, len(found))
+			for i, commit := range found {
+				hashes[i] = commit.Hash
+			}
+			assert.Equal(t, tc.expectedHashes, hashes)
+
+			// The count applies the same filter, so total pages match the listed commits
+			total, err := repo.GetTotalCommits(ctx, "octocat", "Hello-World", tc.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(len(tc.expectedHashes)), total)
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{})
+	assert.NoError(t, err)
+
+	// Create repository and service instances
+	repo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	commitService := service.NewCommitService(repo, nil, &config.Config{}, nil)
+
+	// Five commits, two of them made in the same second
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	commits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", CommitDate: day},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", CommitDate: day.Add(time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", CommitDate: day.Add(time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", CommitDate: day.Add(2 * time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit5", CommitDate: day.Add(3 * time.Hour)},
+	}
+	assert.NoError(t, repo.SaveCommits(ctx, commits))
+
+	hashes := func(page domain.PaginatedResponse) []string {
+		result := make([]string, len(page.Commits))
+		for i, commit := range page.Commits {
+			result[i] = commit.Hash
+		}
+		return result
+	}
+
+	// Walk forward, newest first
+	first, err := commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit5", "commit4"}, hashes(first))
+	assert.Empty(t, first.PrevCursor)
+
+	// A commit arriving mid-scan does not shift the following pages
+	assert.NoError(t, repo.SaveCommits(ctx, []domain.Commit{{Owner: "octocat", Repository: "Hello-World", Hash: "commit6", CommitDate: day.Add(4 * time.Hour)}}))
+
+	second, err := commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, first.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit3", "commit2"}, hashes(second))
+
+	third, err := commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, second.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit1"}, hashes(third))
+	assert.Empty(t, third.NextCursor)
+
+	// Walk back from the last page
+	back, err := commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, third.PrevCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit3", "commit2"}, hashes(back))
+
+	back, err = commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, back.PrevCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit5", "commit4"}, hashes(back))
+
+	// The commit that arrived mid-scan is before the start of the scan
+	back, err = commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, back.PrevCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit6"}, hashes(back))
+	assert.Empty(t, back.PrevCursor)
+
+	// A cursor keeps the order of its listing
+	oldest, err := commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{Sort: domain.CommitSortDate}, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit1", "commit2"}, hashes(oldest))
+	next, err := commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, oldest.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"commit3", "commit4"}, hashes(next))
+
+	_, err = commitService.GetCommitsByCursor(ctx, "octocat", "Hello-World", domain.CommitFilter{}, "not-a-cursor", 2)
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
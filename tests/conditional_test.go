@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	httpclient "github-service/pkg/httpClient"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// versionedServer serves a resource under the current ETag, answering 304 when the request already has it,
+// and records the validators each request was sent with
+type versionedServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	etag       string
+	validators [][2]string
+}
+
+func newVersionedServer() *versionedServer {
+	s := &versionedServer{etag: `"v1"`}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.validators = append(s.validators, [2]string{r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")})
+		if r.Header.Get("If-None-Match") == s.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Write([]byte(s.etag))
+	}))
+	return s
+}
+
+func (s *versionedServer) update(etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etag = etag
+}
+
+func (s *versionedServer) sent() [][2]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][2]string(nil), s.validators...)
+}
+
+func TestClientSendsConditionalRequests(t *testing.T) {
+	server := newVersionedServer()
+	defer server.Close()
+	client := httpclient.NewClient(nil, nil)
+	ctx := context.Background()
+	url := server.URL + "/repos/octocat/hello-world"
+
+	body, err := client.ApiCall(ctx, http.MethodGet, url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `"v1"`, string(body))
+
+	// The validators of the last response are sent back, and 304 is reported as not modified
+	_, err = client.ApiCall(ctx, http.MethodGet, url, nil)
+	assert.ErrorIs(t, err, httpclient.ErrNotModified)
+
+	// A changed resource is returned in full along with its new validators
+	server.update(`"v2"`)
+	body, err = client.ApiCall(ctx, http.MethodGet, url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `"v2"`, string(body))
+	_, err = client.ApiCall(ctx, http.MethodGet, url, nil)
+	assert.ErrorIs(t, err, httpclient.ErrNotModified)
+
+	lastModified := "Mon, 01 Jan 2024 00:00:00 GMT"
+	assert.Equal(t, [][2]string{
+		{"", ""},
+		{`"v1"`, lastModified},
+		{`"v1"`, lastModified},
+		{`"v2"`, lastModified},
+	}, server.sent())
+}
+
+func TestClientForgetsValidators(t *testing.T) {
+	server := newVersionedServer()
+	defer server.Close()
+	client := httpclient.NewClient(nil, nil)
+	ctx := context.Background()
+	metadata := server.URL + "/repos/octocat/hello-world"
+	commits := server.URL + "/repos/octocat/hello-world/commits?per_page=100"
+
+	for _, url := range []string{metadata, commits} {
+		_, err := client.ApiCall(ctx, http.MethodGet, url, nil)
+		assert.NoError(t, err)
+	}
+
+	// A forgotten URL is fetched in full again, the others are still revalidated
+	client.Forget(metadata)
+	_, err := client.ApiCall(ctx, http.MethodGet, metadata, nil)
+	assert.NoError(t, err)
+	_, err = client.ApiCall(ctx, http.MethodGet, commits, nil)
+	assert.ErrorIs(t, err, httpclient.ErrNotModified)
+
+	// Forgetting a prefix forgets every URL below it
+	client.ForgetPrefix(server.URL + "/repos/octocat/hello-world/commits?")
+	_, err = client.ApiCall(ctx, http.MethodGet, commits, nil)
+	assert.NoError(t, err)
+	_, err = client.ApiCall(ctx, http.MethodGet, metadata, nil)
+	assert.ErrorIs(t, err, httpclient.ErrNotModified)
+}
+
+func TestClientSendsPostsUnconditionally(t *testing.T) {
+	server := newVersionedServer()
+	defer server.Close()
+	client := httpclient.NewClient(nil, nil)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ApiCall(context.Background(), http.MethodPost, server.URL, []byte("{}"))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, [][2]string{{"", ""}, {"", ""}}, server.sent())
+}
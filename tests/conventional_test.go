@@ -0,0 +1,132 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/conventional"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	testCases := []struct {
+		message  string
+		expected conventional.Message
+	}{
+		{
+			message:  "feat(api): add punch card endpoint",
+			expected: conventional.Message{Type: "feat", Scope: "api", Subject: "add punch card endpoint"},
+		},
+		{
+			message:  "Fix!: drop the v1 routes\n\nThe v1 routes were deprecated a year ago.",
+			expected: conventional.Message{Type: "fix", Breaking: true, Subject: "drop the v1 routes"},
+		},
+		{
+			message: "refactor: rename the job types\n\nBREAKING CHANGE: jobs queued before the upgrade are dropped\n" +
+				"Co-authored-by: Jane Doe <jane@example.com>\nSigned-off-by: John Roe <john@example.com>\nSigned-off-by: Jane Doe <jane@example.com>",
+			expected: conventional.Message{
+				Type: "refactor", Breaking: true, Subject: "rename the job types",
+				CoAuthors:   []conventional.Person{{Name: "Jane Doe", Email: "jane@example.com"}},
+				SignedOffBy: []conventional.Person{{Name: "John Roe", Email: "john@example.com"}, {Name: "Jane Doe", Email: "jane@example.com"}},
+			},
+		},
+		{
+			// Not a trailer block: the last paragraph is prose
+			message:  "Merge pull request #6 from octocat/patch-1\n\nThanks to\nCo-authored-by: Jane Doe <jane@example.com>",
+			expected: conventional.Message{Subject: "Merge pull request #6 from octocat/patch-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, conventional.Parse(tc.message), tc.message)
+	}
+}
+
+func TestCommitTypeActivity(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	commitService := service.NewCommitService(commitRepo, nil, &config.Config{}, nil)
+
+	// Commits stored before messages were parsed on ingestion
+	week := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	testCommits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Message: "feat: add activity stats", CommitDate: week},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Message: "fix(api): handle empty windows", CommitDate: week},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Message: "feat!: replace the commit listing", CommitDate: week.AddDate(0, 0, 14)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", Message: "Update README.md\n\nCo-authored-by: Jane Doe <jane@example.com>", CommitDate: week.AddDate(0, 0, 14)},
+		{Owner: "octocat", Repository: "Spoon-Knife", Hash: "commit5", Message: "chore: bump dependencies", CommitDate: week},
+	}
+	for _, commit := range testCommits {
+		assert.NoError(t, db.Create(&commit).Error)
+	}
+
+	parsed, err := commitService.ParseCommitMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, parsed)
+	parsed, err = commitService.ParseCommitMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, parsed)
+
+	commit, err := commitRepo.GetCommit(ctx, "octocat", "Hello-World", "commit4")
+	assert.NoError(t, err)
+	assert.Equal(t, "Update README.md", commit.Subject)
+	assert.Equal(t, []domain.CommitPerson{{Name: "Jane Doe", Email: "jane@example.com"}}, commit.CoAuthors)
+
+	activity, err := commitService.GetCommitTypeActivity(ctx, "octocat", "Hello-World", domain.ActivityWeek, time.Time{}, week.AddDate(0, 0, 14))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, activity.Total)
+	assert.Equal(t, 3, activity.Conventional)
+	assert.Equal(t, []int{0, 0, 1}, []int{activity.Breaking[0].Count, activity.Breaking[1].Count, activity.Breaking[2].Count})
+	if assert.Len(t, activity.Types, 3) {
+		assert.Equal(t, "feat", activity.Types[0].Type)
+		assert.Equal(t, 2, activity.Types[0].Total)
+		assert.InDelta(t, 50, activity.Types[0].Share, 0.001)
+		assert.Equal(t, []int{1, 0, 1}, []int{activity.Types[0].Buckets[0].Count, activity.Types[0].Buckets[1].Count, activity.Types[0].Buckets[2].Count})
+		assert.Equal(t, "fix", activity.Types[1].Type)
+		assert.Equal(t, domain.CommitTypeOther, activity.Types[2].Type)
+	}
+}
+
+func TestParseMessagesOfUpgradedCommits(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// The message parts of commits stored before they were kept are NULL
+	week := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	migrateLegacyCommits(t, db,
+		legacyCommit{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Message: "feat(api): add activity stats", CommitDate: week},
+		legacyCommit{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Message: "Update README.md", CommitDate: week},
+	)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	commitService := service.NewCommitService(commitRepo, nil, &config.Config{}, nil)
+
+	parsed, err := commitService.ParseCommitMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, parsed)
+	parsed, err = commitService.ParseCommitMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, parsed)
+
+	commit, err := commitRepo.GetCommit(ctx, "octocat", "Hello-World", "commit1")
+	assert.NoError(t, err)
+	assert.Equal(t, "feat", commit.CommitType)
+	assert.Equal(t, "api", commit.Scope)
+	assert.Equal(t, "add activity stats", commit.Subject)
+}
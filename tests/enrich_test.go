@@ -0,0 +1,176 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/config"
+	githubclient "github-service/internal/adapters/github"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestEnrichCommits(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.CommitFile{}, &domain.Backfill{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	commits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Message: "First commit", Author: "Alice", CommitDate: time.Now()},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Message: "Second commit", Author: "Bob", CommitDate: time.Now()},
+	}
+	err = commitRepo.SaveCommits(ctx, commits)
+	assert.NoError(t, err)
+
+	commitService := service.NewCommitService(commitRepo, backfillRepo, &config.Config{}, &fakeGithub{})
+
+	enriched, err := commitService.EnrichCommits(ctx, "octocat", "Hello-World")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, enriched)
+
+	// Enriched commits are not picked up again
+	enriched, err = commitService.EnrichCommits(ctx, "octocat", "Hello-World")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, enriched)
+
+	detail, err := commitService.GetCommit(ctx, "octocat", "Hello-World", "commit1")
+	assert.NoError(t, err)
+	assert.NotNil(t, detail.EnrichedAt)
+	assert.Equal(t, "First commit", detail.Message)
+	assert.Equal(t, 12, detail.Additions)
+	assert.Equal(t, 3, detail.Deletions)
+	assert.Equal(t, 15, detail.Changes)
+	if assert.Len(t, detail.Files, 2) {
+		assert.Equal(t, "README.md", detail.Files[0].Filename)
+		assert.Equal(t, "added", detail.Files[0].Status)
+		assert.Equal(t, "main.go", detail.Files[1].Filename)
+	}
+
+	// Saving the commit again from the commit list keeps its stats
+	err = commitRepo.SaveCommits(ctx, commits[:1])
+	assert.NoError(t, err)
+	saved, err := commitRepo.GetCommit(ctx, "octocat", "Hello-World", "commit1")
+	assert.NoError(t, err)
+	assert.Equal(t, 12, saved.Additions)
+	assert.NotNil(t, saved.EnrichedAt)
+
+	missing, err := commitService.GetCommit(ctx, "octocat", "Hello-World", "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+// fakeUnavailableGithub does not serve some commits, as after a force-push, and cannot be reached for others
+type fakeUnavailableGithub struct {
+	fakeGithub
+	unavailable map[string]bool
+	unreachable map[string]bool
+}
+
+func (f *fakeUnavailableGithub) FetchCommitDetail(ctx context.Context, owner, repo, sha string) (*domain.CommitDetail, error) {
+	if f.unavailable[sha] {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCommitUnavailable, http.StatusText(http.StatusUnprocessableEntity))
+	}
+	if f.unreachable[sha] {
+		return nil, errors.New("connection reset")
+	}
+	return f.fakeGithub.FetchCommitDetail(ctx, owner, repo, sha)
+}
+
+func TestEnrichCommitsSkipsUnavailableCommits(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.CommitFile{}, &domain.Backfill{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	// The newest commit was force-pushed away and is enriched first
+	now := time.Now()
+	commits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "gone", Message: "Rewritten", CommitDate: now},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "older", Message: "Older", CommitDate: now.Add(-time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "flaky", Message: "Flaky", CommitDate: now.Add(-2 * time.Hour)},
+	}
+	assert.NoError(t, commitRepo.SaveCommits(ctx, commits))
+
+	github := &fakeUnavailableGithub{unavailable: map[string]bool{"gone": true}, unreachable: map[string]bool{"flaky": true}}
+	commitService := service.NewCommitService(commitRepo, backfillRepo, &config.Config{}, github)
+
+	// The older commit is enriched past the unavailable one, and a failure to reach GitHub still fails the run
+	enriched, err := commitService.EnrichCommits(ctx, "octocat", "Hello-World")
+	assert.Error(t, err)
+	assert.Equal(t, 1, enriched)
+
+	gone, err := commitRepo.GetCommit(ctx, "octocat", "Hello-World", "gone")
+	assert.NoError(t, err)
+	assert.NotNil(t, gone.EnrichedAt)
+	assert.Equal(t, 0, gone.Changes)
+	older, err := commitRepo.GetCommit(ctx, "octocat", "Hello-World", "older")
+	assert.NoError(t, err)
+	assert.Equal(t, 15, older.Changes)
+
+	// Reading a commit GitHub cannot be reached for returns it without stats rather than failing
+	detail, err := commitService.GetCommit(ctx, "octocat", "Hello-World", "flaky")
+	assert.NoError(t, err)
+	assert.Equal(t, "Flaky", detail.Message)
+	assert.Nil(t, detail.EnrichedAt)
+	assert.Empty(t, detail.Files)
+
+	// Once GitHub answers, reading it enriches it
+	github.unreachable = nil
+	detail, err = commitService.GetCommit(ctx, "octocat", "Hello-World", "flaky")
+	assert.NoError(t, err)
+	assert.NotNil(t, detail.EnrichedAt)
+	assert.Equal(t, 15, detail.Changes)
+}
+
+func TestFetchCommitDetailReportsUnavailableCommits(t *testing.T) {
+	// GitHub answers 422 for a SHA it no longer has and 404 for one it never had
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/octocat/hello-world/commits/gone":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case "/octocat/hello-world/commits/unknown":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	cfg := &config.Config{BASE_URL: server.URL, PER_PAGE: "100"}
+	github := service.NewGithubService(cfg, ctx, githubclient.NewGithubClient(cfg, ctx))
+
+	_, err := github.FetchCommitDetail(ctx, "octocat", "hello-world", "gone")
+	assert.ErrorIs(t, err, domain.ErrCommitUnavailable)
+	_, err = github.FetchCommitDetail(ctx, "octocat", "hello-world", "unknown")
+	assert.ErrorIs(t, err, domain.ErrCommitUnavailable)
+
+	// Other failures may go away on a retry
+	_, err = github.FetchCommitDetail(ctx, "octocat", "hello-world", "flaky")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrCommitUnavailable)
+}
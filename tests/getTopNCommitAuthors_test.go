@@ -2,8 +2,11 @@ package repository_test
 
 import (
 	"context"
+	"fmt"
+	"github-service/config"
 	"github-service/internal/adapters/postgresdb"
 	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
 	"time"
 
 	"testing"
@@ -19,7 +22,7 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 	assert.NoError(t, err)
 	ctx := context.Background()
 	// Auto migrate the schema
-	err = db.AutoMigrate(&postgresdb.Commit{})
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Identity{})
 	assert.NoError(t, err)
 
 	// Create repository instance
@@ -28,13 +31,15 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 
 	// Create test commits
 	testCommits := []domain.Commit{
-		{Author: "Alice", CommitDate: time.Now(), Repository: "Hello-World"},
-		{Author: "Alice", CommitDate: time.Now(), Repository: "Hello-World"},
-		{Author: "Bob", CommitDate: time.Now(), Repository: "Hello-World"},
-		{Author: "Charlie", CommitDate: time.Now(), Repository: "Hello-World"},
-		{Author: "Alice", CommitDate: time.Now(), Repository: "Hello-World"},
-		{Author: "Bob", CommitDate: time.Now(), Repository: "Hello-World"},
-		{Author: "David", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit1", Author: "Alice", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit2", Author: "Alice", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit3", Author: "Bob", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit4", Author: "Charlie", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit5", Author: "Alice", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit6", Author: "Bob", CommitDate: time.Now(), Repository: "Hello-World"},
+		{Owner: "octocat", Hash: "commit7", Author: "David", CommitDate: time.Now(), Repository: "Hello-World"},
+		// A fork with the same name must not be counted
+		{Owner: "alice", Hash: "commit8", Author: "Eve", CommitDate: time.Now(), Repository: "Hello-World"},
 	}
 
 	// Save all commits
@@ -45,6 +50,7 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 
 	// Test cases
 	testCases := []struct {
+		owner         string
 		repo          string
 		name          string
 		page          int
@@ -53,6 +59,7 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 		expectedOrder []string
 	}{
 		{
+			owner:         "octocat",
 			repo:          "Hello-World",
 			name:          "First page, limit 2",
 			page:          1,
@@ -61,6 +68,7 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 			expectedOrder: []string{"Alice", "Bob"},
 		},
 		{
+			owner:         "octocat",
 			repo:          "Hello-World",
 			name:          "Second page, limit 2",
 			page:          2,
@@ -69,6 +77,7 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 			expectedOrder: []string{"Charlie", "David"},
 		},
 		{
+			owner:         "octocat",
 			repo:          "Hello-World",
 			name:          "All authors, single page",
 			page:          1,
@@ -80,7 +89,7 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			authors, err := repo.GetTopNCommitAuthors(ctx, tc.repo, tc.page, tc.limit)
+			authors, err := repo.GetTopNCommitAuthors(ctx, tc.owner, tc.repo, time.Time{}, time.Time{}, tc.page, tc.limit)
 			assert.NoError(t, err)
 			assert.Len(t, authors, tc.expectedCount)
 
@@ -97,3 +106,153 @@ func TestGetTopNCommitAuthors(t *testing.T) {
 		})
 	}
 }
+
+func TestTopCommitAuthorsByIdentity(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	// Create repository instance
+	repo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+
+	quarter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	enrichedAt := time.Now()
+	testCommits := []domain.Commit{
+		// Alice commits under two display names from the same account
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Author: "Alice", AuthorLogin: "alice", Email: "alice@example.com", AuthorDate: quarter.Add(time.Hour), CommitDate: quarter.Add(time.Hour), Additions: 10, Deletions: 2, EnrichedAt: &enrichedAt},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Author: "Alice Smith", AuthorLogin: "alice", Email: "alice@work.example.com", AuthorDate: quarter.Add(2 * time.Hour), CommitDate: quarter.Add(2 * time.Hour), Additions: 5, Deletions: 1, EnrichedAt: &enrichedAt},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Author: "Alice", AuthorLogin: "alice", Email: "alice@example.com", AuthorDate: quarter.Add(50 * time.Hour), CommitDate: quarter.Add(50 * time.Hour)},
+		// Bob has no GitHub account, so his email identifies him whatever its case
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", Author: "Bob", Email: "Bob@Example.com", AuthorDate: quarter.Add(3 * time.Hour), CommitDate: quarter.Add(3 * time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit5", Author: "bob", Email: "bob@example.com", AuthorDate: quarter.Add(4 * time.Hour), CommitDate: quarter.Add(4 * time.Hour)},
+		// Outside the quarter
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit6", Author: "Bob", Email: "bob@example.com", AuthorDate: quarter.Add(-time.Hour), CommitDate: quarter.Add(-time.Hour)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit7", Author: "Carol", Email: "carol@example.com", AuthorDate: quarter.AddDate(0, 3, 1), CommitDate: quarter.AddDate(0, 3, 1)},
+	}
+	for _, commit := range testCommits {
+		err = db.Create(&commit).Error
+		assert.NoError(t, err)
+	}
+
+	authors, err := repo.GetTopNCommitAuthors(ctx, "octocat", "Hello-World", quarter, quarter.AddDate(0, 3, 0), 1, 10)
+	assert.NoError(t, err)
+	if !assert.Len(t, authors, 2) {
+		return
+	}
+
+	alice := authors[0]
+	assert.Equal(t, "alice", alice.Identity)
+	assert.Equal(t, "alice", alice.Login)
+	assert.Equal(t, 3, alice.Count)
+	assert.Equal(t, 2, alice.ActiveDays)
+	assert.True(t, quarter.Add(time.Hour).Equal(alice.FirstCommit))
+	assert.True(t, quarter.Add(50*time.Hour).Equal(alice.LastCommit))
+	assert.Equal(t, 15, alice.LinesAdded)
+	assert.Equal(t, 3, alice.LinesRemoved)
+	assert.Equal(t, 2, alice.EnrichedCommits)
+
+	bob := authors[1]
+	assert.Equal(t, "bob@example.com", bob.Identity)
+	assert.Equal(t, 2, bob.Count)
+	assert.Equal(t, 1, bob.ActiveDays)
+	assert.Equal(t, 0, bob.EnrichedCommits)
+}
+
+func TestTopNCommitAuthorsPages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	repo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	repositoryService := service.NewRepositoryService(repo, service.CommitService{}, &config.Config{}, nil, nil)
+
+	// Alice 4 commits, Bob 3, Charlie 2 and David 1
+	hash := 0
+	for author, count := range map[string]int{"Alice": 4, "Bob": 3, "Charlie": 2, "David": 1} {
+		for i := 0; i < count; i++ {
+			hash++
+			commit := domain.Commit{Owner: "octocat", Repository: "Hello-World", Hash: fmt.Sprintf("commit%d", hash), Author: author, AuthorDate: time.Now(), CommitDate: time.Now()}
+			assert.NoError(t, db.Create(&commit).Error)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		n             int
+		page          int
+		limit         int
+		expectedOrder []string
+	}{
+		{name: "First page", n: 3, page: 1, limit: 2, expectedOrder: []string{"Alice", "Bob"}},
+		// The last page keeps the offset of the requested limit and stops at the n-th author
+		{name: "Last page", n: 3, page: 2, limit: 2, expectedOrder: []string{"Charlie"}},
+		{name: "Past the n-th author", n: 3, page: 3, limit: 2, expectedOrder: []string{}},
+		{name: "Page ending at the n-th author", n: 4, page: 2, limit: 2, expectedOrder: []string{"Charlie", "David"}},
+		{name: "Fewer authors than n", n: 10, page: 1, limit: 10, expectedOrder: []string{"Alice", "Bob", "Charlie", "David"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authors, err := repositoryService.GetTopNCommitAuthors(ctx, "octocat", "Hello-World", time.Time{}, time.Time{}, tc.n, tc.page, tc.limit)
+			assert.NoError(t, err)
+			names := []string{}
+			for _, author := range authors {
+				names = append(names, author.Author)
+			}
+			assert.Equal(t, tc.expectedOrder, names)
+		})
+	}
+}
+
+func TestTopCommitAuthorsByAuthorDate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Identity{})
+	assert.NoError(t, err)
+
+	repo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+
+	quarter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCommits := []domain.Commit{
+		// Authored in the quarter and rebased onto the default branch after it
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Author: "Alice", Email: "alice@example.com", AuthorDate: quarter.Add(time.Hour), CommitDate: quarter.AddDate(0, 3, 2)},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Author: "Alice", Email: "alice@example.com", AuthorDate: quarter.Add(26 * time.Hour), CommitDate: quarter.AddDate(0, 3, 2)},
+		// Authored before the quarter and applied in it
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Author: "Bob", Email: "bob@example.com", AuthorDate: quarter.Add(-time.Hour), CommitDate: quarter.Add(time.Hour)},
+	}
+	for _, commit := range testCommits {
+		assert.NoError(t, db.Create(&commit).Error)
+	}
+	// Commits stored before author dates were fall back to their commit date
+	stale := domain.Commit{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", Author: "Carol", Email: "carol@example.com", CommitDate: quarter.Add(2 * time.Hour)}
+	assert.NoError(t, db.Create(&stale).Error)
+	assert.NoError(t, db.Model(&postgresdb.Commit{}).Where("hash = ?", "commit4").Update("author_date", nil).Error)
+
+	authors, err := repo.GetTopNCommitAuthors(ctx, "octocat", "Hello-World", quarter, quarter.AddDate(0, 3, 0), 1, 10)
+	assert.NoError(t, err)
+	if !assert.Len(t, authors, 2) {
+		return
+	}
+
+	alice := authors[0]
+	assert.Equal(t, "alice@example.com", alice.Identity)
+	assert.Equal(t, 2, alice.Count)
+	assert.Equal(t, 2, alice.ActiveDays)
+	assert.True(t, quarter.Add(time.Hour).Equal(alice.FirstCommit))
+	assert.True(t, quarter.Add(26*time.Hour).Equal(alice.LastCommit))
+
+	carol := authors[1]
+	assert.Equal(t, "carol@example.com", carol.Identity)
+	assert.Equal(t, 1, carol.Count)
+	assert.True(t, quarter.Add(2*time.Hour).Equal(carol.FirstCommit))
+}
@@ -0,0 +1,224 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github-service/config"
+	githubclient "github-service/internal/adapters/github"
+	"github-service/internal/core/domain"
+	httpclient "github-service/pkg/httpClient"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	header := `<https://api.github.com/repositories/1/commits?page=2>; rel="next", ` +
+		`<https://api.github.com/repositories/1/commits?page=5>; rel="last", ` +
+		`<https://api.github.com/repositories/1/commits?page=1>; rel="first prev"`
+	links := httpclient.ParseLinkHeader(header)
+	assert.Equal(t, map[string]string{
+		"next":  "https://api.github.com/repositories/1/commits?page=2",
+		"last":  "https://api.github.com/repositories/1/commits?page=5",
+		"first": "https://api.github.com/repositories/1/commits?page=1",
+		"prev":  "https://api.github.com/repositories/1/commits?page=1",
+	}, links)
+
+	// Malformed parts are skipped
+	assert.Empty(t, httpclient.ParseLinkHeader(""))
+	assert.Empty(t, httpclient.ParseLinkHeader(`https://api.github.com/commits?page=2; rel="next"`))
+	assert.Empty(t, httpclient.ParseLinkHeader(`<https://api.github.com/commits?page=2>`))
+
+	// The last page has no next link
+	assert.Equal(t, "", httpclient.NextPageURL(http.Header{"Link": {`<https://api.github.com/commits?page=1>; rel="first"`}}))
+}
+
+// pagedCommitsServer serves the given number of pages of commits, linking each page to the next one, and records the
+// queries it was sent
+type pagedCommitsServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	queries []string
+}
+
+func newPagedCommitsServer(pages int) *pagedCommitsServer {
+	s := &pagedCommitsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.queries = append(s.queries, r.URL.RawQuery)
+		s.mu.Unlock()
+
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		if page < pages {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=%s&page=%d>; rel="next"`, s.URL, r.URL.Path, r.URL.Query().Get("per_page"), page+1))
+		}
+		fmt.Fprintf(w, `[{"sha": "p%d-a"}, {"sha": "p%d-b"}]`, page, page)
+	}))
+	return s
+}
+
+func (s *pagedCommitsServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func newTestGithubClient(server *httptest.Server) *githubclient.GithubClient {
+	cfg := &config.Config{BASE_URL: server.URL, PER_PAGE: "2"}
+	return githubclient.NewGithubClient(cfg, context.Background())
+}
+
+func TestFetchRepositoryCommitsFollowsLinkPagination(t *testing.T) {
+	server := newPagedCommitsServer(3)
+	defer server.Close()
+	client := newTestGithubClient(server.Server)
+
+	var pages []int
+	var hashes []string
+	err := client.FetchRepositoryCommits(context.Background(), "octocat", "hello-world", domain.CommitFetchOptions{}, func(page int, commits []githubclient.Commit) error {
+		pages = append(pages, page)
+		for _, commit := range commits {
+			hashes = append(hashes, commit.SHA)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, pages)
+	assert.Equal(t, []string{"p1-a", "p1-b", "p2-a", "p2-b", "p3-a", "p3-b"}, hashes)
+	assert.Equal(t, []string{"per_page=2", "per_page=2&page=2", "per_page=2&page=3"}, server.requests())
+}
+
+func TestFetchRepositoryCommitsPageWindow(t *testing.T) {
+	server := newPagedCommitsServer(5)
+	defer server.Close()
+	client := newTestGithubClient(server.Server)
+
+	// Resuming from page 2 with a cap of two pages stops before page 4
+	var pages []int
+	opts := domain.CommitFetchOptions{StartPage: 2, MaxPages: 2}
+	err := client.FetchRepositoryCommits(context.Background(), "octocat", "hello-world", opts, func(page int, commits []githubclient.Commit) error {
+		pages = append(pages, page)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, pages)
+	assert.Equal(t, []string{"per_page=2&page=2", "per_page=2&page=3"}, server.requests())
+}
+
+func TestFetchRepositoryCommitsSendsWindow(t *testing.T) {
+	server := newPagedCommitsServer(1)
+	defer server.Close()
+	client := newTestGithubClient(server.Server)
+	ignore := func(page int, commits []githubclient.Commit) error { return nil }
+
+	// Times are sent in UTC, so an offset never needs escaping
+	lagos := time.FixedZone("WAT", 60*60)
+	opts := domain.CommitFetchOptions{
+		Since: time.Date(2024, 1, 1, 9, 30, 0, 0, lagos),
+		Until: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.NoError(t, client.FetchRepositoryCommits(context.Background(), "octocat", "hello-world", opts, ignore))
+
+	// Either bound can be left open
+	assert.NoError(t, client.FetchRepositoryCommits(context.Background(), "octocat", "hello-world", domain.CommitFetchOptions{Since: opts.Since}, ignore))
+	assert.NoError(t, client.FetchRepositoryCommits(context.Background(), "octocat", "hello-world", domain.CommitFetchOptions{Until: opts.Until}, ignore))
+
+	assert.Equal(t, []string{
+		"per_page=2&since=2024-01-01T08:30:00Z&until=2024-02-01T00:00:00Z",
+		"per_page=2&since=2024-01-01T08:30:00Z",
+		"per_page=2&until=2024-02-01T00:00:00Z",
+	}, server.requests())
+}
+
+// pagedPullsServer serves three pages of two pull requests, most recently updated first, under a fixed ETag per page,
+// and records the pages it was sent and which of them it answered with 304
+type pagedPullsServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func newPagedPullsServer() *pagedPullsServer {
+	s := &pagedPullsServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		etag := fmt.Sprintf(`"page%d"`, page)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			s.requests = append(s.requests, fmt.Sprintf("%d:304", page))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.requests = append(s.requests, fmt.Sprintf("%d:200", page))
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=2&page=%d>; rel="next"`, s.URL, r.URL.Path, page+1))
+		}
+		w.Header().Set("ETag", etag)
+		// Pull requests 6 down to 1 were last updated on days 6 down to 1 of January
+		first := 8 - 2*page
+		fmt.Fprintf(w, `[{"number": %d, "updated_at": "2024-01-%02dT00:00:00Z"}, {"number": %d, "updated_at": "2024-01-%02dT00:00:00Z"}]`,
+			first, first, first-1, first-1)
+	}))
+	return s
+}
+
+func (s *pagedPullsServer) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := s.requests
+	s.requests = nil
+	return sent
+}
+
+func TestFetchRepositoryPullsWalk(t *testing.T) {
+	server := newPagedPullsServer()
+	defer server.Close()
+	client := newTestGithubClient(server.Server)
+	ctx := context.Background()
+
+	// The walk stops at the page reaching the last sync, and hands over what changed since, oldest first
+	since := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	var numbers []int
+	failing := errors.New("database is down")
+	err := client.FetchRepositoryPulls(ctx, "octocat", "hello-world", since, func(pulls []githubclient.PullRequest) error {
+		for _, pull := range pulls {
+			numbers = append(numbers, pull.Number)
+		}
+		return failing
+	})
+	assert.ErrorIs(t, err, failing)
+	assert.Equal(t, []int{4, 5, 6}, numbers)
+	assert.Equal(t, []string{"1:200", "2:200"}, server.sent())
+
+	// The failed walk forgot its pages, so the retry fetches them in full
+	numbers = nil
+	handle := func(pulls []githubclient.PullRequest) error {
+		for _, pull := range pulls {
+			numbers = append(numbers, pull.Number)
+		}
+		return nil
+	}
+	assert.NoError(t, client.FetchRepositoryPulls(ctx, "octocat", "hello-world", time.Time{}, handle))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, numbers)
+	assert.Equal(t, []string{"1:200", "2:200", "3:200"}, server.sent())
+
+	// Once handled, an unchanged list answers 304 on the first page
+	numbers = nil
+	err = client.FetchRepositoryPulls(ctx, "octocat", "hello-world", time.Time{}, handle)
+	assert.ErrorIs(t, err, httpclient.ErrNotModified)
+	assert.Empty(t, numbers)
+	assert.Equal(t, []string{"1:304"}, server.sent())
+}
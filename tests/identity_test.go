@@ -0,0 +1,190 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/pkg/mailmap"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestParseMailmap(t *testing.T) {
+	entries, err := mailmap.Parse(strings.NewReader(`# Team members
+Jane Doe <jane@example.com>
+<jane@example.com> <jane@old.example.com>
+Jane Doe <jane@example.com> <jdoe@laptop.local>  # old laptop
+Jane Doe <jane@example.com> Janey <shared@example.com>
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []mailmap.Entry{
+		{ProperName: "Jane Doe", CommitEmail: "jane@example.com"},
+		{ProperEmail: "jane@example.com", CommitEmail: "jane@old.example.com"},
+		{ProperName: "Jane Doe", ProperEmail: "jane@example.com", CommitEmail: "jdoe@laptop.local"},
+		{ProperName: "Jane Doe", ProperEmail: "jane@example.com", CommitName: "Janey", CommitEmail: "shared@example.com"},
+	}, entries)
+
+	_, err = mailmap.Parse(strings.NewReader("Jane Doe <jane@example.com\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestResolveIdentities(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Job{}, &domain.Identity{}, &domain.IdentityAlias{})
+	assert.NoError(t, err)
+
+	repo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	identityRepo, err := postgresdb.NewIdentityRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	identityService := service.NewIdentityService(identityRepo, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCommits := []domain.Commit{
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Author: "Alice", AuthorLogin: "Alice", Email: "alice@example.com", CommitDate: date},
+		// Not linked to an account, but the email was used with a login
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Author: "alice", Email: "Alice@example.com", CommitDate: date},
+		// GitHub's private email for the login
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Author: "A. Smith", Email: "1234+alice@users.noreply.github.com", CommitDate: date},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit4", Author: "Bob", Email: "bob@old.example.com", CommitDate: date},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit5", Author: "Robert", Email: "bob@example.com", CommitDate: date},
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit6", Author: "build", Email: "ci@example.com", CommitDate: date},
+	}
+	for _, commit := range testCommits {
+		assert.NoError(t, db.Create(&commit).Error)
+	}
+
+	// Bob's old email belongs to his current one, and the CI account to Alice
+	imported, err := identityService.ImportMailmap(ctx, strings.NewReader("Bob <bob@example.com> <bob@old.example.com>\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	alice := domain.Identity{Name: "Alice Smith", Login: "alice", Aliases: []domain.IdentityAlias{{Name: "build", Email: "ci@example.com"}}}
+	assert.NoError(t, identityService.CreateIdentity(ctx, &alice))
+	assert.Equal(t, "alice", alice.Key)
+
+	updated, err := identityService.ResolveIdentities(ctx, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), updated)
+
+	authors, err := repo.GetTopNCommitAuthors(ctx, "octocat", "Hello-World", time.Time{}, time.Time{}, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, authors, 2) {
+		assert.Equal(t, "alice", authors[0].Identity)
+		assert.Equal(t, "Alice Smith", authors[0].Author)
+		assert.Equal(t, 4, authors[0].Count)
+		assert.Equal(t, "bob@example.com", authors[1].Identity)
+		assert.Equal(t, "Bob", authors[1].Author)
+		assert.Equal(t, 2, authors[1].Count)
+	}
+
+	// Commits can be listed by the identity they were resolved to, whatever name they were made under
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	filter := domain.CommitFilter{Identity: "alice"}
+	commits, err := commitRepo.GetCommits(ctx, "octocat", "Hello-World", filter, 1, 10)
+	assert.NoError(t, err)
+	hashes := []string{}
+	for _, commit := range commits {
+		hashes = append(hashes, commit.Hash)
+	}
+	assert.ElementsMatch(t, []string{"commit1", "commit2", "commit3", "commit6"}, hashes)
+	total, err := commitRepo.GetTotalCommits(ctx, "octocat", "Hello-World", filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+
+	// Deleting the alias hands the CI commits back to automatic clustering
+	identity, err := identityService.GetIdentity(ctx, alice.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, identityService.DeleteAlias(ctx, alice.ID, identity.Aliases[0].ID))
+	updated, err = identityService.ResolveIdentities(ctx, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	var ci postgresdb.Commit
+	assert.NoError(t, db.Where("hash = ?", "commit6").First(&ci).Error)
+	assert.Equal(t, "ci@example.com", ci.Identity)
+
+	// The changes queued a single re-resolution
+	var jobs []domain.Job
+	assert.NoError(t, db.Where("type = ?", domain.JobResolveIdentities).Find(&jobs).Error)
+	assert.Len(t, jobs, 1)
+}
+
+// legacyCommit is a commit as stored before author and committer details were kept apart. Migrating a table of them
+// adds the columns of later versions as NULL, as it does on an upgraded database.
+type legacyCommit struct {
+	gorm.Model
+	Owner      string
+	Repository string
+	Hash       string
+	Message    string
+	Author     string
+	Email      string
+	URL        string
+	CommitDate time.Time
+}
+
+func (legacyCommit) TableName() string {
+	return "commits"
+}
+
+// migrateLegacyCommits stores commits in a commits table of the legacy schema, then migrates it to the current one
+func migrateLegacyCommits(t *testing.T, db *gorm.DB, commits ...legacyCommit) {
+	assert.NoError(t, db.AutoMigrate(&legacyCommit{}))
+	for _, commit := range commits {
+		assert.NoError(t, db.Create(&commit).Error)
+	}
+	assert.NoError(t, db.AutoMigrate(&postgresdb.Commit{}))
+}
+
+func TestResolveIdentitiesOfUpgradedCommits(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	migrateLegacyCommits(t, db,
+		legacyCommit{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Author: "Alice", Email: "1234+alice@users.noreply.github.com", CommitDate: date},
+		legacyCommit{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Author: "Bob", Email: "bob@old.example.com", CommitDate: date},
+		legacyCommit{Owner: "octocat", Repository: "Spoon-Knife", Hash: "commit3", Author: "Bob", Email: "bob@old.example.com", CommitDate: date},
+	)
+	assert.NoError(t, db.AutoMigrate(&domain.Job{}, &domain.Identity{}, &domain.IdentityAlias{}))
+
+	identityRepo, err := postgresdb.NewIdentityRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	identityService := service.NewIdentityService(identityRepo, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	// Commits stored before identities existed are resolved, in a single repository
+	updated, err := identityService.ResolveIdentities(ctx, "octocat", "Hello-World")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+
+	// and in every repository, where a mailmap entry reaches them too
+	_, err = identityService.ImportMailmap(ctx, strings.NewReader("Bob <bob@example.com> <bob@old.example.com>\n"))
+	assert.NoError(t, err)
+	updated, err = identityService.ResolveIdentities(ctx, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	total, err := commitRepo.GetTotalCommits(ctx, "octocat", "Hello-World", domain.CommitFilter{Identity: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	total, err = commitRepo.GetTotalCommits(ctx, "octocat", "Spoon-Knife", domain.CommitFilter{Identity: "bob@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+}
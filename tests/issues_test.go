@@ -0,0 +1,185 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeIssuesGithub serves a fixed list of issues and transitions, oldest first as GitHub's adapter does
+type fakeIssuesGithub struct {
+	fakeGithub
+	issues      []domain.Issue
+	transitions []domain.IssueTransition
+	since       time.Time
+}
+
+func (f *fakeIssuesGithub) FetchIssues(ctx context.Context, owner, repo string, since time.Time, handle func(issues []domain.Issue) error) error {
+	f.since = since
+	var updated []domain.Issue
+	for _, issue := range f.issues {
+		if !issue.UpdatedAt.Before(since) {
+			updated = append(updated, issue)
+		}
+	}
+	return handle(updated)
+}
+
+func (f *fakeIssuesGithub) FetchIssueTransitions(ctx context.Context, owner, repo string, since time.Time, handle func(transitions []domain.IssueTransition) error) error {
+	var made []domain.IssueTransition
+	for _, transition := range f.transitions {
+		if !transition.CreatedAt.Before(since) {
+			made = append(made, transition)
+		}
+	}
+	return handle(made)
+}
+
+func TestIssueSync(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&domain.Job{}, &domain.Issue{}, &domain.IssueTransition{})
+	assert.NoError(t, err)
+
+	issueRepo, err := postgresdb.NewIssueRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	// Monday 4 March 2024
+	week := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	closedAt := week.Add(7*24*time.Hour + 2*time.Hour)
+	timeToClose := int64(closedAt.Sub(week).Seconds())
+	github := &fakeIssuesGithub{
+		issues: []domain.Issue{
+			{Owner: "octocat", Repository: "hello-world", Number: 1, Title: "Crash on start", State: domain.IssueClosed, Author: "mona",
+				Labels: []string{"bug", "100%"}, Assignees: []string{"Hubot"}, CreatedAt: week, UpdatedAt: closedAt, ClosedAt: &closedAt, TimeToClose: &timeToClose},
+			{Owner: "octocat", Repository: "hello-world", Number: 2, Title: "Dark mode", State: domain.IssueOpen, Author: "hubot",
+				Labels: []string{"enhancement"}, Assignees: []string{}, CreatedAt: week.Add(24 * time.Hour), UpdatedAt: week.Add(9 * 24 * time.Hour)},
+			{Owner: "octocat", Repository: "hello-world", Number: 3, Title: "Typo in the docs", State: domain.IssueOpen, Author: "mona",
+				Labels: []string{"bugfix"}, Assignees: []string{"mona"}, CreatedAt: week.Add(8 * 24 * time.Hour), UpdatedAt: week.Add(10 * 24 * time.Hour)},
+		},
+		transitions: []domain.IssueTransition{
+			{Owner: "octocat", Repository: "hello-world", EventID: 11, Number: 1, Event: domain.IssueEventClosed, Actor: "hubot", CreatedAt: week.Add(2 * 24 * time.Hour)},
+			{Owner: "octocat", Repository: "hello-world", EventID: 12, Number: 1, Event: domain.IssueEventReopened, Actor: "mona", CreatedAt: week.Add(3 * 24 * time.Hour)},
+			{Owner: "octocat", Repository: "hello-world", EventID: 13, Number: 1, Event: domain.IssueEventClosed, Actor: "hubot", CreatedAt: closedAt},
+		},
+	}
+	issueService := service.NewIssueService(issueRepo, github, service.NewJobService(jobRepo, 1, 1, time.Minute), &config.Config{})
+
+	saved, err := issueService.SyncIssues(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, saved)
+	assert.True(t, github.since.IsZero())
+
+	// A second sync resumes from the latest update, and transitions fetched again are not duplicated
+	saved, err = issueService.SyncIssues(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved)
+	assert.True(t, github.since.Equal(week.Add(10*24*time.Hour)))
+
+	// The label matches whole names only, and the assignee case-insensitively
+	issues, total, err := issueService.GetIssues(ctx, "octocat", "hello-world", domain.IssueFilter{Label: "bug"}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, 1, issues[0].Number)
+		assert.Equal(t, []string{"bug", "100%"}, issues[0].Labels)
+	}
+	issues, _, err = issueService.GetIssues(ctx, "octocat", "hello-world", domain.IssueFilter{Label: "100%"}, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	issues, _, err = issueService.GetIssues(ctx, "octocat", "hello-world", domain.IssueFilter{Assignee: "HUBOT"}, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+
+	issues, total, err = issueService.GetIssues(ctx, "octocat", "hello-world", domain.IssueFilter{State: domain.IssueOpen, Author: "Mona"}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, 3, issues[0].Number)
+	}
+
+	// The lifecycle starts with the opening and follows every close and reopen
+	issue, err := issueService.GetIssue(ctx, "octocat", "hello-world", 1)
+	assert.NoError(t, err)
+	if assert.NotNil(t, issue) {
+		events := make([]domain.IssueEvent, len(issue.Transitions))
+		for i, transition := range issue.Transitions {
+			events[i] = transition.Event
+		}
+		assert.Equal(t, []domain.IssueEvent{domain.IssueEventOpened, domain.IssueEventClosed, domain.IssueEventReopened, domain.IssueEventClosed}, events)
+		assert.Equal(t, "mona", issue.Transitions[0].Actor)
+	}
+	issue, err = issueService.GetIssue(ctx, "octocat", "hello-world", 42)
+	assert.NoError(t, err)
+	assert.Nil(t, issue)
+}
+
+func TestIssueRates(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&domain.Job{}, &domain.Issue{}, &domain.IssueTransition{})
+	assert.NoError(t, err)
+
+	issueRepo, err := postgresdb.NewIssueRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	// Monday 4 March 2024; issue 1 was opened the week before
+	week := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	issue := func(number int, created time.Time, closed *time.Time) domain.Issue {
+		i := domain.Issue{Owner: "octocat", Repository: "hello-world", Number: number, State: domain.IssueOpen, CreatedAt: created, UpdatedAt: created}
+		if closed != nil {
+			seconds := int64(closed.Sub(created).Seconds())
+			i.State, i.ClosedAt, i.TimeToClose = domain.IssueClosed, closed, &seconds
+		}
+		return i
+	}
+	day := 24 * time.Hour
+	closedFirst := week.Add(day)
+	closedSecond := week.Add(2 * day)
+	assert.NoError(t, issueRepo.SaveIssues(ctx, []domain.Issue{
+		issue(1, week.Add(-3*day), &closedFirst),
+		issue(2, week.Add(time.Hour), &closedSecond),
+		issue(3, week.Add(3*day), nil),
+		issue(4, week.Add(15*day), nil),
+	}))
+
+	issueService := service.NewIssueService(issueRepo, &fakeGithub{}, service.NewJobService(jobRepo, 1, 1, time.Minute), &config.Config{})
+
+	rates, err := issueService.GetIssueRates(ctx, "octocat", "hello-world", domain.ActivityWeek, week, week.Add(20*day))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rates.Opened)
+	assert.Equal(t, 2, rates.Closed)
+	if assert.Len(t, rates.Buckets, 3) {
+		// Issue 1 was open when the window began
+		assert.Equal(t, domain.IssueRatePoint{Start: week, Opened: 2, Closed: 2, Open: 1, AverageTimeToClose: (4*86400 + (2*86400 - 3600)) / 2.0}, rates.Buckets[0])
+		assert.Equal(t, domain.IssueRatePoint{Start: week.Add(7 * day), Open: 1}, rates.Buckets[1])
+		assert.Equal(t, domain.IssueRatePoint{Start: week.Add(14 * day), Opened: 1, Open: 2}, rates.Buckets[2])
+	}
+
+	// Without a window the series starts at the first issue
+	rates, err = issueService.GetIssueRates(ctx, "octocat", "hello-world", domain.ActivityWeek, time.Time{}, week.Add(20*day))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, rates.Opened)
+	if assert.Len(t, rates.Buckets, 4) {
+		assert.Equal(t, domain.IssueRatePoint{Start: week.Add(-7 * day), Opened: 1, Open: 1}, rates.Buckets[0])
+		assert.Equal(t, 1, rates.Buckets[1].Open)
+	}
+}
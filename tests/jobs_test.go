@@ -0,0 +1,174 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"github-service/internal/ports"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestJobServiceRetriesFailedJobs(t *testing.T) {
+	// Setup in-memory SQLite database shared by the workers
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	// Auto migrate the schema
+	err = db.AutoMigrate(&domain.Job{})
+	assert.NoError(t, err)
+
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The handler fails on its first attempt only
+	var calls int32
+	jobService := service.NewJobService(jobRepo, 2, 3, 10*time.Millisecond)
+	jobService.RegisterHandler("test", func(ctx context.Context, job *domain.Job) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	jobService.Start(ctx)
+
+	job, err := jobService.Enqueue(ctx, "test", "octocat", "Hello-World", map[string]string{"since": "2024-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+
+	// Enqueueing the same work again while it is pending returns the existing job
+	again, err := jobService.Enqueue(ctx, "test", "octocat", "Hello-World", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, again.ID)
+
+	assert.Eventually(t, func() bool {
+		stored, err := jobService.GetJob(ctx, job.ID)
+		return err == nil && stored.Status == domain.JobSucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stored, err := jobService.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Empty(t, stored.Error)
+	assert.NotNil(t, stored.FinishedAt)
+
+	jobs, total, err := jobService.ListJobs(ctx, domain.JobSucceeded, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, jobs, 1)
+}
+
+func TestJobServiceFailsAfterMaxAttempts(t *testing.T) {
+	// Setup in-memory SQLite database shared by the workers
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	// Auto migrate the schema
+	err = db.AutoMigrate(&domain.Job{})
+	assert.NoError(t, err)
+
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobService := service.NewJobService(jobRepo, 1, 2, 10*time.Millisecond)
+	jobService.RegisterHandler("test", func(ctx context.Context, job *domain.Job) error {
+		return errors.New("backfill died")
+	})
+	jobService.Start(ctx)
+
+	job, err := jobService.Enqueue(ctx, "test", "octocat", "Hello-World", nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		stored, err := jobService.GetJob(ctx, job.ID)
+		return err == nil && stored.Status == domain.JobFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stored, err := jobService.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Equal(t, "backfill died", stored.Error)
+}
+
+// racingJobs holds back the first callers looking for an active job until all of them have looked,
+// so they all find none and race to create it
+type racingJobs struct {
+	ports.PostgresJob
+	racers  int32
+	looked  atomic.Int32
+	barrier sync.WaitGroup
+}
+
+func (r *racingJobs) FindActiveJob(ctx context.Context, jobType, owner, repositoryName string) (*domain.Job, error) {
+	job, err := r.PostgresJob.FindActiveJob(ctx, jobType, owner, repositoryName)
+	if r.looked.Add(1) <= r.racers {
+		r.barrier.Done()
+		r.barrier.Wait()
+	}
+	return job, err
+}
+
+func TestJobServiceEnqueuesConcurrentDuplicatesOnce(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	err = db.AutoMigrate(&domain.Job{})
+	assert.NoError(t, err)
+
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	racing := &racingJobs{PostgresJob: jobRepo, racers: 5}
+	racing.barrier.Add(5)
+	jobService := service.NewJobService(racing, 1, 3, 10*time.Millisecond)
+
+	ctx := context.Background()
+	ids := make([]uint, 5)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			job, err := jobService.Enqueue(ctx, "test", "octocat", "Hello-World", nil)
+			if assert.NoError(t, err) {
+				ids[i] = job.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every caller got the one job that was queued
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	_, total, err := jobService.ListJobs(ctx, domain.JobQueued, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	// Once it is finished the same work can be queued again
+	finished, err := jobService.GetJob(ctx, ids[0])
+	assert.NoError(t, err)
+	finished.Status = domain.JobSucceeded
+	assert.NoError(t, racing.UpdateJob(ctx, &finished))
+	next, err := jobService.Enqueue(ctx, "test", "octocat", "Hello-World", nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ids[0], next.ID)
+}
@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeMetadataGithub answers repository metadata fetches with a fixed result and counts the fetches and forgotten fetches
+type fakeMetadataGithub struct {
+	fakeGithub
+	repository *domain.Repository
+	err        error
+	forgotten  int
+	fetchMu    sync.Mutex
+	fetched    map[string]int
+}
+
+func (f *fakeMetadataGithub) FetchRepository(ctx context.Context, owner, repoName string) (*domain.Repository, error) {
+	f.fetchMu.Lock()
+	defer f.fetchMu.Unlock()
+	if f.fetched == nil {
+		f.fetched = make(map[string]int)
+	}
+	f.fetched[owner+"/"+repoName]++
+	return f.repository, f.err
+}
+
+// fetches returns how many times the metadata of a repository was fetched
+func (f *fakeMetadataGithub) fetches(fullName string) int {
+	f.fetchMu.Lock()
+	defer f.fetchMu.Unlock()
+	return f.fetched[fullName]
+}
+
+func (f *fakeMetadataGithub) ForgetRepository(owner, repoName string) {
+	f.forgotten++
+}
+
+// setupMonitor returns a MonitorService over an in-memory database migrated with the given models, without started workers
+func setupMonitor(t *testing.T, github *fakeMetadataGithub, models ...interface{}) (*service.MonitorService, *service.CommitService, *service.JobService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	err = db.AutoMigrate(append([]interface{}{&postgresdb.Commit{}, &domain.CommitBranch{}, &domain.Job{}, &domain.Backfill{}, &domain.Identity{}}, models...)...)
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	repositoryRepo, err := postgresdb.NewRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	cfg := &config.Config{}
+	commitService := service.NewCommitService(commitRepo, backfillRepo, cfg, github)
+	jobService := service.NewJobService(jobRepo, 1, 3, 10*time.Millisecond)
+	repositoryService := service.NewRepositoryService(repositoryRepo, *commitService, cfg, nil, github)
+	monitorService := service.NewMonitorService(commitService, repositoryService, 1, time.Millisecond, github, jobService, backfillRepo, cfg)
+	return monitorService, commitService, jobService
+}
+
+func TestMonitorPullsCommitsWhenMetadataUnchanged(t *testing.T) {
+	ctx := context.Background()
+	github := &fakeMetadataGithub{err: domain.ErrNotModified}
+	monitorService, commitService, jobService := setupMonitor(t, github, &postgresdb.Repository{})
+
+	saved, err := commitService.SaveCommits(ctx, "octocat", "hello-world", domain.CommitFetchOptions{
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved)
+
+	// The metadata answering 304 does not mean the commit listing is unchanged too
+	assert.NoError(t, monitorService.MonitorRepository(ctx, domain.RepoData{Owner: "octocat", RepoName: "hello-world"}))
+
+	jobs, total, err := jobService.ListJobs(ctx, domain.JobQueued, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, domain.JobSyncCommits, jobs[0].Type)
+	assert.Equal(t, 0, github.forgotten)
+}
+
+func TestMonitorForgetsMetadataItFailedToStore(t *testing.T) {
+	ctx := context.Background()
+	github := &fakeMetadataGithub{repository: &domain.Repository{Owner: "octocat", Name: "hello-world"}}
+	// Without a repositories table the metadata cannot be stored
+	monitorService, _, jobService := setupMonitor(t, github)
+
+	assert.Error(t, monitorService.MonitorRepository(ctx, domain.RepoData{Owner: "octocat", RepoName: "hello-world"}))
+	assert.Equal(t, 1, github.forgotten)
+
+	_, total, err := jobService.ListJobs(ctx, "", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestMonitorSyncsAfterBackfillFoundNoCommits(t *testing.T) {
+	ctx := context.Background()
+	github := &fakeMetadataGithub{err: domain.ErrNotModified}
+	monitorService, _, jobService := setupMonitor(t, github, &postgresdb.Repository{})
+
+	// An empty window: the backfill completes without storing a commit
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rData := domain.RepoData{Owner: "octocat", RepoName: "hello-world"}
+	job, err := monitorService.AddRepositoryCommitsToMonitor(ctx, rData, start, start, 1)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobService.Start(ctx)
+	assert.Eventually(t, func() bool {
+		stored, err := jobService.GetJob(ctx, job.ID)
+		return err == nil && stored.Status == domain.JobSucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	// Polling pulls the commits made since the backfill ended rather than starting it over
+	assert.NoError(t, monitorService.MonitorRepository(context.Background(), rData))
+	backfill, err := monitorService.GetBackfill(context.Background(), "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, backfill.JobID)
+
+	jobs, _, err := jobService.ListJobs(context.Background(), domain.JobQueued, 1, 10)
+	assert.NoError(t, err)
+	var syncs []domain.Job
+	for _, queued := range jobs {
+		if queued.Type == domain.JobSyncCommits {
+			syncs = append(syncs, queued)
+		}
+	}
+	if assert.Len(t, syncs, 1) {
+		assert.Contains(t, syncs[0].Payload, `"Since":"2024-01-01T00:00:00Z"`)
+	}
+}
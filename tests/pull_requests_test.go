@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakePullsGithub serves a fixed list of pull requests, least recently updated first as GitHub's adapter does
+type fakePullsGithub struct {
+	fakeGithub
+	pulls []domain.PullRequest
+	since time.Time
+}
+
+func (f *fakePullsGithub) FetchPullRequests(ctx context.Context, owner, repo string, since time.Time, handle func(pulls []domain.PullRequest) error) error {
+	f.since = since
+	var updated []domain.PullRequest
+	for _, pull := range f.pulls {
+		if !pull.UpdatedAt.Before(since) {
+			updated = append(updated, pull)
+		}
+	}
+	return handle(updated)
+}
+
+func (f *fakePullsGithub) FetchPullReviewStats(ctx context.Context, owner, repo string, number int) (domain.PullReviewStats, error) {
+	firstReview := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return domain.PullReviewStats{Reviews: number, Approvals: 1, FirstReviewAt: &firstReview}, nil
+}
+
+func TestPullRequestSync(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Job{}, &domain.PullRequest{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	pullRepo, err := postgresdb.NewPullRequestRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, commitRepo.SaveCommits(ctx, []domain.Commit{
+		{Owner: "octocat", Repository: "hello-world", Hash: "m1", Message: "Add the punch card (#1)", Author: "Mona", CommitDate: base.Add(48 * time.Hour)},
+		{Owner: "octocat", Repository: "hello-world", Hash: "c2", Message: "Tidy up", Author: "Mona", CommitDate: base.Add(24 * time.Hour)},
+		{Owner: "octocat", Repository: "fork", Hash: "m1", Message: "Add the punch card (#1)", Author: "Mona", CommitDate: base.Add(48 * time.Hour)},
+	}))
+
+	mergedAt := base.Add(48 * time.Hour)
+	closedAt := base.Add(72 * time.Hour)
+	github := &fakePullsGithub{pulls: []domain.PullRequest{
+		{Owner: "octocat", Repository: "hello-world", Number: 1, Title: "Add the punch card", State: domain.PullRequestMerged, Author: "Mona", BaseRef: "main",
+			CreatedAt: base, UpdatedAt: mergedAt, ClosedAt: &mergedAt, MergedAt: &mergedAt, MergeCommitSHA: "m1"},
+		{Owner: "octocat", Repository: "hello-world", Number: 2, Title: "Try another chart", State: domain.PullRequestClosed, Author: "hubot", BaseRef: "main",
+			CreatedAt: base.Add(24 * time.Hour), UpdatedAt: closedAt, ClosedAt: &closedAt, MergeCommitSHA: "c2"},
+		{Owner: "octocat", Repository: "hello-world", Number: 3, Title: "Draft the docs", State: domain.PullRequestOpen, Draft: true, Author: "mona", BaseRef: "docs",
+			CreatedAt: base.Add(96 * time.Hour), UpdatedAt: base.Add(96 * time.Hour)},
+	}}
+	pullService := service.NewPullRequestService(pullRepo, github, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	// The first sync fetches every pull request with its reviews and links the merge commit
+	saved, err := pullService.SyncPullRequests(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, saved)
+	assert.True(t, github.since.IsZero())
+
+	pulls, total, err := pullService.GetPullRequests(ctx, "octocat", "hello-world", domain.PullRequestFilter{}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, pulls, 3) {
+		assert.Equal(t, []int{3, 2, 1}, []int{pulls[0].Number, pulls[1].Number, pulls[2].Number})
+		assert.Equal(t, 3, pulls[0].Reviews)
+		assert.Equal(t, 1, pulls[0].Approvals)
+		assert.NotNil(t, pulls[0].FirstReviewAt)
+	}
+
+	// Only the merged pull request links its merge commit, and only in its own repository
+	var links []postgresdb.Commit
+	assert.NoError(t, db.Order("repository, hash").Find(&links).Error)
+	assert.Equal(t, []int{0, 0, 1}, []int{links[0].PullNumber, links[1].PullNumber, links[2].PullNumber})
+
+	// Filters narrow the listing; the author matches case-insensitively
+	pulls, total, err = pullService.GetPullRequests(ctx, "octocat", "hello-world", domain.PullRequestFilter{Author: "MONA"}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, pulls, 2)
+
+	pulls, _, err = pullService.GetPullRequests(ctx, "octocat", "hello-world", domain.PullRequestFilter{State: domain.PullRequestMerged}, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, pulls, 1) {
+		assert.Equal(t, "m1", pulls[0].MergeCommitSHA)
+	}
+
+	pulls, _, err = pullService.GetPullRequests(ctx, "octocat", "hello-world", domain.PullRequestFilter{Base: "main", Since: base.Add(time.Hour)}, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, pulls, 1) {
+		assert.Equal(t, 2, pulls[0].Number)
+	}
+
+	// The next sync resumes from the most recent update and updates the pull requests in place
+	github.pulls[2].State = domain.PullRequestClosed
+	github.pulls[2].UpdatedAt = base.Add(120 * time.Hour)
+	saved, err = pullService.SyncPullRequests(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved)
+	assert.True(t, github.since.Equal(base.Add(96*time.Hour)))
+
+	pulls, total, err = pullService.GetPullRequests(ctx, "octocat", "hello-world", domain.PullRequestFilter{State: domain.PullRequestClosed}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, pulls, 2)
+}
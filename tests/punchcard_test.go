@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPunchcard(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	commitService := service.NewCommitService(commitRepo, nil, &config.Config{}, nil)
+
+	testCommits := []domain.Commit{
+		// Monday 23:30 UTC is Tuesday 00:30 in Berlin in winter
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit1", Author: "Alice", AuthorLogin: "alice", AuthorDate: time.Date(2024, time.January, 8, 23, 30, 0, 0, time.UTC), CommitDate: time.Date(2024, time.January, 8, 23, 30, 0, 0, time.UTC)},
+		// Summer time: 08:15 UTC is 10:15 in Berlin
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit2", Author: "Bob", AuthorLogin: "bob", AuthorDate: time.Date(2024, time.July, 3, 8, 15, 0, 0, time.UTC), CommitDate: time.Date(2024, time.July, 3, 8, 15, 0, 0, time.UTC)},
+		// Rebased on a Friday afternoon: placed by when it was written
+		{Owner: "octocat", Repository: "Hello-World", Hash: "commit3", Author: "Alice", AuthorLogin: "alice", AuthorDate: time.Date(2024, time.January, 15, 23, 5, 0, 0, time.UTC), CommitDate: time.Date(2024, time.January, 19, 15, 0, 0, 0, time.UTC)},
+	}
+	for _, commit := range testCommits {
+		assert.NoError(t, commitRepo.SaveCommit(ctx, &commit))
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	punchcard, err := commitService.GetPunchcard(ctx, "octocat", "Hello-World", domain.CommitFilter{}, berlin)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", punchcard.Timezone)
+	assert.Equal(t, 3, punchcard.Total)
+	assert.Equal(t, 2, punchcard.Counts[time.Tuesday][0])
+	assert.Equal(t, 1, punchcard.Counts[time.Wednesday][10])
+
+	// In UTC, for one author
+	punchcard, err = commitService.GetPunchcard(ctx, "octocat", "Hello-World", domain.CommitFilter{Identity: "alice"}, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, punchcard.Total)
+	assert.Equal(t, 2, punchcard.Counts[time.Monday][23])
+}
@@ -0,0 +1,137 @@
+package repository_test
+
+import (
+	"context"
+	httpclient "github-service/pkg/httpClient"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuota(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "5000")
+	header.Set("X-RateLimit-Remaining", "4321")
+	header.Set("X-RateLimit-Reset", "1704067200")
+	quota, ok := httpclient.ParseQuota(header)
+	assert.True(t, ok)
+	assert.Equal(t, httpclient.Quota{Limit: 5000, Remaining: 4321, Reset: time.Unix(1704067200, 0)}, quota)
+
+	// The limit is optional, the remaining requests and reset time are not
+	header.Del("X-RateLimit-Limit")
+	quota, ok = httpclient.ParseQuota(header)
+	assert.True(t, ok)
+	assert.Equal(t, 0, quota.Limit)
+
+	header.Del("X-RateLimit-Reset")
+	_, ok = httpclient.ParseQuota(header)
+	assert.False(t, ok)
+	_, ok = httpclient.ParseQuota(http.Header{"X-Ratelimit-Remaining": {"many"}, "X-Ratelimit-Reset": {"1704067200"}})
+	assert.False(t, ok)
+}
+
+// rejectingServer answers the first rejections requests with the given status, headers and body, then with 200
+func rejectingServer(rejections int32, status int, header http.Header, body string) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= rejections {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	return server, &requests
+}
+
+func TestClientRetriesRateLimitedRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+	}{
+		{"too many requests", http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}, ""},
+		{"forbidden with retry after", http.StatusForbidden, http.Header{"Retry-After": {"0"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := rejectingServer(1, tt.status, tt.header, tt.body)
+			defer server.Close()
+
+			client := httpclient.NewClient(nil, nil)
+			_, err := client.ApiCall(context.Background(), http.MethodGet, server.URL, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, int32(2), requests.Load())
+		})
+	}
+}
+
+func TestClientGivesUpOnRateLimits(t *testing.T) {
+	// A 403 that is not about the rate limit is not retried
+	server, requests := rejectingServer(1, http.StatusForbidden, nil, `{"message": "Resource not accessible by integration"}`)
+	defer server.Close()
+	client := httpclient.NewClient(nil, nil)
+	_, err := client.ApiCall(context.Background(), http.MethodGet, server.URL, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.True(t, client.BlockedUntil().IsZero())
+
+	// A rate limit that persists is retried a bounded number of times
+	server, requests = rejectingServer(10, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}, "")
+	defer server.Close()
+	_, err = httpclient.NewClient(nil, nil).ApiCall(context.Background(), http.MethodGet, server.URL, nil)
+	assert.ErrorContains(t, err, "rate limited after 3 attempts")
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClientBacksOffAsAsked(t *testing.T) {
+	reset := time.Now().Add(2 * time.Minute).Truncate(time.Second)
+	exhausted := http.Header{
+		"X-Ratelimit-Limit":     {"5000"},
+		"X-Ratelimit-Remaining": {"0"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+	}
+	withRetryAfter := http.Header{"Retry-After": {"30"}}
+	for key, values := range exhausted {
+		withRetryAfter[key] = values
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+		until  time.Time
+	}{
+		// Retry-After wins over the quota reset
+		{"retry after", http.StatusForbidden, withRetryAfter, "", time.Now().Add(30 * time.Second)},
+		{"exhausted quota", http.StatusForbidden, exhausted, "", reset},
+		// Without either, the first back-off is a minute
+		{"no hint", http.StatusTooManyRequests, nil, "", time.Now().Add(time.Minute)},
+		{"secondary limit", http.StatusForbidden, nil, `{"message": "You have exceeded a secondary rate limit"}`, time.Now().Add(time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := rejectingServer(1, tt.status, tt.header, tt.body)
+			defer server.Close()
+
+			// The retry waits for the back-off, which outlasts the context
+			client := httpclient.NewClient(nil, nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := client.ApiCall(ctx, http.MethodGet, server.URL, nil)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Equal(t, int32(1), requests.Load())
+			assert.WithinDuration(t, tt.until, client.BlockedUntil(), 2*time.Second)
+		})
+	}
+}
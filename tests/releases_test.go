@@ -0,0 +1,213 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeReleasesGithub serves a fixed list of releases and tags
+type fakeReleasesGithub struct {
+	fakeGithub
+	releases []domain.Release
+	tags     []domain.Tag
+}
+
+func (f *fakeReleasesGithub) FetchReleases(ctx context.Context, owner, repo string) ([]domain.Release, error) {
+	return append([]domain.Release(nil), f.releases...), nil
+}
+
+func (f *fakeReleasesGithub) FetchTags(ctx context.Context, owner, repo string) ([]domain.Tag, error) {
+	return f.tags, nil
+}
+
+func TestReleaseSync(t *testing.T) {
+	// Setup in-memory SQLite database
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	// Auto migrate the schema
+	err = db.AutoMigrate(&postgresdb.Commit{}, &domain.Job{}, &domain.Tag{}, &domain.Release{})
+	assert.NoError(t, err)
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	releaseRepo, err := postgresdb.NewReleaseRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+
+	// c1 <- c2 (v1.0.0) <- c3 <- m4 (v1.1.0) <- c5, with b1 branching off c2 and merged in m4
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(hash string, hours int, parents ...string) domain.Commit {
+		return domain.Commit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: "Change " + hash, Author: "Mona",
+			CommitDate: base.Add(time.Duration(hours) * time.Hour), Parents: parents}
+	}
+	assert.NoError(t, commitRepo.SaveCommits(ctx, []domain.Commit{
+		commit("c1", 1),
+		commit("c2", 2, "c1"),
+		commit("b1", 3, "c2"),
+		commit("c3", 4, "c2"),
+		commit("m4", 5, "c3", "b1"),
+		commit("c5", 6, "m4"),
+	}))
+
+	first := base.Add(24 * time.Hour)
+	second := base.Add(48 * time.Hour)
+	github := &fakeReleasesGithub{
+		tags: []domain.Tag{
+			{Owner: "octocat", Repository: "hello-world", Name: "v1.0.0", CommitSHA: "c2"},
+			{Owner: "octocat", Repository: "hello-world", Name: "v1.1.0", CommitSHA: "m4"},
+		},
+		releases: []domain.Release{
+			{Owner: "octocat", Repository: "hello-world", TagName: "v1.1.0", Name: "Merge the branch", CreatedAt: second, PublishedAt: &second},
+			{Owner: "octocat", Repository: "hello-world", TagName: "v1.0.0", Name: "First", CreatedAt: first, PublishedAt: &first},
+			// The tag of this release is gone, so it contains no stored commit
+			{Owner: "octocat", Repository: "hello-world", TagName: "v0.1.0", Name: "Preview", Prerelease: true, CreatedAt: base},
+		},
+	}
+	releaseService := service.NewReleaseService(releaseRepo, commitRepo, github, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	saved, err := releaseService.SyncReleases(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, saved)
+
+	// Every commit belongs to the first release that contains it, the side branch included
+	var commits []postgresdb.Commit
+	assert.NoError(t, db.Order("hash").Find(&commits).Error)
+	released := make(map[string]string, len(commits))
+	for _, c := range commits {
+		released[c.Hash] = c.ReleaseTag
+	}
+	assert.Equal(t, map[string]string{"b1": "v1.1.0", "c1": "v1.0.0", "c2": "v1.0.0", "c3": "v1.1.0", "m4": "v1.1.0", "c5": ""}, released)
+
+	// Mapping again changes nothing
+	changed, err := releaseService.MapCommitReleases(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	releases, total, err := releaseService.GetReleases(ctx, "octocat", "hello-world", 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	if assert.Len(t, releases, 2) {
+		assert.Equal(t, "v1.1.0", releases[0].TagName)
+		assert.Equal(t, "m4", releases[0].CommitSHA)
+		assert.Equal(t, "v1.0.0", releases[1].TagName)
+	}
+
+	// The commits of a release are those between its tag and the previous release's, newest first
+	response, err := releaseService.GetReleaseCommits(ctx, "octocat", "hello-world", "v1.1.0", domain.CommitFilter{}, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1.0", response.Release.TagName)
+	if assert.NotNil(t, response.Previous) {
+		assert.Equal(t, "v1.0.0", response.Previous.TagName)
+	}
+	assert.Equal(t, 2, response.TotalPages)
+	if assert.Len(t, response.Commits, 2) {
+		assert.Equal(t, []string{"m4", "c3"}, []string{response.Commits[0].Hash, response.Commits[1].Hash})
+		assert.Equal(t, "v1.1.0", response.Commits[0].ReleaseTag)
+	}
+
+	response, err = releaseService.GetReleaseCommits(ctx, "octocat", "hello-world", "v0.1.0", domain.CommitFilter{}, 1, 10)
+	assert.NoError(t, err)
+	assert.Nil(t, response.Previous)
+	assert.Empty(t, response.Commits)
+
+	_, err = releaseService.GetReleaseCommits(ctx, "octocat", "hello-world", "v9.9.9", domain.CommitFilter{}, 1, 10)
+	assert.ErrorIs(t, err, domain.ErrReleaseNotFound)
+
+	// Moving a tag back moves the commits it no longer reaches to the next release
+	github.tags[0].CommitSHA = "c1"
+	_, err = releaseService.SyncReleases(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	response, err = releaseService.GetReleaseCommits(ctx, "octocat", "hello-world", "v1.1.0", domain.CommitFilter{}, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, response.Commits, 4)
+}
+
+// fakeHistoryGithub serves a fixed history of commits along with releases and tags
+type fakeHistoryGithub struct {
+	fakeReleasesGithub
+	commits []domain.Commit
+}
+
+func (f *fakeHistoryGithub) FetchCommit(ctx context.Context, owner, repo string, opts domain.CommitFetchOptions, handle func(page int, commits []domain.Commit) error) error {
+	var commits []domain.Commit
+	for _, commit := range f.commits {
+		if !commit.CommitDate.Before(opts.Since) && !commit.CommitDate.After(opts.Until) {
+			commits = append(commits, commit)
+		}
+	}
+	return handle(1, commits)
+}
+
+func TestReleaseSyncOfUpgradedCommits(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// c1 <- c2 <- c3 (v1.0.0), stored before parents were, along with a commit since dropped by a force-push
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	legacy := func(hash string, hours int) legacyCommit {
+		return legacyCommit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: "Change " + hash, Author: "Mona",
+			CommitDate: base.Add(time.Duration(hours) * time.Hour)}
+	}
+	migrateLegacyCommits(t, db, legacy("c1", 1), legacy("c2", 2), legacy("gone", 3), legacy("c3", 4))
+	assert.NoError(t, db.AutoMigrate(&domain.CommitBranch{}, &domain.Job{}, &domain.Tag{}, &domain.Release{}, &domain.Backfill{}))
+
+	commitRepo, err := postgresdb.NewCommitRepository(db)
+	assert.NoError(t, err)
+	releaseRepo, err := postgresdb.NewReleaseRepository(db)
+	assert.NoError(t, err)
+	jobRepo, err := postgresdb.NewJobRepository(db)
+	assert.NoError(t, err)
+	backfillRepo, err := postgresdb.NewBackfillRepository(db)
+	assert.NoError(t, err)
+
+	commit := func(hash string, hours int, parents ...string) domain.Commit {
+		return domain.Commit{Owner: "octocat", Repository: "hello-world", Hash: hash, Message: "Change " + hash, Author: "Mona",
+			CommitDate: base.Add(time.Duration(hours) * time.Hour), Parents: append([]string{}, parents...)}
+	}
+	released := base.Add(24 * time.Hour)
+	github := &fakeHistoryGithub{
+		fakeReleasesGithub: fakeReleasesGithub{
+			tags:     []domain.Tag{{Owner: "octocat", Repository: "hello-world", Name: "v1.0.0", CommitSHA: "c3"}},
+			releases: []domain.Release{{Owner: "octocat", Repository: "hello-world", TagName: "v1.0.0", CreatedAt: released, PublishedAt: &released}},
+		},
+		commits: []domain.Commit{commit("c1", 1), commit("c2", 2, "c1"), commit("c3", 4, "c2")},
+	}
+	releaseService := service.NewReleaseService(releaseRepo, commitRepo, github, service.NewJobService(jobRepo, 1, 1, time.Minute))
+
+	// History cannot be walked through the commits yet, so none is mapped and their parents are pulled again
+	saved, err := releaseService.SyncReleases(ctx, "octocat", "hello-world")
+	assert.ErrorIs(t, err, domain.ErrHistoryIncomplete)
+	assert.Equal(t, 1, saved)
+	total, err := commitRepo.GetTotalCommits(ctx, "octocat", "hello-world", domain.CommitFilter{Release: "v1.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	job, err := jobRepo.FindActiveJob(ctx, domain.JobBackfillParents, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+
+	// The commit GitHub no longer serves keeps unknown parents
+	commitService := service.NewCommitService(commitRepo, backfillRepo, &config.Config{}, github)
+	missing, err := commitService.BackfillParents(ctx, "octocat", "hello-world", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), missing.Count)
+
+	// but it is not part of any release's history, which is mapped in full
+	changed, err := releaseService.MapCommitReleases(ctx, "octocat", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, changed)
+	total, err = commitRepo.GetTotalCommits(ctx, "octocat", "hello-world", domain.CommitFilter{Release: "v1.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+}
@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"context"
+	"github-service/config"
+	"github-service/internal/adapters/postgresdb"
+	"github-service/internal/core/domain"
+	"github-service/internal/core/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWatchlist serves an initial watchlist and hands the watch callback to the test, which calls it with every change
+type fakeWatchlist struct {
+	initial  []domain.RepoData
+	onChange chan func([]domain.RepoData)
+}
+
+func (f *fakeWatchlist) SaveRepoArray(repoKey string, repoDataArray []domain.RepoData) error {
+	return nil
+}
+
+func (f *fakeWatchlist) GetRepoArray(repoKey string) ([]domain.RepoData, error) {
+	return f.initial, nil
+}
+
+func (f *fakeWatchlist) UpdateRepoArray(repoKey string, updatedRepo domain.RepoData) error {
+	return nil
+}
+
+func (f *fakeWatchlist) RemoveFromRepoArray(repoKey string, removedRepo domain.RepoData) error {
+	return nil
+}
+
+func (f *fakeWatchlist) WatchRepoArray(ctx context.Context, repoKey string, onChange func([]domain.RepoData)) error {
+	f.onChange <- onChange
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestSchedulerFollowsWatchlist(t *testing.T) {
+	github := &fakeMetadataGithub{err: domain.ErrNotModified}
+	monitorService, commitService, jobService := setupMonitor(t, github, &postgresdb.Repository{})
+	issueService := service.NewIssueService(nil, github, jobService, &config.Config{})
+
+	hello := domain.RepoData{Owner: "octocat", RepoName: "hello-world"}
+	spoon := domain.RepoData{Owner: "octocat", RepoName: "spoon-knife"}
+	// With commits saved, each run fetches the metadata once and queues a commit pull
+	for _, repo := range []domain.RepoData{hello, spoon} {
+		_, err := commitService.SaveCommits(context.Background(), repo.Owner, repo.RepoName, domain.CommitFetchOptions{
+			Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		})
+		assert.NoError(t, err)
+	}
+	watchlist := &fakeWatchlist{initial: []domain.RepoData{hello}, onChange: make(chan func([]domain.RepoData), 1)}
+
+	// Jobs run once when they are scheduled and then not again within the test
+	scheduler := service.NewScheduler(monitorService, issueService, &config.Config{POLL_INTERVAL: 3600}, watchlist)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.ScheduleMonitoring(ctx)
+	onChange := <-watchlist.onChange
+
+	polled := func(repo domain.RepoData, times int) {
+		t.Helper()
+		assert.Eventually(t, func() bool { return github.fetches(repo.FullName()) == times }, 5*time.Second, 10*time.Millisecond)
+	}
+	polled(hello, 1)
+
+	// Adding a repository schedules it without restarting the others
+	onChange([]domain.RepoData{hello, spoon})
+	polled(spoon, 1)
+
+	// Tracking other branches restarts the repository's job
+	hello.Branches = []string{"release/*"}
+	onChange([]domain.RepoData{hello, spoon})
+	polled(hello, 2)
+
+	// A removed repository is no longer scheduled, so adding it back starts a new job
+	onChange([]domain.RepoData{hello})
+	onChange([]domain.RepoData{hello, spoon})
+	polled(spoon, 2)
+
+	// The unchanged repository was left alone throughout
+	assert.Never(t, func() bool { return github.fetches(hello.FullName()) != 2 }, 100*time.Millisecond, 10*time.Millisecond)
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	httpclient "github-service/pkg/httpClient"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenPoolRotation(t *testing.T) {
+	// Blank and repeated tokens are skipped
+	pool := httpclient.NewTokenPool([]string{"a", " ", "b", "a", ""})
+	assert.Equal(t, 2, pool.Size())
+
+	// Each request is counted against its token, so tokens with the same quota take turns
+	var handed []string
+	for i := 0; i < 4; i++ {
+		token, wait := pool.Acquire()
+		assert.Zero(t, wait)
+		handed = append(handed, token)
+	}
+	assert.Equal(t, []string{"a", "b", "a", "b"}, handed)
+
+	// The token with the most quota left is preferred
+	now := time.Now()
+	pool.Update("a", httpclient.Quota{Limit: 5000, Remaining: 10, Reset: now.Add(time.Hour)})
+	pool.Update("b", httpclient.Quota{Limit: 5000, Remaining: 4000, Reset: now.Add(time.Hour)})
+	token, _ := pool.Acquire()
+	assert.Equal(t, "b", token)
+
+	quota := pool.Quota()
+	assert.Equal(t, 10000, quota.Limit)
+	assert.Equal(t, 10+3999, quota.Remaining)
+}
+
+func TestTokenPoolExhaustion(t *testing.T) {
+	pool := httpclient.NewTokenPool([]string{"a", "b"})
+	now := time.Now()
+
+	// An exhausted token is parked while another has quota
+	pool.Update("a", httpclient.Quota{Limit: 5000, Remaining: 0, Reset: now.Add(time.Hour)})
+	for i := 0; i < 3; i++ {
+		token, wait := pool.Acquire()
+		assert.Equal(t, "b", token)
+		assert.Zero(t, wait)
+	}
+
+	// With every token exhausted, the one that resets first is handed out along with the wait for its reset
+	pool.Update("b", httpclient.Quota{Limit: 5000, Remaining: 0, Reset: now.Add(10 * time.Minute)})
+	token, wait := pool.Acquire()
+	assert.Equal(t, "b", token)
+	assert.InDelta(t, float64(10*time.Minute), float64(wait), float64(time.Second))
+	assert.Equal(t, 0, pool.Quota().Remaining)
+
+	// A token whose reset has passed is usable again
+	pool.Update("a", httpclient.Quota{Limit: 5000, Remaining: 0, Reset: now.Add(-time.Second)})
+	token, wait = pool.Acquire()
+	assert.Equal(t, "a", token)
+	assert.Zero(t, wait)
+
+	// An empty pool sends requests unauthenticated
+	token, wait = httpclient.NewTokenPool(nil).Acquire()
+	assert.Equal(t, "", token)
+	assert.Zero(t, wait)
+}
+
+func TestClientRotatesExhaustedTokens(t *testing.T) {
+	reset := time.Now().Add(2 * time.Second).Unix()
+	var mu sync.Mutex
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		// Token a has run out for the hour, token b has plenty left
+		remaining := "1000"
+		if r.Header.Get("Authorization") == "Bearer a" {
+			remaining = "0"
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", remaining)
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := httpclient.NewClient(nil, httpclient.NewTokenPool([]string{"a", "b"}))
+	for i := 0; i < 3; i++ {
+		_, err := client.ApiCall(context.Background(), http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Bearer a", "Bearer b", "Bearer b"}, authorizations)
+
+	// The client reports the combined quota of the pool
+	quota, known := client.Quota()
+	assert.True(t, known)
+	assert.Equal(t, 10000, quota.Limit)
+	assert.Equal(t, 1000, quota.Remaining)
+}
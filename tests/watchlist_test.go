@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"fmt"
+	badger "github-service/internal/adapters/badgerdb"
+	"github-service/internal/core/domain"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchlistConcurrentEdits(t *testing.T) {
+	store, err := badger.NewBadgerRepository(t.TempDir())
+	assert.NoError(t, err)
+	defer store.Close()
+
+	// Start with repositories to remove while others are added
+	var removed []domain.RepoData
+	for i := 0; i < 10; i++ {
+		repo := domain.RepoData{Owner: "octocat", RepoName: fmt.Sprintf("old-%d", i)}
+		assert.NoError(t, store.UpdateRepoArray("repos", repo))
+		removed = append(removed, repo)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, store.UpdateRepoArray("repos", domain.RepoData{Owner: "octocat", RepoName: fmt.Sprintf("new-%d", i)}))
+			if i < len(removed) {
+				assert.NoError(t, store.RemoveFromRepoArray("repos", removed[i]))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every concurrent edit landed: each new repository is watched and each removed one is gone
+	watched, err := store.GetRepoArray("repos")
+	assert.NoError(t, err)
+	assert.Len(t, watched, 20)
+	names := make(map[string]bool)
+	for _, repo := range watched {
+		names[repo.RepoName] = true
+	}
+	for i := 0; i < 20; i++ {
+		assert.True(t, names[fmt.Sprintf("new-%d", i)], "new-%d missing", i)
+	}
+}